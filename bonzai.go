@@ -1,5 +1,7 @@
 package bonzai
 
+import "strings"
+
 // Configurer specifies how to configure a Bonzai Cmd. Configurations
 // must always be maintained in YAML (of which JSON is a subset) and be
 // fully compatible with gopkg.in/yaml.v3.
@@ -34,17 +36,86 @@ type Configurer interface {
 	QueryPrint(q string)      // prints result to os.Stdout
 }
 
+// ConfigurerWriter may optionally be implemented by any Configurer that
+// supports changing a single value at a time. The path is the full
+// dotted path without the leading dot (ex: db.migrate.url) and the
+// value is YAML (so that JSON arrays and objects are also accepted).
+// Set must replace anything already at the path with the same atomic
+// write guarantees as OverWrite. Edit must open the full configuration
+// in the local editor (usually $EDITOR).
+type ConfigurerWriter interface {
+	Set(path, value string) error
+	Edit() error
+}
+
+// Vars specifies a lightweight store for small bits of state that
+// commands need to persist between runs (last used environment, cached
+// tokens, counters, etc.). Unlike Configurer, Vars are changed by the
+// commands themselves, one key at a time. Keys are usually namespaced
+// by the command path (ex: db.migrate.last). Values are always strings.
+//
+// Get must return an empty string for keys that are not set. Set must
+// persist the value before returning. Del must not return an error if
+// the key does not exist. Data must return all the keys and values in
+// the native format of the implementation.
+type Vars interface {
+	Get(key string) string     // empty if not set
+	Set(key, val string) error // persist value of key
+	Del(key string) error      // remove key (if set)
+	Data() string              // all keys and values
+}
+
 // Completer defines a function to complete the given leaf Command with
 // the provided arguments, if any. Completer functions must never be
 // passed a nil Command or nil as the args slice. See comp.Standard.
 type Completer func(leaf Command, args ...string) []string
 
+// CompContext is everything known about the command line being
+// completed. It is passed to a ContextCompleter so that it can tell
+// which positional argument it is completing (the first is a host, the
+// second a path on it, for example) or see what came before it.
+type CompContext struct {
+	Line  []string  // every word of the line (ex: foo db migrate --to v)
+	Index int       // of the word being completed within Line
+	Chain []Command // from the root to the leaf sought from the Line
+	Args  []string  // those after the leaf (the last is being completed)
+}
+
+// Leaf returns the last Command of the Chain (or nil if empty).
+func (c CompContext) Leaf() Command {
+	if len(c.Chain) == 0 {
+		return nil
+	}
+	return c.Chain[len(c.Chain)-1]
+}
+
+// Word returns the word being completed (or an empty string if Index is
+// outside of the Line).
+func (c CompContext) Word() string {
+	if c.Index < 0 || c.Index >= len(c.Line) {
+		return ""
+	}
+	return c.Line[c.Index]
+}
+
+// ContextCompleter is a Completer receiving the full CompContext rather
+// than just the Args remaining after the leaf. See comp.Contextual for
+// using a plain Completer as a ContextCompleter and comp.Standard.
+type ContextCompleter func(ctx CompContext) []string
+
 // Section is a section from the Other attribute.
 type Section interface {
 	GetTitle() string
 	GetBody() string
 }
 
+// Example is a single example invocation of a command with an optional
+// note explaining it.
+type Example interface {
+	GetCmd() string
+	GetNote() string
+}
+
 // Command interface encapsulates the Z.Cmd implementation under the
 // bonzai/z package enabling the use of the interface type when an
 // interface is needed, for example, when implementing Completers to
@@ -56,6 +127,7 @@ type Command interface {
 	GetTitle() string
 	GetAliases() []string
 	GetSummary() string
+	GetGroup() string
 	GetUsage() string
 	GetVersion() string
 	GetCopyright() string
@@ -67,15 +139,28 @@ type Command interface {
 	GetCommands() []Command
 	GetCommandNames() []string
 	GetParams() []string
+	GetRepeatable() []string
 	GetHidden() []string
+	GetHide() bool
+	GetDeprecated() string
+	GetDepParams() map[string]string
+	GetExcl() [][]string
+	GetNeeds() map[string][]string
 	GetOther() []Section
 	GetOtherTitles() []string
+	GetOtherSection(title string) Section
+	GetExamples() []Example
 	GetCompleter() Completer
+	GetContextCompleter() ContextCompleter
 	GetCaller() Command
 	GetMinArgs() int
 	GetMinParm() int
 	GetMaxParm() int
 	GetReqConf() bool
+	GetReqVars() bool
+	GetTag(key string) string
+	GetIgnoreCase() bool
+	GetPrefixMatch() bool
 	GetUsageFunc() UsageFunc
 }
 
@@ -87,3 +172,30 @@ type Command interface {
 // implementations to cast the Command passed to a specific Cmd to give
 // that implementation access to rest of the Cmd symbol scope.
 type UsageFunc func(x Command) string
+
+// KVParam parses a key=value param declaration returning the key and
+// the allowed values (if any) or false if the param is not key=value. A
+// param ending with an equals sign (ex: "env=") allows any value while
+// values may be restricted by joining them with bars (ex:
+// "env=prod|staging|dev"). The value of a key=value argument is always
+// required.
+func KVParam(p string) (key string, values []string, ok bool) {
+	key, vals, ok := strings.Cut(p, "=")
+	if !ok || key == "" {
+		return "", nil, false
+	}
+	if vals != "" {
+		values = strings.Split(vals, "|")
+	}
+	return key, values, true
+}
+
+// ParamWord returns the word used to refer to a param in the
+// constraints of a Command (see GetExcl and GetNeeds), which is the
+// key of a key=value param (see KVParam) or the param itself.
+func ParamWord(p string) string {
+	if k, _, ok := KVParam(p); ok {
+		return k
+	}
+	return p
+}
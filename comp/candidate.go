@@ -0,0 +1,67 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package comp
+
+import "github.com/rwxrob/bonzai"
+
+// Candidate is a completion Value with an optional Description (usually
+// the Summary of a Command) that shells such as zsh and fish display
+// next to the value. Shells without such support (bash) only use the
+// Value.
+type Candidate struct {
+	Value       string
+	Description string
+}
+
+// Describer is implemented by anything that completes with Candidates
+// rather than just the string values of a bonzai.Completer. Describers
+// are always optional. Plain Completers can be described with Describe.
+type Describer interface {
+	Complete(x bonzai.Command, args ...string) []Candidate
+}
+
+// DescriberFunc adapts an ordinary function to the Describer interface.
+type DescriberFunc func(x bonzai.Command, args ...string) []Candidate
+
+// Complete fulfills the Describer interface.
+func (f DescriberFunc) Complete(x bonzai.Command, args ...string) []Candidate {
+	return f(x, args...)
+}
+
+// Describe returns the list as Candidates with the Description of each
+// set to the Summary of the Command (of x) that has the same name or
+// alias (if any).
+func Describe(x bonzai.Command, list []string) []Candidate {
+	summaries := map[string]string{}
+	if x != nil {
+		for _, c := range x.GetCommands() {
+			summaries[c.GetName()] = c.GetSummary()
+			for _, a := range c.GetAliases() {
+				summaries[a] = c.GetSummary()
+			}
+		}
+	}
+	cands := make([]Candidate, 0, len(list))
+	for _, i := range list {
+		cands = append(cands, Candidate{i, summaries[i]})
+	}
+	return cands
+}
+
+// Values returns just the Value of each Candidate.
+func Values(cands []Candidate) []string {
+	list := make([]string, 0, len(cands))
+	for _, c := range cands {
+		list = append(list, c.Value)
+	}
+	return list
+}
+
+// StandardDescriber completes the same as Standard but with the
+// Summary of every Command as its Description (see Describe).
+var StandardDescriber = DescriberFunc(
+	func(x bonzai.Command, args ...string) []Candidate {
+		return Describe(x, Standard(x, args...))
+	},
+)
@@ -0,0 +1,33 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package comp_test
+
+import (
+	"fmt"
+
+	"github.com/rwxrob/bonzai/comp"
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func ExampleDescribe() {
+	foo := new(Z.Cmd)
+	foo.Params = []string{"box"}
+	foo.Add("bar", "b").Summary = "bar the things"
+	foo.Add("blah")
+	fmt.Printf("%q\n", comp.Describe(foo, []string{"bar", "b", "blah", "box"}))
+	// Output:
+	// [{"bar" "bar the things"} {"b" "bar the things"} {"blah" ""} {"box" ""}]
+}
+
+func ExampleStandardDescriber() {
+	foo := new(Z.Cmd)
+	foo.Params = []string{"box"}
+	foo.Add("bar").Summary = "bar the things"
+	cands := comp.StandardDescriber.Complete(foo, "b")
+	fmt.Printf("%q\n", cands)
+	fmt.Println(comp.Values(cands))
+	// Output:
+	// [{"bar" "bar the things"} {"box" ""}]
+	// [bar box]
+}
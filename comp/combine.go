@@ -0,0 +1,40 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package comp
+
+import "github.com/rwxrob/bonzai"
+
+// Combine returns a Completer that calls each of the completers in
+// order, concatenates the results, removes duplicates (preserving the
+// order in which they first appear), and then filters them once with
+// the last argument (see Matching) ignoring case if the Command does.
+// Nil completers are skipped. Each completer is passed a Command that
+// reports no Completer of its own so that those (like Standard) that
+// would otherwise delegate back to the Command's Completer (this one)
+// do not recurse forever.
+func Combine(completers ...bonzai.Completer) bonzai.Completer {
+	return func(x bonzai.Command, args ...string) []string {
+		list := []string{}
+		seen := map[string]bool{}
+		for _, c := range completers {
+			if c == nil {
+				continue
+			}
+			for _, i := range c(nocomp{x}, args...) {
+				if seen[i] {
+					continue
+				}
+				seen[i] = true
+				list = append(list, i)
+			}
+		}
+		return prefixed(x, list, last(args))
+	}
+}
+
+type nocomp struct{ bonzai.Command }
+
+func (nocomp) GetCompleter() bonzai.Completer { return nil }
+
+func (nocomp) GetContextCompleter() bonzai.ContextCompleter { return nil }
@@ -0,0 +1,43 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package comp_test
+
+import (
+	"fmt"
+
+	"github.com/rwxrob/bonzai"
+	"github.com/rwxrob/bonzai/comp"
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func ExampleCombine() {
+	foo := new(Z.Cmd)
+	foo.Params = []string{"box"}
+	foo.Add("bar")
+	foo.Completer = comp.Combine(
+		comp.Standard,
+		nil,
+		comp.List("bar", "baz", "other"),
+	)
+	fmt.Println(comp.Standard(foo, ""))
+	fmt.Println(comp.Standard(foo, "b"))
+	fmt.Println(comp.Standard(foo, "o"))
+	// Output:
+	// [bar box baz other]
+	// [bar box baz]
+	// [other]
+}
+
+func ExampleCombine_matching() {
+	defer func(m comp.MatchMode) { comp.Matching = m }(comp.Matching)
+	comp.Matching = comp.Substring
+	foo := new(Z.Cmd)
+	all := func(bonzai.Command, ...string) []string {
+		return []string{"abba", "bar", "other"}
+	}
+	foo.Completer = comp.Combine(comp.List("bar", "baz"), all)
+	fmt.Println(comp.Standard(foo, "b"))
+	// Output:
+	// [bar baz abba]
+}
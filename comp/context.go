@@ -0,0 +1,36 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package comp
+
+import "github.com/rwxrob/bonzai"
+
+// Context returns the bonzai.CompContext for completing the args of the
+// leaf x when the full line is not known (as when called from
+// Standard). The Chain is made from the Callers of x (see GetCaller)
+// and the Line from their names followed by the args.
+func Context(x bonzai.Command, args ...string) bonzai.CompContext {
+	var chain []bonzai.Command
+	for c := x; c != nil; c = c.GetCaller() {
+		chain = append([]bonzai.Command{c}, chain...)
+	}
+	line := []string{}
+	for _, c := range chain {
+		line = append(line, c.GetName())
+	}
+	line = append(line, args...)
+	return bonzai.CompContext{
+		Line:  line,
+		Index: len(line) - 1,
+		Chain: chain,
+		Args:  args,
+	}
+}
+
+// Contextual returns a bonzai.ContextCompleter that calls the plain
+// Completer with the leaf and Args of the context.
+func Contextual(c bonzai.Completer) bonzai.ContextCompleter {
+	return func(ctx bonzai.CompContext) []string {
+		return c(ctx.Leaf(), ctx.Args...)
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package comp_test
+
+import (
+	"fmt"
+
+	"github.com/rwxrob/bonzai"
+	"github.com/rwxrob/bonzai/comp"
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func ExampleContext() {
+	foo := &Z.Cmd{Name: `foo`}
+	bar := &Z.Cmd{Name: `bar`, Caller: foo}
+	ctx := comp.Context(bar, "one", "t")
+	fmt.Printf("%q %v %v %q %v\n", ctx.Line, ctx.Index, len(ctx.Chain),
+		ctx.Word(), ctx.Leaf().GetName())
+	// Output:
+	// ["foo" "bar" "one" "t"] 3 2 "t" bar
+}
+
+func ExampleContextual() {
+	days := comp.Contextual(comp.List("mon", "tue", "wed", "thu"))
+	fmt.Println(days(comp.Context(&Z.Cmd{}, "t")))
+	// Output:
+	// [tue thu]
+}
+
+func ExampleStandard_contextCompleter() {
+	foo := &Z.Cmd{
+		Name: `foo`,
+		ContextCompleter: func(ctx bonzai.CompContext) []string {
+			return []string{fmt.Sprintf("arg%v", len(ctx.Args))}
+		},
+	}
+	fmt.Println(comp.Standard(foo, "a", ""))
+	fmt.Println(comp.Static(foo, "a", ""))
+	// Output:
+	// [arg2]
+	// []
+}
@@ -0,0 +1,42 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package comp
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// DebugEnv is the name of the environment variable containing the path
+// of the file to which Debugf appends. Since anything printed to
+// standard output during completion becomes a candidate this file is
+// the only safe place to trace completion (from Completers or
+// Describers, for example):
+//
+//     BONZAI_COMP_DEBUG=/tmp/comp.log foo <tab>
+//     tail -f /tmp/comp.log
+const DebugEnv = `BONZAI_COMP_DEBUG`
+
+// Debugf appends a line formatted with fmt.Sprintf to the file named by
+// the DebugEnv environment variable (creating it if needed). A trailing
+// line return is added unless already there. Debugf does nothing if
+// DebugEnv is unset and silently ignores any errors writing the file
+// since there is nowhere to report them during completion.
+func Debugf(format string, args ...any) {
+	path := os.Getenv(DebugEnv)
+	if path == "" {
+		return
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	msg := fmt.Sprintf(format, args...)
+	if !strings.HasSuffix(msg, "\n") {
+		msg += "\n"
+	}
+	f.WriteString(msg)
+}
@@ -0,0 +1,32 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package comp_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rwxrob/bonzai/comp"
+)
+
+func ExampleDebugf() {
+	dir, _ := os.MkdirTemp("", "comp")
+	defer os.RemoveAll(dir)
+	trace := filepath.Join(dir, "comp.log")
+
+	comp.Debugf("ignored since %v is unset", comp.DebugEnv)
+
+	defer os.Unsetenv(comp.DebugEnv)
+	os.Setenv(comp.DebugEnv, trace)
+	comp.Debugf("first: %q", []string{"a", "b"})
+	comp.Debugf("second\n")
+
+	buf, _ := os.ReadFile(trace)
+	fmt.Print(string(buf))
+
+	// Output:
+	// first: ["a" "b"]
+	// second
+}
@@ -0,0 +1,58 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package comp
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/rwxrob/bonzai"
+)
+
+// EnvVars completes the names of all the environment variables (see
+// os.Environ) in sorted order using the last argument as the prefix.
+func EnvVars(x bonzai.Command, args ...string) []string {
+	return hasPrefix(envNames(""), last(args))
+}
+
+// EnvVarsWithPrefix returns a Completer that is the same as EnvVars but
+// only includes the names beginning with the prefix (usually one
+// specific to the application, ex: FOO_).
+func EnvVarsWithPrefix(prefix string) bonzai.Completer {
+	return func(x bonzai.Command, args ...string) []string {
+		return hasPrefix(envNames(prefix), last(args))
+	}
+}
+
+// EnvVarsAssign is the same as EnvVars but completes each name followed
+// by an equals sign (ex: PATH=) for commands taking VAR=value
+// arguments. Nothing is completed once the last argument contains an
+// equals sign since the value is left to the user.
+func EnvVarsAssign(x bonzai.Command, args ...string) []string {
+	word := last(args)
+	if strings.Contains(word, "=") {
+		return []string{}
+	}
+	list := []string{}
+	for _, name := range hasPrefix(envNames(""), word) {
+		list = append(list, name+"=")
+	}
+	return list
+}
+
+// envNames returns the sorted names of the environment variables that
+// begin with the prefix. Entries without a name (such as the =C: of
+// Windows) are skipped.
+func envNames(prefix string) []string {
+	names := []string{}
+	for _, e := range os.Environ() {
+		name, _, _ := strings.Cut(e, "=")
+		if name != "" && strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,45 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package comp_test
+
+import (
+	"testing"
+
+	"github.com/rwxrob/bonzai/comp"
+)
+
+func TestEnvVars(t *testing.T) {
+	t.Setenv("BONZAI_TEST_ZED", "z")
+	t.Setenv("BONZAI_TEST_APPLE", "a")
+	t.Setenv("BONZAI_TEST_MANGO", "with=equals")
+	check(t, comp.EnvVars(nil, "BONZAI_TEST_"),
+		"BONZAI_TEST_APPLE", "BONZAI_TEST_MANGO", "BONZAI_TEST_ZED")
+	check(t, comp.EnvVars(nil, "BONZAI_TEST_M"), "BONZAI_TEST_MANGO")
+	check(t, comp.EnvVars(nil, "BONZAI_TEST_NOPE"))
+	if got := comp.EnvVars(nil); len(got) < 3 {
+		t.Errorf("expected every variable without args, got %q", got)
+	}
+}
+
+func TestEnvVarsWithPrefix(t *testing.T) {
+	t.Setenv("BONZAI_TEST_ONE", "1")
+	t.Setenv("BONZAI_TEST_TWO", "2")
+	t.Setenv("BONZAI_TESTING", "no")
+	vars := comp.EnvVarsWithPrefix("BONZAI_TEST_")
+	check(t, vars(nil), "BONZAI_TEST_ONE", "BONZAI_TEST_TWO")
+	check(t, vars(nil, ""), "BONZAI_TEST_ONE", "BONZAI_TEST_TWO")
+	check(t, vars(nil, "BONZAI_TEST_T"), "BONZAI_TEST_TWO")
+	check(t, vars(nil, "BONZAI_TESTI"))
+	check(t, vars(nil, "PATH"))
+}
+
+func TestEnvVarsAssign(t *testing.T) {
+	t.Setenv("BONZAI_TEST_ONE", "1")
+	t.Setenv("BONZAI_TEST_TWO", "2")
+	check(t, comp.EnvVarsAssign(nil, "BONZAI_TEST_"),
+		"BONZAI_TEST_ONE=", "BONZAI_TEST_TWO=")
+	check(t, comp.EnvVarsAssign(nil, "BONZAI_TEST_O"), "BONZAI_TEST_ONE=")
+	check(t, comp.EnvVarsAssign(nil, "BONZAI_TEST_ONE="))
+	check(t, comp.EnvVarsAssign(nil, "BONZAI_TEST_ONE=x"))
+}
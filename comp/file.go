@@ -4,13 +4,11 @@
 package comp
 
 import (
+	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/rwxrob/bonzai"
-	"github.com/rwxrob/fn/filt"
-	"github.com/rwxrob/fs"
-	"github.com/rwxrob/fs/dir"
 )
 
 // File returns all file names for the directory and file prefix passed.
@@ -27,7 +25,7 @@ func File(x bonzai.Command, args ...string) []string {
 	}
 
 	if args == nil || (len(args) > 0 && args[0] == "") {
-		return dir.EntriesWithSlash(".")
+		return entriesWithSlash(".")
 	}
 
 	// catch edge cases
@@ -35,31 +33,78 @@ func File(x bonzai.Command, args ...string) []string {
 		if x != nil {
 			return []string{x.GetName()} // will add tailing space
 		}
-		return dir.EntriesWithSlash("")
+		return entriesWithSlash("")
 	}
 
 	first := strings.TrimRight(args[0], string(filepath.Separator))
 	d, pre := filepath.Split(first)
 
 	if d == "" {
-		list := filt.HasPrefix(dir.Entries("."), pre)
-		if len(list) == 1 && fs.IsDir(list[0]) {
-			return dir.EntriesWithSlash(list[0])
+		list := hasPrefix(entries("."), pre)
+		if len(list) == 1 && isDir(list[0]) {
+			return entriesWithSlash(list[0])
 		}
-		return dir.AddSlash(list)
+		return addSlash(list)
 	}
 
 	for {
-		list := filt.BaseHasPrefix(dir.Entries(d), pre)
+		list := baseHasPrefix(entries(d), pre)
 		if len(list) > 1 {
-			return dir.AddSlash(list)
+			return addSlash(list)
 		}
-		if fs.IsDir(list[0]) {
+		if isDir(list[0]) {
 			d = list[0]
 			continue
 		}
-		return dir.AddSlash(list)
+		return addSlash(list)
 	}
 
 	return []string{}
 }
+
+// entries returns the path joined with the name of every entry in the
+// directory (or nil if it cannot be read).
+func entries(path string) []string {
+	var list []string
+	dirents, err := os.ReadDir(path)
+	if err != nil {
+		return list
+	}
+	for _, f := range dirents {
+		list = append(list, filepath.Join(path, f.Name()))
+	}
+	return list
+}
+
+// entriesWithSlash returns the entries of the directory with
+// a separator added to those that are directories (see addSlash).
+func entriesWithSlash(path string) []string { return addSlash(entries(path)) }
+
+// addSlash adds a filepath.Separator to every path that is a directory.
+func addSlash(paths []string) []string {
+	var list []string
+	for _, p := range paths {
+		if isDir(p) {
+			p += string(filepath.Separator)
+		}
+		list = append(list, p)
+	}
+	return list
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// baseHasPrefix returns the paths with a base name beginning with the
+// prefix (or nil if none).
+func baseHasPrefix(paths []string, pre string) []string {
+	var out []string
+	for _, p := range paths {
+		if strings.HasPrefix(filepath.Base(p), pre) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
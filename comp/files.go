@@ -0,0 +1,97 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package comp
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rwxrob/bonzai"
+)
+
+// Files completes file system paths (files and directories) relative to
+// the current working directory using the last argument as the prefix.
+// Directories have a trailing separator added. When the only match is
+// a directory its contents are returned instead so that completion
+// continues to descend as the user types. Hidden (dot) files are only
+// included when the base of the prefix begins with a dot. Separators
+// are those of the host operating system (see path/filepath) so both
+// forward and back slashes work on Windows.
+func Files(x bonzai.Command, args ...string) []string {
+	return paths(last(args), func(string, bool) bool { return true })
+}
+
+// Dirs is the same as Files but only includes directories.
+func Dirs(x bonzai.Command, args ...string) []string {
+	return paths(last(args), func(_ string, isdir bool) bool { return isdir })
+}
+
+// FilesWith returns a Completer that is the same as Files but only
+// includes the files ending with one of the given extensions (ex:
+// ".go"). Directories are always included.
+func FilesWith(ext ...string) bonzai.Completer {
+	return func(x bonzai.Command, args ...string) []string {
+		return paths(last(args), func(name string, isdir bool) bool {
+			if isdir {
+				return true
+			}
+			for _, e := range ext {
+				if strings.HasSuffix(name, e) {
+					return true
+				}
+			}
+			return false
+		})
+	}
+}
+
+func last(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	return args[len(args)-1]
+}
+
+func paths(word string, keep func(name string, isdir bool) bool) []string {
+	list := []string{}
+	sep := string(filepath.Separator)
+	d, pre := filepath.Split(word)
+	read := d
+	if read == "" {
+		read = "."
+	}
+	entries, err := os.ReadDir(read)
+	if err != nil {
+		return list
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasPrefix(name, pre) {
+			continue
+		}
+		if strings.HasPrefix(name, ".") && !strings.HasPrefix(pre, ".") {
+			continue
+		}
+		isdir := e.IsDir()
+		if e.Type()&os.ModeSymlink != 0 {
+			if i, err := os.Stat(filepath.Join(read, name)); err == nil {
+				isdir = i.IsDir()
+			}
+		}
+		if !keep(name, isdir) {
+			continue
+		}
+		if isdir {
+			name += sep
+		}
+		list = append(list, d+name)
+	}
+	if len(list) == 1 && strings.HasSuffix(list[0], sep) {
+		if sub := paths(list[0], keep); len(sub) > 0 {
+			return sub
+		}
+	}
+	return list
+}
@@ -0,0 +1,87 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package comp_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rwxrob/bonzai/comp"
+)
+
+// tree creates the following in a temporary directory and changes into
+// it for the duration of the test:
+//
+//     .hidden
+//     foo.go
+//     foo.txt
+//     sub/one.go
+//     sub/deep/two.go
+//     only/inner/three.go
+func tree(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	for _, f := range []string{
+		".hidden", "foo.go", "foo.txt", "sub/one.go", "sub/deep/two.go",
+		"only/inner/three.go",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	orig, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(orig) })
+}
+
+func check(t *testing.T, got []string, want ...string) {
+	t.Helper()
+	for i, w := range want {
+		want[i] = filepath.FromSlash(w)
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestFiles(t *testing.T) {
+	tree(t)
+	check(t, comp.Files(nil), "foo.go", "foo.txt", "only/", "sub/")
+	check(t, comp.Files(nil, ""), "foo.go", "foo.txt", "only/", "sub/")
+	check(t, comp.Files(nil, "f"), "foo.go", "foo.txt")
+	check(t, comp.Files(nil, "."), ".hidden")
+	check(t, comp.Files(nil, "s"), "sub/deep/", "sub/one.go")
+	check(t, comp.Files(nil, "sub/"), "sub/deep/", "sub/one.go")
+	check(t, comp.Files(nil, "sub/d"), "sub/deep/two.go")
+	check(t, comp.Files(nil, "o"), "only/inner/three.go")
+	check(t, comp.Files(nil, "nope"))
+	check(t, comp.Files(nil, "nope/"))
+}
+
+func TestDirs(t *testing.T) {
+	tree(t)
+	check(t, comp.Dirs(nil), "only/", "sub/")
+	check(t, comp.Dirs(nil, "s"), "sub/deep/")
+	check(t, comp.Dirs(nil, "sub/"), "sub/deep/")
+	check(t, comp.Dirs(nil, "f"))
+}
+
+func TestFilesWith(t *testing.T) {
+	tree(t)
+	gofiles := comp.FilesWith(".go")
+	check(t, gofiles(nil), "foo.go", "only/", "sub/")
+	check(t, gofiles(nil, "f"), "foo.go")
+	check(t, gofiles(nil, "sub/"), "sub/deep/", "sub/one.go")
+}
@@ -0,0 +1,129 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package comp
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rwxrob/bonzai"
+)
+
+// SSHConfigFiles, KnownHostsFiles, and HostsFiles are the files read
+// by Hosts. They default to those of the current user (in ~/.ssh) and
+// the system (/etc) and may be changed (in tests, for example).
+var (
+	SSHConfigFiles  = homeFiles(".ssh/config")
+	KnownHostsFiles = homeFiles(".ssh/known_hosts")
+	HostsFiles      = []string{"/etc/hosts"}
+)
+
+func homeFiles(name string) []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return []string{}
+	}
+	return []string{filepath.Join(home, filepath.FromSlash(name))}
+}
+
+// Hosts completes host names (sorted and de-duplicated) using the last
+// argument as the prefix from the following (see SSHConfigFiles,
+// KnownHostsFiles, and HostsFiles):
+//
+//     * Host patterns from ssh config files (skipping wildcards)
+//     * host names from known_hosts files (skipping hashed ones)
+//     * host names and aliases from hosts files
+//
+// Any file that cannot be read is silently skipped.
+func Hosts(x bonzai.Command, args ...string) []string {
+	seen := map[string]bool{}
+	for _, f := range SSHConfigFiles {
+		eachLine(f, func(line string) {
+			key, val := sshConfigLine(line)
+			if !strings.EqualFold(key, "host") {
+				return
+			}
+			for _, h := range strings.Fields(val) {
+				if !isPattern(h) {
+					seen[h] = true
+				}
+			}
+		})
+	}
+	for _, f := range KnownHostsFiles {
+		eachLine(f, func(line string) {
+			fields := strings.Fields(line)
+			if len(fields) > 0 && strings.HasPrefix(fields[0], "@") {
+				fields = fields[1:] // @cert-authority or @revoked
+			}
+			if len(fields) == 0 || strings.HasPrefix(fields[0], "|") {
+				return
+			}
+			for _, h := range strings.Split(fields[0], ",") {
+				if strings.HasPrefix(h, "[") {
+					if i := strings.Index(h, "]"); i > 0 {
+						h = h[1:i]
+					}
+				}
+				if h != "" && !isPattern(h) {
+					seen[h] = true
+				}
+			}
+		})
+	}
+	for _, f := range HostsFiles {
+		eachLine(f, func(line string) {
+			fields := strings.Fields(line)
+			if len(fields) < 2 {
+				return
+			}
+			for _, h := range fields[1:] {
+				seen[h] = true
+			}
+		})
+	}
+	names := make([]string, 0, len(seen))
+	for h := range seen {
+		names = append(names, h)
+	}
+	sort.Strings(names)
+	return hasPrefix(names, last(args))
+}
+
+// eachLine calls do with every line of the file with any comment (from
+// #) and surrounding white space removed skipping those left empty.
+// Nothing is done if the file cannot be read.
+func eachLine(path string, do func(line string)) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line, _, _ := strings.Cut(s.Text(), "#")
+		if line = strings.TrimSpace(line); line != "" {
+			do(line)
+		}
+	}
+}
+
+// sshConfigLine returns the keyword and arguments of a line from an ssh
+// config file, which may be separated by white space or an equals sign.
+func sshConfigLine(line string) (key, val string) {
+	i := strings.IndexAny(line, " \t=")
+	if i < 0 {
+		return line, ""
+	}
+	val = strings.TrimSpace(line[i:])
+	return line[:i], strings.TrimSpace(strings.TrimPrefix(val, "="))
+}
+
+// isPattern returns true if the host is a wildcard or negated pattern.
+func isPattern(host string) bool {
+	return strings.ContainsAny(host, "*?") || strings.HasPrefix(host, "!")
+}
@@ -0,0 +1,70 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package comp_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rwxrob/bonzai/comp"
+)
+
+func hostFiles(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	config := write("config", `# personal
+Host web1 web2
+  HostName 10.0.0.1
+Host *.internal !bastion
+host=db
+  User admin
+Match host build
+Host   ci    # trailing comment
+`)
+	known := write("known_hosts", `web1,10.0.0.1 ssh-ed25519 AAAA
+|1|F1E1KeoE/eEWhi10WpGv4OdiO6Y=|3988QV0VE8wmZL7suNrYQLITLCg= ssh-rsa AAAA
+[git.example.com]:2222 ssh-ed25519 AAAA
+@cert-authority *.example.com ssh-rsa AAAA
+@revoked old.example.com ssh-rsa AAAA
+`)
+	hosts := write("hosts", `127.0.0.1	localhost
+::1	localhost ip6-localhost # loopback
+10.0.0.2 db db.lan
+`)
+	s, k, h := comp.SSHConfigFiles, comp.KnownHostsFiles, comp.HostsFiles
+	t.Cleanup(func() {
+		comp.SSHConfigFiles, comp.KnownHostsFiles, comp.HostsFiles = s, k, h
+	})
+	missing := filepath.Join(dir, "missing")
+	comp.SSHConfigFiles = []string{config, missing}
+	comp.KnownHostsFiles = []string{known, dir} // directory unreadable as file
+	comp.HostsFiles = []string{hosts}
+}
+
+func TestHosts(t *testing.T) {
+	hostFiles(t)
+	check(t, comp.Hosts(nil),
+		"10.0.0.1", "ci", "db", "db.lan", "git.example.com",
+		"ip6-localhost", "localhost", "old.example.com", "web1", "web2")
+	check(t, comp.Hosts(nil, "web"), "web1", "web2")
+	check(t, comp.Hosts(nil, "d"), "db", "db.lan")
+	check(t, comp.Hosts(nil, "bastion"))
+	check(t, comp.Hosts(nil, "*"))
+}
+
+func TestHosts_unreadable(t *testing.T) {
+	hostFiles(t)
+	comp.SSHConfigFiles = []string{"/nonexistent/config"}
+	comp.KnownHostsFiles = nil
+	comp.HostsFiles = []string{t.TempDir()}
+	check(t, comp.Hosts(nil))
+}
@@ -0,0 +1,30 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package comp
+
+import (
+	"strings"
+
+	"github.com/rwxrob/bonzai"
+)
+
+// List returns a Completer for a static list of words filtered with the
+// last argument as the prefix.
+func List(items ...string) bonzai.Completer {
+	return func(_ bonzai.Command, args ...string) []string {
+		return hasPrefix(items, last(args))
+	}
+}
+
+// hasPrefix returns the items of the list that begin with the prefix
+// (or nil if none).
+func hasPrefix(list []string, pre string) []string {
+	var out []string
+	for _, i := range list {
+		if strings.HasPrefix(i, pre) {
+			out = append(out, i)
+		}
+	}
+	return out
+}
@@ -0,0 +1,19 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package comp_test
+
+import (
+	"fmt"
+
+	"github.com/rwxrob/bonzai/comp"
+)
+
+func ExampleList() {
+	days := comp.List("mon", "tue", "wed", "thu", "fri", "sat", "sun")
+	fmt.Println(days(nil))
+	fmt.Println(days(nil, "t"))
+	// Output:
+	// [mon tue wed thu fri sat sun]
+	// [tue thu]
+}
@@ -0,0 +1,114 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package comp
+
+import (
+	"sort"
+	"strings"
+)
+
+// MatchMode is the strategy used to match the word being completed
+// against the possible completions (see Filter).
+type MatchMode int
+
+const (
+	Prefix    MatchMode = iota // begins with the word (default)
+	Substring                  // contains the word anywhere
+	Fuzzy                      // contains the runes of the word in order
+)
+
+// Matching is the MatchMode used by the final filter of Standard (and
+// Static). It is usually set from Z.CompMatch.
+var Matching = Prefix
+
+// Filter returns the items of the list that match the word using the
+// mode. Prefix and Substring matches keep the order of the list. Fuzzy
+// matches are ranked so that the better ones come first: those
+// beginning with the word, then those containing it (earliest first),
+// then those containing its runes in order (those spanning the fewest
+// runes first). Ties keep the order of the list so the ranking is
+// always the same for the same list.
+func Filter(list []string, word string, mode MatchMode) []string {
+	return filter(list, word, mode, false)
+}
+
+// filter is the same as Filter but ignores case if fold is true.
+func filter(list []string, word string, mode MatchMode, fold bool) []string {
+	out := []string{}
+	if fold {
+		word = strings.ToLower(word)
+	}
+	type ranked struct {
+		item             string
+		class, pos, span int
+	}
+	var fuzzy []ranked
+	for _, i := range list {
+		s := i
+		if fold {
+			s = strings.ToLower(i)
+		}
+		switch mode {
+		case Substring:
+			if strings.Contains(s, word) {
+				out = append(out, i)
+			}
+		case Fuzzy:
+			switch n := strings.Index(s, word); {
+			case n == 0:
+				fuzzy = append(fuzzy, ranked{i, 0, 0, 0})
+			case n > 0:
+				fuzzy = append(fuzzy, ranked{i, 1, n, 0})
+			default:
+				if pos, span, ok := subsequence(s, word); ok {
+					fuzzy = append(fuzzy, ranked{i, 2, pos, span})
+				}
+			}
+		default:
+			if strings.HasPrefix(s, word) {
+				out = append(out, i)
+			}
+		}
+	}
+	if mode != Fuzzy {
+		return out
+	}
+	sort.SliceStable(fuzzy, func(a, b int) bool {
+		x, y := fuzzy[a], fuzzy[b]
+		switch {
+		case x.class != y.class:
+			return x.class < y.class
+		case x.span != y.span:
+			return x.span < y.span
+		default:
+			return x.pos < y.pos
+		}
+	})
+	for _, r := range fuzzy {
+		out = append(out, r.item)
+	}
+	return out
+}
+
+// subsequence returns the byte position of the first rune of the word
+// found in s and the number of bytes spanned from there to the last if
+// all of its runes are found in order (taking the first of each).
+func subsequence(s, word string) (pos, span int, ok bool) {
+	pos = -1
+	i := 0
+	for _, r := range word {
+		n := strings.IndexRune(s[i:], r)
+		if n < 0 {
+			return 0, 0, false
+		}
+		if pos < 0 {
+			pos = i + n
+		}
+		i += n + len(string(r))
+	}
+	if pos < 0 {
+		pos = 0
+	}
+	return pos, i - pos, true
+}
@@ -0,0 +1,63 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package comp_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/rwxrob/bonzai/comp"
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func ExampleFilter() {
+	list := []string{"migrate", "db-migrate", "merge", "image", "mig"}
+	fmt.Println(comp.Filter(list, "mig", comp.Prefix))
+	fmt.Println(comp.Filter(list, "mig", comp.Substring))
+	fmt.Println(comp.Filter(list, "mig", comp.Fuzzy))
+	fmt.Println(comp.Filter(list, "mge", comp.Fuzzy))
+	fmt.Println(comp.Filter(list, "", comp.Fuzzy))
+	// Output:
+	// [migrate mig]
+	// [migrate db-migrate mig]
+	// [migrate mig db-migrate]
+	// [image merge migrate db-migrate]
+	// [migrate db-migrate merge image mig]
+}
+
+func TestFilter_fuzzy_ranking(t *testing.T) {
+	list := []string{
+		"xstatus", // substring at 1
+		"stash",   // no match
+		"s-t-a-t", // subsequence spanning 7
+		"start",   // subsequence spanning 5
+		"status",  // prefix
+		"xxstat",  // substring at 2
+		"stat",    // prefix
+	}
+	want := "[status stat xstatus xxstat start s-t-a-t]"
+	for i := 0; i < 10; i++ {
+		if got := fmt.Sprint(comp.Filter(list, "stat", comp.Fuzzy)); got != want {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func ExampleMatching() {
+	defer func() { comp.Matching = comp.Prefix }()
+	foo := &Z.Cmd{
+		Name:       `foo`,
+		IgnoreCase: true,
+		Commands: []*Z.Cmd{
+			{Name: `schema`}, {Name: `migrate`}, {Name: `Remigrate`},
+		},
+	}
+	comp.Matching = comp.Substring
+	fmt.Println(comp.Standard(foo, "MIG"))
+	comp.Matching = comp.Fuzzy
+	fmt.Println(comp.Standard(foo, "mt"))
+	// Output:
+	// [migrate Remigrate]
+	// [migrate Remigrate]
+}
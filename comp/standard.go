@@ -4,29 +4,72 @@
 package comp
 
 import (
+	"strings"
+
 	"github.com/rwxrob/bonzai"
-	"github.com/rwxrob/fn/filt"
-	"github.com/rwxrob/structs/set/text/set"
 )
 
 // Standard completion is resolved as follows:
 //
-//     1. If leaf has Completer function, delegate to it
+//     1. If leaf has a ContextCompleter (see Context) or Completer
+//        function, delegate to it
+//
+//     2. If leaf has no arguments, return its own name (since the
+//        command name itself might not be complete yet)
+//
+//     3. If any args before the last name a Command (or alias), as they
+//        would for Seek, delegate to Standard for that Command with the
+//        args that follow it
 //
-//     2. If leaf has no arguments, return all Commands and Params
+//     4. Otherwise, return every Command (only if there are no args
+//        before the last) or Param that is not in the Hidden list (or
+//        a Command that Hides itself) and matches the last arg (see
+//        Matching, ignoring case if GetIgnoreCase is true)
 //
-//     3. If first argument is the name of a Command return it only even
-//        if in the Hidden list
+// Hidden Commands and Params are only returned when the last arg is
+// exactly one of them (so that the shell adds the trailing space) and
+// the Commands of a hidden Command complete normally once it has been
+// typed (see rule 3).
 //
-//     4. Otherwise, return every Command or Param that is not in the
-//        Hidden list and HasPrefix matching the first arg
+// The last arg is always the word being completed (empty after
+// a trailing space, see Z.ArgsFrom) and those before it are the
+// context already consumed.
+//
+// Params that already appear in the args before the last are not
+// returned again unless they are also in the Repeatable list. Neither
+// are the others of any group of GetExcl (mutually exclusive Params) of
+// which one has been used. Once MaxParm (if greater than 0) distinct
+// Params have been used no more Params are returned at all. Deprecated
+// Commands and Params (see GetDeprecated and GetDepParams) are still
+// returned but always last.
+//
+// Key=value Params (see bonzai.KVParam) complete as the key followed
+// by an equals sign (ex: env=) until the last arg begins with it at
+// which point the allowed values are completed (ex: env=prod). They
+// are used once their key appears in any args before the last.
 //
 // See bonzai.Completer.
 func Standard(x bonzai.Command, args ...string) []string {
+	return standard(x, true, args)
+}
+
+// Static completes exactly as Standard would if neither x nor any
+// Command it delegates to had a Completer. It is meant as a fallback
+// for Completers that cannot be used (see Z.CompTimeout).
+func Static(x bonzai.Command, args ...string) []string {
+	return standard(x, false, args)
+}
+
+func standard(x bonzai.Command, delegate bool, args []string) []string {
 
 	// if has completer, delegate
-	if c := x.GetCompleter(); c != nil {
-		return c(x, args...)
+	if delegate {
+		if c := x.GetContextCompleter(); c != nil {
+			return c(Context(x, args...))
+		}
+		if c := x.GetCompleter(); c != nil {
+			return c(x, args...)
+		}
 	}
 
 	// not sure we've completed the command name itself yet
@@ -34,15 +77,182 @@ func Standard(x bonzai.Command, args ...string) []string {
 		return []string{x.GetName()}
 	}
 
-	// build list of visible commands and params
+	// delegate to the Command named by the first of the consumed args
+	if len(args) > 1 {
+		if c := named(x, args[0]); c != nil {
+			return standard(c, delegate, args[1:])
+		}
+	}
+
+	// build list of visible commands and unused params
 	list := []string{}
-	list = append(list, x.GetCommandNames()...)
-	list = append(list, x.GetParams()...)
-	list = set.Minus[string, string](list, x.GetHidden())
+	if len(args) == 1 {
+		list = append(list, x.GetCommandNames()...)
+	}
+	list = append(list, kv(unused(x, args[:len(args)-1]), args[len(args)-1])...)
+	list = minus(list, hidden(x))
 
-	if len(args) == 0 {
+	// hidden only when typed in full
+	word := args[len(args)-1]
+	names := x.GetParams()
+	if len(args) == 1 {
+		names = append(x.GetCommandNames(), names...)
+	}
+	for _, h := range hidden(x) {
+		if h == word || (x.GetIgnoreCase() && strings.EqualFold(h, word)) {
+			if len(minus([]string{h}, names)) == 0 &&
+				len(minus([]string{h}, list)) > 0 {
+				list = append(list, h)
+			}
+		}
+	}
+
+	return deprecatedLast(x, prefixed(x, list, args[len(args)-1]))
+}
+
+// named returns the Command of x with the name (or alias) or nil (see
+// GetIgnoreCase).
+func named(x bonzai.Command, name string) bonzai.Command {
+	fold := x.GetIgnoreCase()
+	for _, c := range x.GetCommands() {
+		for _, n := range append([]string{c.GetName()}, c.GetAliases()...) {
+			if n == name || (fold && strings.EqualFold(n, name)) {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+// deprecatedLast moves any deprecated Commands (or their aliases) and
+// Params to the end of the list preserving order otherwise.
+func deprecatedLast(x bonzai.Command, list []string) []string {
+	dep := map[string]bool{}
+	for p := range x.GetDepParams() {
+		dep[p] = true
+	}
+	for _, c := range x.GetCommands() {
+		if c.GetDeprecated() == "" {
+			continue
+		}
+		dep[c.GetName()] = true
+		for _, a := range c.GetAliases() {
+			dep[a] = true
+		}
+	}
+	if len(dep) == 0 {
 		return list
 	}
+	out := []string{}
+	var last []string
+	for _, i := range list {
+		if dep[i] {
+			last = append(last, i)
+			continue
+		}
+		out = append(out, i)
+	}
+	return append(out, last...)
+}
+
+// minus returns the items of the list that are not in the other list.
+func minus(list, other []string) []string {
+	out := []string{}
+	for _, i := range list {
+		var found bool
+		for _, o := range other {
+			if i == o {
+				found = true
+				break
+			}
+		}
+		if !found {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// hidden returns the Hidden list of x and the names of any of its
+// Commands that Hide themselves.
+func hidden(x bonzai.Command) []string {
+	list := append([]string{}, x.GetHidden()...)
+	for _, c := range x.GetCommands() {
+		if c.GetHide() {
+			list = append(list, c.GetName())
+		}
+	}
+	return list
+}
+
+// prefixed returns the items of the list that match the prefix (see
+// Matching and Filter) ignoring case if x.GetIgnoreCase() is true.
+func prefixed(x bonzai.Command, list []string, pre string) []string {
+	return filter(list, pre, Matching, x.GetIgnoreCase())
+}
 
-	return filt.HasPrefix(list, args[0])
+// kv replaces any key=value params in the list with the key and an
+// equals sign unless the last arg already begins with that in which
+// case the key with each of the allowed values is used instead.
+func kv(params []string, last string) []string {
+	list := []string{}
+	for _, p := range params {
+		key, vals, ok := bonzai.KVParam(p)
+		if !ok {
+			list = append(list, p)
+			continue
+		}
+		if !strings.HasPrefix(last, key+"=") || len(vals) == 0 {
+			list = append(list, key+"=")
+			continue
+		}
+		for _, v := range vals {
+			list = append(list, key+"="+v)
+		}
+	}
+	return list
+}
+
+// unused returns the Params of x that have not been used (unless
+// Repeatable) and are not excluded by one that has (see GetExcl) or
+// none at all if MaxParm distinct Params are used.
+func unused(x bonzai.Command, used []string) []string {
+	seen := map[string]bool{}
+	words := map[string]bool{}
+	for _, p := range x.GetParams() {
+		key, _, iskv := bonzai.KVParam(p)
+		for _, u := range used {
+			if u == p || (iskv && strings.HasPrefix(u, key+"=")) {
+				seen[p] = true
+				words[bonzai.ParamWord(p)] = true
+			}
+		}
+	}
+	if max := x.GetMaxParm(); max > 0 && len(seen) >= max {
+		return []string{}
+	}
+	repeat := map[string]bool{}
+	for _, r := range x.GetRepeatable() {
+		repeat[r] = true
+	}
+	excluded := map[string]bool{}
+	for _, group := range x.GetExcl() {
+		for _, w := range group {
+			if !words[w] {
+				continue
+			}
+			for _, o := range group {
+				if o != w {
+					excluded[o] = true
+				}
+			}
+		}
+	}
+	list := []string{}
+	for _, p := range x.GetParams() {
+		if (!seen[p] || repeat[p]) && !excluded[bonzai.ParamWord(p)] {
+			list = append(list, p)
+		}
+	}
+	return list
 }
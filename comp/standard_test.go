@@ -9,7 +9,6 @@ import (
 	"github.com/rwxrob/bonzai"
 	"github.com/rwxrob/bonzai/comp"
 	Z "github.com/rwxrob/bonzai/z"
-	"github.com/rwxrob/fn/filt"
 )
 
 func ExampleStandard() {
@@ -40,7 +39,7 @@ func ExampleStandard() {
 		if len(args) == 0 {
 			return list
 		}
-		return filt.HasPrefix(list, args[0])
+		return comp.List(list...)(cmd, args[0])
 	}
 	fmt.Println(comp.Standard(foo, `t`))
 
@@ -53,3 +52,143 @@ func ExampleStandard() {
 	// [tue thu]
 
 }
+
+func ExampleStandard_usedParams() {
+	foo := new(Z.Cmd)
+	foo.Params = []string{"json", "yaml", "text", "verbose"}
+	foo.Repeatable = []string{"verbose"}
+
+	fmt.Println(comp.Standard(foo, "json", ""))
+	fmt.Println(comp.Standard(foo, "json", "verbose", "v"))
+
+	foo.MaxParm = 2
+	fmt.Println(comp.Standard(foo, "json", ""))
+	fmt.Println(comp.Standard(foo, "json", "yaml", ""))
+
+	// Output:
+	// [yaml text verbose]
+	// [verbose]
+	// [yaml text verbose]
+	// []
+}
+
+func ExampleStandard_ignoreCase() {
+	foo := new(Z.Cmd)
+	foo.Add("Status")
+	foo.Add("start")
+	fmt.Println(comp.Standard(foo, "st"))
+	foo.IgnoreCase = true
+	fmt.Println(comp.Standard(foo, "st"))
+	// Output:
+	// [start]
+	// [Status start]
+}
+
+func ExampleStandard_deprecated() {
+	foo := new(Z.Cmd)
+	foo.Commands = []*Z.Cmd{
+		&Z.Cmd{Name: "push", Deprecated: "use sync instead"},
+		&Z.Cmd{Name: "sync"},
+	}
+	foo.Params = []string{"old", "new"}
+	foo.DepParams = map[string]string{"old": "use new instead"}
+	fmt.Println(comp.Standard(foo, ""))
+	// Output:
+	// [sync new push old]
+}
+
+func ExampleStandard_kv() {
+	foo := new(Z.Cmd)
+	foo.Params = []string{"env=prod|staging|dev", "limit=", "verbose"}
+	fmt.Println(comp.Standard(foo, ""))
+	fmt.Println(comp.Standard(foo, "env="))
+	fmt.Println(comp.Standard(foo, "env=s"))
+	fmt.Println(comp.Standard(foo, "env=dev", ""))
+	// Output:
+	// [env= limit= verbose]
+	// [env=prod env=staging env=dev]
+	// [env=staging]
+	// [limit= verbose]
+}
+
+func ExampleStandard_excl() {
+	foo := new(Z.Cmd)
+	foo.Params = []string{"json", "yaml", "to=", "force"}
+	foo.Excl = [][]string{{"json", "yaml", "to"}}
+	fmt.Println(comp.Standard(foo, ""))
+	fmt.Println(comp.Standard(foo, "yaml", ""))
+	fmt.Println(comp.Standard(foo, "to=out", ""))
+	// Output:
+	// [json yaml to= force]
+	// [force]
+	// [force]
+}
+
+func ExampleStandard_lastArg() {
+	foo := &Z.Cmd{Name: `foo`}
+	sub := foo.Add(`sub`, `s`)
+	sub.Params = []string{`par1`, `par2`, `other`}
+	sub.Add(`deeper`)
+	foo.Add(`sibling`)
+
+	complete := func(line string) {
+		fmt.Println(comp.Standard(foo, Z.ArgsFrom(line)[1:]...))
+	}
+
+	complete(`foo `)
+	complete(`foo s`)
+	complete(`foo sub `)
+	complete(`foo s `)
+	complete(`foo sub par1 par`)
+	complete(`foo sub par1 `)
+	complete(`foo sub deeper `)
+
+	// Output:
+	// [sub sibling]
+	// [sub sibling]
+	// [deeper par1 par2 other]
+	// [deeper par1 par2 other]
+	// [par2]
+	// [par2 other]
+	// []
+}
+
+func ExampleStatic() {
+	foo := new(Z.Cmd)
+	foo.Params = []string{"param1", "param2"}
+	foo.Completer = func(_ bonzai.Command, _ ...string) []string {
+		return []string{"slow"}
+	}
+	fmt.Println(comp.Standard(foo, ""))
+	fmt.Println(comp.Static(foo, ""))
+	// Output:
+	// [slow]
+	// [param1 param2]
+}
+
+func ExampleStandard_hidden() {
+	foo := &Z.Cmd{
+		Name:   `foo`,
+		Params: []string{`debug`, `dump`},
+		Hidden: []string{`debug`},
+		Commands: []*Z.Cmd{
+			{Name: `secret`, Hide: true, Commands: []*Z.Cmd{
+				{Name: `reveal`}, {Name: `rotate`},
+			}},
+			{Name: `search`},
+		},
+	}
+	fmt.Println(comp.Standard(foo, "se"))
+	fmt.Println(comp.Standard(foo, "secret"))
+	fmt.Println(comp.Standard(foo, "secret", ""))
+	fmt.Println(comp.Standard(foo, "secret", "re"))
+	fmt.Println(comp.Standard(foo, "d"))
+	fmt.Println(comp.Standard(foo, "debug"))
+	// Output:
+	// [search]
+	// [secret]
+	// [reveal rotate]
+	// [reveal]
+	// [dump]
+	// [debug]
+}
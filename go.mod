@@ -2,14 +2,7 @@ module github.com/rwxrob/bonzai
 
 go 1.18
 
-require (
-	github.com/rwxrob/fn v0.3.3
-	github.com/rwxrob/fs v0.5.0
-	github.com/rwxrob/scan v0.6.1
-	github.com/rwxrob/structs v0.5.1
-	github.com/rwxrob/term v0.2.6
-	github.com/rwxrob/to v0.4.5
-)
+require github.com/rwxrob/term v0.2.6
 
 require (
 	golang.org/x/crypto v0.0.0-20220408190544-5352b0902921 // indirect
@@ -0,0 +1,351 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rwxrob/bonzai"
+)
+
+// AliasesKey is the top-level key of the configuration (see Conf) that
+// users may use to define their own aliases in addition to the
+// hard-coded Aliases (which always take precedence). The value must be
+// a map of alias names to lists of arguments:
+//
+//     aliases:
+//       st: [status, --short]
+var AliasesKey = "aliases"
+
+var confAliases map[string][]string
+var confAliasesRun = -1
+
+// ConfAliases returns the aliases defined by the user in the
+// configuration under AliasesKey (see Conf). Entries that are not lists
+// of strings are logged as warnings and skipped. Returns nil if Conf is
+// not assigned or there are no such aliases. The aliases are only
+// queried once per Run.
+func ConfAliases() map[string][]string {
+	if Conf == nil {
+		return nil
+	}
+	if confAliasesRun == runs {
+		return confAliases
+	}
+	confAliases, confAliasesRun = queryAliases(), runs
+	return confAliases
+}
+
+func queryAliases() map[string][]string {
+	data := strings.TrimSpace(Conf.Query("." + AliasesKey + " | @json"))
+	if data == "" || data == "null" {
+		return nil
+	}
+	raw := map[string]json.RawMessage{}
+	if err := json.Unmarshal([]byte(data), &raw); err != nil {
+		logAt(LevelWarn, "", fmt.Sprintf("%v: %v", AliasesKey, err))
+		return nil
+	}
+	aliases := map[string][]string{}
+	for k, v := range raw {
+		var args []string
+		if err := json.Unmarshal(v, &args); err != nil || len(args) == 0 {
+			logAt(LevelWarn, "",
+				Msg(`aliases-not-list`, AliasesKey, k))
+			continue
+		}
+		aliases[k] = args
+	}
+	return aliases
+}
+
+// AllAliases returns the hard-coded Aliases combined with ConfAliases
+// (which never replace them).
+func AllAliases() map[string][]string {
+	all := map[string][]string{}
+	for k, v := range ConfAliases() {
+		all[k] = v
+	}
+	for k, v := range Aliases {
+		all[k] = v
+	}
+	return all
+}
+
+// writeConfAliases replaces the AliasesKey of the configuration with
+// the aliases preserving everything else (see bonzai.ConfigurerWriter
+// and Conf.OverWrite).
+func writeConfAliases(aliases map[string][]string) error {
+	if Conf == nil {
+		return errors.New(Msg(`aliases-need-conf`))
+	}
+	confAliasesRun = -1
+	if w, ok := Conf.(bonzai.ConfigurerWriter); ok {
+		buf, err := json.Marshal(aliases)
+		if err != nil {
+			return err
+		}
+		return w.Set(AliasesKey, string(buf))
+	}
+	conf := map[string]any{}
+	data := strings.TrimSpace(Conf.Query(". | @json"))
+	if data != "" && data != "null" {
+		if err := json.Unmarshal([]byte(data), &conf); err != nil {
+			return err
+		}
+	}
+	conf[AliasesKey] = aliases
+	return Conf.OverWrite(conf)
+}
+
+// AliasCmd is an optional builtin branch command (see Builtins) for
+// managing the aliases of the user (see ConfAliases) with the
+// following subcommands:
+//
+//     list            - all aliases (static from Aliases or config)
+//     add NAME ARG... - add (or replace) an alias in the configuration
+//     rm NAME         - remove an alias from the configuration
+//
+// Aliases that would shadow a command of the root cannot be added.
+var AliasCmd = &Cmd{
+	Name:     `alias`,
+	Summary:  `list, add, or remove command aliases`,
+	Commands: []*Cmd{aliasListCmd, aliasAddCmd, aliasRmCmd},
+}
+
+var aliasListCmd = &Cmd{
+	Name:    `list`,
+	Summary: `list all aliases`,
+	Call: func(x *Cmd, _ ...string) error {
+		conf := ConfAliases()
+		all := AllAliases()
+		var longest int
+		for k := range all {
+			if len(k) > longest {
+				longest = len(k)
+			}
+		}
+		for _, k := range keysWithPrefix(all, "") {
+			from := "config"
+			if _, has := Aliases[k]; has {
+				from = "static"
+				if _, has := conf[k]; has {
+					from = "static, overrides config"
+				}
+			}
+			fmt.Printf("%-*v -> %v (%v)\n", longest, k,
+				strings.Join(EscAll(all[k]), " "), from)
+		}
+		return nil
+	},
+}
+
+var aliasAddCmd = &Cmd{
+	Name:    `add`,
+	Summary: `add an alias to the configuration`,
+	Usage:   `NAME ARG...`,
+	MinArgs: 2,
+	Call: func(x *Cmd, args ...string) error {
+		name := args[0]
+		if x.Root().Resolve(name) != nil {
+			return errors.New(Msg(`alias-would-shadow`, name))
+		}
+		aliases := ConfAliases()
+		if aliases == nil {
+			aliases = map[string][]string{}
+		}
+		aliases[name] = args[1:]
+		return writeConfAliases(aliases)
+	},
+}
+
+var aliasRmCmd = &Cmd{
+	Name:    `rm`,
+	Summary: `remove an alias from the configuration`,
+	Usage:   `NAME`,
+	MinArgs: 1,
+	Completer: func(_ bonzai.Command, args ...string) []string {
+		if len(args) > 1 {
+			return []string{}
+		}
+		var pre string
+		if len(args) > 0 {
+			pre = args[0]
+		}
+		return keysWithPrefix(ConfAliases(), pre)
+	},
+	Call: func(x *Cmd, args ...string) error {
+		aliases := ConfAliases()
+		if _, has := aliases[args[0]]; !has {
+			return errors.New(Msg(`alias-not-found`, args[0]))
+		}
+		delete(aliases, args[0])
+		return writeConfAliases(aliases)
+	},
+}
+
+// ExpandAlias returns the words of the alias (see Aliases and
+// ConfAliases) followed by the args. Any word that is exactly one of
+// the following placeholders is replaced instead:
+//
+//     $1 ... $9 - the corresponding arg (which is then not appended)
+//     $@        - all args following the highest numbered placeholder
+//     $$        - a literal dollar sign (ex: $$1 is $1)
+//
+// When $@ is used nothing more is appended. If an arg referenced by
+// a placeholder is missing an error describing the args required is
+// returned (ex: "ARG1 ARG2 ...").
+func ExpandAlias(alias, args []string) ([]string, error) {
+	var high int
+	var all bool
+	for _, w := range alias {
+		switch {
+		case w == "$@":
+			all = true
+		case len(w) == 2 && w[0] == '$' && w[1] >= '1' && w[1] <= '9':
+			if n := int(w[1] - '0'); n > high {
+				high = n
+			}
+		}
+	}
+	if high > len(args) {
+		need := make([]string, high)
+		for i := range need {
+			need[i] = fmt.Sprintf("ARG%d", i+1)
+		}
+		if all {
+			need = append(need, "...")
+		}
+		return nil, errors.New(strings.Join(need, " "))
+	}
+	rest := args[high:]
+	words := []string{}
+	for _, w := range alias {
+		switch {
+		case w == "$@":
+			words = append(words, rest...)
+		case len(w) == 2 && w[0] == '$' && w[1] >= '1' && w[1] <= '9':
+			words = append(words, args[w[1]-'1'])
+		case strings.HasPrefix(w, "$$"):
+			words = append(words, w[1:])
+		default:
+			words = append(words, w)
+		}
+	}
+	if !all {
+		words = append(words, rest...)
+	}
+	return words, nil
+}
+
+// MaxAliasDepth is the maximum number of times Run will expand an alias
+// that expands to another alias.
+var MaxAliasDepth = 10
+
+// expandAliases returns the args with every alias (see AllAliases)
+// expanded (see rewriteAliases) returning an error if an alias loop is
+// detected or MaxAliasDepth is exceeded. The aliases are checked first
+// (see checkAliases).
+func (x *Cmd) expandAliases(args []string) ([]string, error) {
+	all := AllAliases()
+	if len(all) == 0 {
+		return args, nil
+	}
+	if err := x.checkAliases(all); err != nil {
+		return nil, err
+	}
+	return x.rewriteAliases(all, args)
+}
+
+// rewriteAliases walks the args the same way as Seek (passing over any
+// Flags) and expands (see ExpandAlias) the aliases in the position of
+// a Command name: the first arg and the one after any Command that has
+// Commands of its own. Only the first arg may shadow a Command (see
+// checkAliases). Everywhere else a Command with the same name (or
+// alias) always wins. This is done before anything else by both Run
+// and completion so that an alias can be completed, and then followed,
+// exactly as if its words had been typed.
+func (x *Cmd) rewriteAliases(all map[string][]string, args []string) ([]string, error) {
+	out := []string{}
+	scope := []*Cmd{x}
+	cur := x
+	var seen []string
+	for len(args) > 0 {
+		name := args[0]
+		if strings.HasPrefix(name, "-") && len(name) > 1 {
+			if name == "--" {
+				break
+			}
+			n := 1
+			if f, _, _, hasval := lookupFlag(scope, name); f != nil &&
+				f.Value && !hasval && len(args) > 1 {
+				n = 2
+			}
+			out, args = append(out, args[:n]...), args[n:]
+			continue
+		}
+		alias, has := all[name]
+		if has && (cur == x || len(cur.AllCommands()) > 0 && cur.Resolve(name) == nil) {
+			if contains(seen, name) {
+				return nil, errors.New(Msg(`alias-loop`,
+					strings.Join(append(seen, name), " -> ")))
+			}
+			if len(seen) >= MaxAliasDepth {
+				return nil, errors.New(Msg(`alias-too-deep`,
+					MaxAliasDepth, strings.Join(seen, " -> ")))
+			}
+			seen = append(seen, name)
+			words, err := ExpandAlias(alias, args[1:])
+			if err != nil {
+				path := append(append([]string{x.Name}, out...), name)
+				return nil, fmt.Errorf("%v: %v %v", usageText(),
+					strings.Join(path, " "), err)
+			}
+			if tracing() {
+				tracef("alias %v -> %q", name, alias)
+			}
+			args = words
+			continue
+		}
+		next := cur.Resolve(name)
+		if next == nil {
+			break
+		}
+		scope = append(scope, next)
+		cur, seen = next, nil
+		out, args = append(out, name), args[1:]
+	}
+	return append(out, args...), nil
+}
+
+// checkAliases logs a warning for every alias that shadows a Command
+// (name or alias) of x or differs from another alias only by case.
+// Under StrictTree an error with all of them is returned instead.
+func (x *Cmd) checkAliases(all map[string][]string) error {
+	var msgs []string
+	names := keysWithPrefix(all, "")
+	for i, k := range names {
+		for _, c := range x.AllCommands() {
+			if contains(c.Names(), k) {
+				msgs = append(msgs, Msg(`alias-shadows`, k))
+			}
+		}
+		for _, o := range names[i+1:] {
+			if strings.EqualFold(k, o) {
+				msgs = append(msgs,
+					Msg(`alias-case`, k, o))
+			}
+		}
+	}
+	if StrictTree && len(msgs) > 0 {
+		return errors.New(strings.Join(msgs, "\n"))
+	}
+	for _, m := range msgs {
+		logAt(LevelWarn, "", m)
+	}
+	return nil
+}
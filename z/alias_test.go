@@ -0,0 +1,292 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func ExampleConfAliases() {
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	defer log.SetOutput(os.Stderr)
+	defer log.SetFlags(log.Flags())
+	log.SetOutput(os.Stdout)
+	log.SetFlags(0)
+	defer func() { Z.Conf = nil }()
+	orig := os.Args
+	defer func() { os.Args = orig }()
+	comp := os.Getenv("COMP_LINE")
+	defer os.Setenv("COMP_LINE", comp)
+	defer delete(Z.Aliases, "st")
+
+	Z.Aliases["st"] = []string{"status"}
+	Z.Conf = Z.NewMapConf(
+		"aliases.st", `["status","--long"]`,
+		"aliases.sts", `["status","--short"]`,
+		"aliases.bad", "status",
+	)
+
+	x := &Z.Cmd{
+		Name: `foo`,
+		Commands: []*Z.Cmd{
+			&Z.Cmd{
+				Name: `status`,
+				Call: func(_ *Z.Cmd, args ...string) error {
+					fmt.Println(args)
+					return nil
+				},
+			},
+		},
+	}
+
+	os.Args = []string{"foo", "sts", "more"}
+	x.Run()
+
+	os.Args = []string{"foo", "st"}
+	x.Run()
+
+	os.Setenv("COMP_LINE", "foo s")
+	x.Run()
+
+	// Output:
+	// warning: aliases: bad: must be a list of strings
+	// [--short more]
+	// warning: aliases: bad: must be a list of strings
+	// []
+	// warning: aliases: bad: must be a list of strings
+	// st
+	// status
+	// sts
+}
+
+func ExampleAliasCmd() {
+	defer logErrs()()
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	defer log.SetOutput(os.Stderr)
+	defer log.SetFlags(log.Flags())
+	log.SetOutput(os.Stdout)
+	log.SetFlags(0)
+	defer func() { Z.Conf = nil }()
+	orig := os.Args
+	defer func() { os.Args = orig }()
+	comp := os.Getenv("COMP_LINE")
+	defer os.Setenv("COMP_LINE", comp)
+	defer delete(Z.Aliases, "st")
+
+	Z.Aliases["st"] = []string{"status"}
+	conf := Z.NewMapConf("other", "kept")
+	Z.Conf = conf
+
+	x := &Z.Cmd{
+		Name:     `foo`,
+		Commands: []*Z.Cmd{&Z.Cmd{Name: `status`}, Z.AliasCmd},
+	}
+
+	os.Args = []string{"foo", "alias", "add", "sts", "status", "with space"}
+	x.Run()
+	os.Args = []string{"foo", "alias", "add", "status", "x"}
+	x.Run()
+	os.Args = []string{"foo", "alias", "list"}
+	x.Run()
+	fmt.Println(conf.Data())
+
+	os.Setenv("COMP_LINE", "foo alias rm s")
+	x.Run()
+	os.Setenv("COMP_LINE", "")
+
+	os.Args = []string{"foo", "alias", "rm", "sts"}
+	x.Run()
+	os.Args = []string{"foo", "alias", "rm", "st"}
+	x.Run()
+	fmt.Println(conf.Data())
+
+	// Output:
+	// alias would shadow command: "status"
+	// st  -> status (static)
+	// sts -> status with\ space (config)
+	// {"aliases":{"sts":["status","with space"]},"other":"kept"}
+	// sts
+	// no such alias in configuration: "st"
+	// {"aliases":{},"other":"kept"}
+}
+
+func ExampleExpandAlias() {
+	fmt.Println(Z.ExpandAlias([]string{"status"}, []string{"a", "b"}))
+	fmt.Println(Z.ExpandAlias([]string{"deploy", "--to", "$1", "now"}, []string{"prod", "x"}))
+	fmt.Println(Z.ExpandAlias([]string{"cp", "$@", "$1"}, []string{"dst", "a", "b"}))
+	fmt.Println(Z.ExpandAlias([]string{"echo", "$$1", "$5USD"}, []string{"a"}))
+	fmt.Println(Z.ExpandAlias([]string{"mv", "$2", "$1", "$@"}, []string{"a"}))
+	// Output:
+	// [status a b] <nil>
+	// [deploy --to prod now x] <nil>
+	// [cp a b dst] <nil>
+	// [echo $1 $5USD a] <nil>
+	// [] ARG1 ARG2 ...
+}
+
+func ExampleCmd_Run_alias_placeholders() {
+	defer logErrs()()
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	defer log.SetOutput(os.Stderr)
+	defer log.SetFlags(log.Flags())
+	log.SetOutput(os.Stdout)
+	log.SetFlags(0)
+	orig := os.Args
+	defer func() { os.Args = orig }()
+	defer delete(Z.Aliases, "deploy-to")
+	Z.Aliases["deploy-to"] = []string{"deploy", "--env", "$1", "--yes"}
+
+	x := &Z.Cmd{
+		Name: `foo`,
+		Commands: []*Z.Cmd{
+			&Z.Cmd{
+				Name: `deploy`,
+				Call: func(_ *Z.Cmd, args ...string) error {
+					fmt.Println(args)
+					return nil
+				},
+			},
+		},
+	}
+
+	os.Args = []string{"foo", "deploy-to", "prod", "extra"}
+	x.Run()
+
+	os.Args = []string{"foo", "deploy-to"}
+	x.Run()
+
+	// Output:
+	// [--env prod --yes extra]
+	// usage: foo deploy-to ARG1
+}
+
+func ExampleCmd_Run_alias_loop() {
+	defer logErrs()()
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	defer log.SetOutput(os.Stderr)
+	defer log.SetFlags(log.Flags())
+	log.SetOutput(os.Stdout)
+	log.SetFlags(0)
+	orig := os.Args
+	defer func() { os.Args = orig }()
+	defer func() { Z.Aliases = map[string][]string{}; Z.StrictTree = false }()
+
+	x := &Z.Cmd{
+		Name:    `foo`,
+		Default: `status`,
+		Commands: []*Z.Cmd{
+			&Z.Cmd{
+				Name: `status`,
+				Call: func(_ *Z.Cmd, args ...string) error {
+					fmt.Println(args)
+					return nil
+				},
+			},
+		},
+	}
+
+	Z.Aliases = map[string][]string{
+		"s":  {"st", "--short"},
+		"st": {"status"},
+		"a":  {"b"},
+		"b":  {"a"},
+	}
+	os.Args = []string{"foo", "s", "x"}
+	x.Run()
+	os.Args = []string{"foo", "a"}
+	x.Run()
+
+	Z.Aliases = map[string][]string{
+		"status": {"help"},
+		"ST":     {"status"},
+		"st":     {"status"},
+	}
+	os.Args = []string{"foo", "st"}
+	x.Run()
+
+	Z.StrictTree = true
+	x.Run()
+
+	// Output:
+	// [--short x]
+	// alias loop: a -> b -> a
+	// warning: aliases differ only by case: "ST", "st"
+	// warning: alias shadows command: "status"
+	// [help]
+	// aliases differ only by case: "ST", "st"
+	// alias shadows command: "status"
+}
+
+func ExampleCmd_Run_alias_nested() {
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	orig := os.Args
+	defer func() { os.Args = orig }()
+	defer func(a map[string][]string) { Z.Aliases = a }(Z.Aliases)
+	defer os.Unsetenv("COMP_LINE")
+
+	Z.Aliases = map[string][]string{
+		"up":  {"db", "migrate", "--to"},
+		"mig": {"migrate"},
+		"m3":  {"migrate", "--to", "v3"},
+	}
+
+	x := &Z.Cmd{
+		Name: `foo`,
+		Commands: []*Z.Cmd{{
+			Name: `db`,
+			Commands: []*Z.Cmd{
+				{
+					Name:   `migrate`,
+					Params: []string{`latest`, `last`},
+					Flags:  []Z.Flag{{Name: `to`, Value: true}},
+					Call: func(x *Z.Cmd, args ...string) error {
+						fmt.Println("migrate", x.Flag("to"), args)
+						return nil
+					},
+				},
+				{Name: `mig`, Call: func(_ *Z.Cmd, _ ...string) error {
+					fmt.Println("mig wins")
+					return nil
+				}},
+				{Name: `seed`},
+			},
+		}},
+	}
+
+	os.Args = []string{"foo", "up", "v2", "now"}
+	x.Run()
+	os.Args = []string{"foo", "db", "mig"}
+	x.Run()
+	os.Args = []string{"foo", "db", "m3", "latest"}
+	x.Run()
+
+	fmt.Println("--- continuation")
+	os.Setenv("COMP_LINE", "foo up v2 la")
+	x.Run()
+	fmt.Println("--- nested name")
+	os.Setenv("COMP_LINE", "foo db m")
+	x.Run()
+	fmt.Println("--- nested continuation")
+	os.Setenv("COMP_LINE", "foo db m3 ")
+	x.Run()
+
+	// Output:
+	// migrate v2 [now]
+	// mig wins
+	// migrate v3 [latest]
+	// --- continuation
+	// last
+	// latest
+	// --- nested name
+	// m3
+	// mig
+	// migrate
+	// --- nested continuation
+	// last
+	// latest
+}
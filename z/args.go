@@ -0,0 +1,210 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrMissingArg is the Err of an ArgError for an argument that was not
+// passed at all.
+var ErrMissingArg error = msgError(`missing-arg`)
+
+// msgError is an error with the message of the ID for the current Lang
+// at the time it is printed (see Msg).
+type msgError string
+
+// Error fulfills the error interface.
+func (e msgError) Error() string { return Msg(string(e)) }
+
+// ArgError is returned by all the typed argument accessors (ArgInt,
+// Args.Int, etc.) when an argument is missing or invalid. It is
+// a usage-class error: the message includes the PathString of the Cmd
+// (or its Name if it is the root, or that of its Caller if it
+// WasDefaulted) and ends with its usage line (see UsageError).
+type ArgError struct {
+	Cmd   *Cmd
+	Index int    // position of the argument (from 0)
+	Arg   string // the argument itself (empty if missing)
+	Err   error  // reason it is invalid
+}
+
+// Error fulfills the error interface.
+func (e *ArgError) Error() string {
+	c := e.Cmd
+	if c.WasDefaulted() {
+		c = c.Caller
+	}
+	path := c.PathString()
+	if path == "" {
+		path = c.Name
+	}
+	msg := Msg(`arg-error`, path, e.Index+1)
+	if !errors.Is(e.Err, ErrMissingArg) {
+		msg += fmt.Sprintf(" (%q)", e.Arg)
+	}
+	return msg + ": " + e.Err.Error() + "\n" + e.Cmd.UsageError().Error()
+}
+
+// Unwrap returns the Err.
+func (e *ArgError) Unwrap() error { return e.Err }
+
+// Arg returns the argument at index i or an ArgError wrapping
+// ErrMissingArg if there is none.
+func (x *Cmd) Arg(args []string, i int) (string, error) {
+	if i < 0 || i >= len(args) {
+		return "", &ArgError{x, i, "", ErrMissingArg}
+	}
+	return args[i], nil
+}
+
+// ArgInt returns the argument at index i as an int (see strconv.Atoi).
+func (x *Cmd) ArgInt(args []string, i int) (int, error) {
+	a, err := x.Arg(args, i)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.Atoi(a)
+	if err != nil {
+		return 0, &ArgError{x, i, a, msgError(`not-integer`)}
+	}
+	return n, nil
+}
+
+// ArgBool returns the argument at index i as a bool (see
+// strconv.ParseBool).
+func (x *Cmd) ArgBool(args []string, i int) (bool, error) {
+	a, err := x.Arg(args, i)
+	if err != nil {
+		return false, err
+	}
+	b, err := strconv.ParseBool(a)
+	if err != nil {
+		return false, &ArgError{x, i, a, msgError(`not-boolean`)}
+	}
+	return b, nil
+}
+
+// ArgDuration returns the argument at index i as a time.Duration (see
+// time.ParseDuration).
+func (x *Cmd) ArgDuration(args []string, i int) (time.Duration, error) {
+	a, err := x.Arg(args, i)
+	if err != nil {
+		return 0, err
+	}
+	d, err := time.ParseDuration(a)
+	if err != nil {
+		return 0, &ArgError{x, i, a, msgError(`not-duration`)}
+	}
+	return d, nil
+}
+
+// ArgEnum returns the argument at index i only if it is one of those
+// allowed.
+func (x *Cmd) ArgEnum(args []string, i int, allowed ...string) (string, error) {
+	a, err := x.Arg(args, i)
+	if err != nil {
+		return "", err
+	}
+	for _, v := range allowed {
+		if a == v {
+			return a, nil
+		}
+	}
+	return "", &ArgError{x, i, a,
+		errors.New(Msg(`must-be-one-of`, strings.Join(allowed, ", ")))}
+}
+
+// ArgFile returns the argument at index i only if it is the path to an
+// existing file (or directory).
+func (x *Cmd) ArgFile(args []string, i int) (string, error) {
+	a, err := x.Arg(args, i)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(a); err != nil {
+		return "", &ArgError{x, i, a, msgError(`file-not-found`)}
+	}
+	return a, nil
+}
+
+// Args wraps the arguments passed to a Method so they can be consumed
+// progressively with the same typed accessors (and errors) as the Cmd.
+// Each accessor consumes the next argument, even if it is invalid.
+type Args struct {
+	Cmd  *Cmd
+	List []string
+	N    int // index of the next argument
+}
+
+// NewArgs returns Args for the args passed to the Method of the Cmd.
+func NewArgs(x *Cmd, args []string) *Args { return &Args{Cmd: x, List: args} }
+
+// Len returns the number of arguments not yet consumed.
+func (a *Args) Len() int { return len(a.List) - a.N }
+
+// Rest consumes and returns all the remaining arguments.
+func (a *Args) Rest() []string {
+	if a.N >= len(a.List) {
+		return []string{}
+	}
+	rest := a.List[a.N:]
+	a.N = len(a.List)
+	return rest
+}
+
+func (a *Args) next() int { a.N++; return a.N - 1 }
+
+// String consumes the next argument (see Cmd.Arg).
+func (a *Args) String() (string, error) { return a.Cmd.Arg(a.List, a.next()) }
+
+// Int consumes the next argument (see Cmd.ArgInt).
+func (a *Args) Int() (int, error) { return a.Cmd.ArgInt(a.List, a.next()) }
+
+// Bool consumes the next argument (see Cmd.ArgBool).
+func (a *Args) Bool() (bool, error) { return a.Cmd.ArgBool(a.List, a.next()) }
+
+// Duration consumes the next argument (see Cmd.ArgDuration).
+func (a *Args) Duration() (time.Duration, error) {
+	return a.Cmd.ArgDuration(a.List, a.next())
+}
+
+// Enum consumes the next argument (see Cmd.ArgEnum).
+func (a *Args) Enum(allowed ...string) (string, error) {
+	return a.Cmd.ArgEnum(a.List, a.next(), allowed...)
+}
+
+// File consumes the next argument (see Cmd.ArgFile).
+func (a *Args) File() (string, error) { return a.Cmd.ArgFile(a.List, a.next()) }
+
+// ArgFiles returns the args with every one of the form @FILE replaced
+// by the fields (separated by any white space, including line endings)
+// read from the FILE. A leading @@ is replaced with a single literal @
+// instead. Errors reading a FILE are returned as an ArgError. Run calls
+// ArgFiles (before checking MinArgs) for any Cmd with ExpandArgFiles
+// set. Completion of words beginning with @ completes files.
+func (x *Cmd) ArgFiles(args []string) ([]string, error) {
+	out := []string{}
+	for i, a := range args {
+		switch {
+		case strings.HasPrefix(a, "@@"):
+			out = append(out, a[1:])
+		case strings.HasPrefix(a, "@") && len(a) > 1:
+			buf, err := os.ReadFile(a[1:])
+			if err != nil {
+				return nil, &ArgError{x, i, a,
+					errors.New(Msg(`cannot-read-argfile`, a[1:]))}
+			}
+			out = append(out, strings.Fields(string(buf))...)
+		default:
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
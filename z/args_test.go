@@ -0,0 +1,127 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func ExampleCmd_ArgInt() {
+	x := &Z.Cmd{
+		Name: `foo`,
+		Commands: []*Z.Cmd{
+			&Z.Cmd{
+				Name:  `wait`,
+				Usage: `COUNT DURATION`,
+				Call:  func(_ *Z.Cmd, _ ...string) error { return nil },
+			},
+		},
+	}
+	wait, _ := x.Seek([]string{"wait"})
+
+	n, err := wait.ArgInt([]string{"3"}, 0)
+	fmt.Println(n, err)
+
+	_, err = wait.ArgInt([]string{"three"}, 0)
+	fmt.Println(err)
+
+	_, err = wait.ArgDuration([]string{"3"}, 1)
+	fmt.Println(errors.Is(err, Z.ErrMissingArg))
+
+	_, err = wait.ArgEnum([]string{"csv"}, 0, "json", "yaml")
+	var aerr *Z.ArgError
+	fmt.Println(errors.As(err, &aerr), aerr.Arg)
+
+	// Output:
+	// 3 <nil>
+	// wait: argument 1 ("three"): not an integer
+	// usage: wait COUNT DURATION
+	// true
+	// true csv
+}
+
+func ExampleArgError_exit() {
+	defer func(p func(error)) { Z.ErrPrinter = p }(Z.ErrPrinter)
+	Z.ErrPrinter = func(err error) { fmt.Println(err) }
+	orig := os.Args
+	defer func() { os.Args = orig }()
+	rec := new(Z.RecordingExiter)
+	defer Z.SetExiter(rec)()
+
+	x := &Z.Cmd{
+		Name:  `foo`,
+		Usage: `COUNT`,
+		Call: func(x *Z.Cmd, args ...string) error {
+			_, err := x.ArgInt(args, 0)
+			return err
+		},
+	}
+	os.Args = []string{"foo", "three"}
+	x.Run()
+	fmt.Println(rec.Last() == Z.ExitUsage, rec.Last())
+
+	// Output:
+	// foo: argument 1 ("three"): not an integer
+	// usage: foo COUNT
+	// true 2
+}
+
+func ExampleNewArgs() {
+	x := &Z.Cmd{Name: `foo`, Usage: `COUNT VERBOSE [FILE ...]`}
+	args := Z.NewArgs(x, []string{"2", "true", "a", "b"})
+	n, _ := args.Int()
+	v, _ := args.Bool()
+	fmt.Println(n, v, args.Len(), args.Rest(), args.Len())
+	_, err := args.String()
+	fmt.Println(err)
+
+	// Output:
+	// 2 true 2 [a b] 0
+	// foo: argument 5: missing argument
+	// usage: foo COUNT VERBOSE [FILE ...]
+}
+
+func ExampleCmd_ArgFiles() {
+	defer logErrs()()
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	defer log.SetOutput(os.Stderr)
+	defer log.SetFlags(log.Flags())
+	log.SetOutput(os.Stdout)
+	log.SetFlags(0)
+	orig := os.Args
+	defer func() { os.Args = orig }()
+	comp := os.Getenv("COMP_LINE")
+	defer os.Setenv("COMP_LINE", comp)
+
+	x := &Z.Cmd{
+		Name:           `ping`,
+		Usage:          `HOST...`,
+		MinArgs:        3,
+		ExpandArgFiles: true,
+		Call: func(_ *Z.Cmd, args ...string) error {
+			fmt.Println(args)
+			return nil
+		},
+	}
+
+	os.Args = []string{"ping", "@testdata/hosts"}
+	x.Run()
+
+	os.Args = []string{"ping", "a", "@@b", "@testdata/nothere"}
+	x.Run()
+
+	os.Setenv("COMP_LINE", "ping @testdata/ho")
+	x.Run()
+
+	// Output:
+	// [host1 host2 host3]
+	// ping: argument 3 ("@testdata/nothere"): cannot read argument file: testdata/nothere
+	// usage: ping HOST...
+	// @testdata/hosts
+}
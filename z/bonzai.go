@@ -21,14 +21,19 @@ following types of nodes:
 package Z
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"runtime"
+	"runtime/debug"
 	"strings"
+	"sync"
+	"unicode"
 
 	"github.com/rwxrob/bonzai"
-	"github.com/rwxrob/term"
 )
 
 func init() {
@@ -60,10 +65,19 @@ var ExePath string
 var ExeName string
 
 // Commands contains the commands to lookup when Run-ing an executable
-// in "multicall" mode. Each value must begin with a *Cmd and the rest
-// will be assumed to be string arguments to prepend. See Run.
+// in "multicall" mode. Each value must begin with a *Cmd (or a func()
+// *Cmd called only when its name is matched, for commands that are
+// expensive to create) and the rest will be assumed to be string
+// arguments to prepend. See Run.
 var Commands map[string][]any
 
+// Builtins are optional commands (such as VersionCmd) that Run injects
+// into the Commands of the root Cmd being run unless a command with the
+// same Name is already there. Since they are added to the end of
+// Commands they never change which is the default (first) command. By
+// default, no builtins are injected.
+var Builtins []*Cmd
+
 // Conf may be optionally assigned any implementation of
 // a bonzai.Configurer. Once assigned it should not be reassigned at any
 // later time during runtime. Certain Bonzai branches and commands may
@@ -81,9 +95,9 @@ var Commands map[string][]any
 // implementation to switch everything that depends on configuration.
 var Conf bonzai.Configurer
 
-// UsageText is used for one-line UsageErrors. It's exported to allow
-// for different languages.
-var UsageText = `usage`
+// UsageText, if set, is used for one-line UsageErrors instead of the
+// "usage" message of the current Lang (see Msg).
+var UsageText string
 
 // UsageFunc is the default first-class function called if a Cmd that
 // does not already define its own when usage information is needed (see
@@ -94,47 +108,49 @@ var UsageText = `usage`
 // return a single line (even if that line is very long).  Developers
 // are encouraged to refer users to their chosen help command rather
 // than producing usually long usage lines. If only the word "usage"
-// needs to be changed (for a given language) consider UsageText
+// needs to be changed (for a given language) consider Lang and AddLang
 // instead. Note that most developers will simply change the Usage
 // string when they do not want the default inferred usage string.
 var UsageFunc = InferredUsage
 
 // InferredUsage returns a single line of text summarizing only the
-// Commands (less any Hidden commands), Params, and Aliases. If a Cmd
-// is currently in an invalid state (Params without Call, no Call and no
+// Commands (less any Hidden commands), Params, and Aliases. If a Cmd is
+// currently in an invalid state (Params without Call, no Call and no
 // Commands) a string beginning with ERROR and wrapped in braces ({}) is
-// returned instead. The string depends on the current language (see
-// lang.go). Note that aliases does not include package Z.Aliases.
+// returned instead. The string depends on the current Lang (see Msg).
+// When there are both Params and Commands they are combined into a
+// single group of alternatives (ex: ([p1|p2]...|(f|foo)|bar)). Note
+// that the Aliases do not include those of the package (see Z.Aliases).
 func InferredUsage(cmd bonzai.Command) string {
 
 	x, iscmd := cmd.(*Cmd)
 	if !iscmd {
-		return "{ERROR: not a bonzai.Command}"
+		return Msg(`not-command`)
 	}
 
-	if x.Call == nil && x.Commands == nil {
-		return "{ERROR: neither Call nor Commands defined}"
+	cmds := x.AllCommands()
+
+	if !x.Callable() && cmds == nil {
+		return Msg(`no-call-no-commands`)
 	}
 
-	if x.Call == nil && x.Params != nil {
-		return "{ERROR: Params without Call: " + strings.Join(x.Params, ", ") + "}"
+	if !x.Callable() && x.Params != nil {
+		return Msg(`params-without-call`, strings.Join(x.Params, ", "))
 	}
 
-	params := UsageGroup(x.Params, x.MinParm, x.MaxParm)
+	params := UsageGroup(usageParams(x.Params), x.MinParm, x.MaxParm)
 
-	var names string
-	if x.Commands != nil {
-		var snames []string
-		for _, x := range x.Commands {
-			snames = append(snames, x.UsageNames())
-		}
-		if len(snames) > 0 {
-			names = UsageGroup(snames, 1, 1)
+	var snames []string
+	for _, c := range cmds {
+		if x.IsHidden(c.Name) {
+			continue
 		}
+		snames = append(snames, c.UsageNames())
 	}
+	names := UsageGroup(snames, 1, 1)
 
 	if params != "" && names != "" {
-		return "(" + params + "|" + names + ")"
+		return "(" + params + "|" + strings.Join(snames, "|") + ")"
 	}
 
 	if params != "" {
@@ -147,37 +163,89 @@ func InferredUsage(cmd bonzai.Command) string {
 // Run infers the name of the command to run from the ExeName looked up
 // in the Commands delegates accordingly, prepending any arguments
 // provided in the Cmd.Run. Run produces an "unmapped multicall command"
-// error if no match is found. This is an alternative to the simpler,
-// direct Cmd.Run method from main where only one possible Cmd will ever
-// be the root and allows for BusyBox (https://www.busybox.net)
-// multicall binaries to be used for such things as very light-weight
-// Linux distributions when used "FROM SCRATCH" in containers.
+// error (listing the names available) if no match is found. This is an
+// alternative to the simpler, direct Cmd.Run method from main where
+// only one possible Cmd will ever be the root and allows for BusyBox
+// (https://www.busybox.net) multicall binaries to be used for such
+// things as very light-weight Linux distributions when used "FROM
+// SCRATCH" in containers.
+//
+// The ExeName is matched exactly, then without any .exe or .test
+// suffix, and (on Windows only) case-insensitively. If none match, the
+// reserved empty ("") entry of Commands, if any, is used as a fallback
+// (handy during development when the binary is named a.out or such).
+//
+// When completing (see CompLine) the prepended arguments are spliced
+// into the COMP_LINE as well so that completion begins at the same
+// level of the tree.
 func Run() {
-	if v, has := Commands[ExeName]; has {
-		if len(v) < 1 {
-			ExitError(fmt.Errorf("multicall command missing"))
+	v, has := multicall(ExeName)
+	if !has {
+		ExitError(errors.New(Msg(`multicall-unmapped`,
+			ExeName, strings.Join(multicallNames(), ", "))))
+		return
+	}
+	if len(v) < 1 {
+		ExitError(errors.New(Msg(`multicall-missing`)))
+		return
+	}
+	var cmd *Cmd
+	switch c := v[0].(type) {
+	case *Cmd:
+		cmd = c
+	case func() *Cmd:
+		cmd = c()
+	default:
+		ExitError(errors.New(Msg(`multicall-first`, v[0])))
+		return
+	}
+	if cmd == nil {
+		ExitError(errors.New(Msg(`multicall-missing`)))
+		return
+	}
+	args := []string{cmd.Name}
+	for _, a := range v[1:] {
+		s, isstring := a.(string)
+		if !isstring {
+			ExitError(errors.New(Msg(`multicall-not-string`)))
+			return
 		}
-		cmd, iscmd := v[0].(*Cmd)
-		if !iscmd {
-			ExitError(fmt.Errorf("first value must be *Cmd"))
+		args = append(args, s)
+	}
+	if len(os.Args) > 1 {
+		args = append(args, os.Args[1:]...)
+	}
+	if line := CompLine(); line != "" && len(v) > 1 {
+		if first, rest, found := strings.Cut(line, " "); found {
+			pre := strings.Join(EscAll(args[1:len(v)]), " ")
+			os.Setenv("COMP_LINE", first+" "+pre+" "+rest)
+			os.Unsetenv("COMP_POINT")
 		}
-		args := []string{cmd.Name}
-		if len(v) > 1 {
-			rest := os.Args[1:]
-			for _, a := range v[1:] {
-				s, isstring := a.(string)
-				if !isstring {
-					ExitError(fmt.Errorf("only string arguments allowed"))
-				}
-				args = append(args, s)
+	}
+	os.Args = args
+	cmd.Run()
+	Exit()
+}
+
+// multicall returns the Commands entry for the name (see Run).
+func multicall(name string) ([]any, bool) {
+	if v, has := Commands[name]; has {
+		return v, true
+	}
+	for _, suf := range []string{".exe", ".test"} {
+		if v, has := Commands[strings.TrimSuffix(name, suf)]; has {
+			return v, true
+		}
+	}
+	if runtime.GOOS == "windows" {
+		for k, v := range Commands {
+			if k != "" && strings.EqualFold(k, name) {
+				return v, true
 			}
-			args = append(args, rest...)
 		}
-		os.Args = args
-		cmd.Run()
-		Exit()
 	}
-	ExitError(fmt.Errorf("unmapped multicall command: %v", ExeName))
+	v, has := Commands[""]
+	return v, has
 }
 
 // Method defines the main code to execute for a command (Cmd). By
@@ -188,57 +256,202 @@ func Run() {
 type Method func(caller *Cmd, args ...string) error
 
 // DoNotExit effectively disables Exit and ExitError allowing the
-// program to continue running, usually for test evaluation.
+// program to continue running (as if DefaultExiter were a NopExiter).
+// Prefer SetExiter with a RecordingExiter for testing.
 var DoNotExit bool
 
-// ExitOff sets DoNotExit to false.
+// ExitOff sets DoNotExit to true.
 func ExitOff() { DoNotExit = true }
 
-// ExitOn sets DoNotExit to true.
+// ExitOn sets DoNotExit to false.
 func ExitOn() { DoNotExit = false }
 
-// Exit calls os.Exit(0) unless DoNotExit has been set to true. Cmds
-// should never call Exit themselves returning a nil error from their
-// Methods instead.
-func Exit() {
+var atexit []*func()
+var atexitmu sync.Mutex
+
+// AtExit registers a cleanup function (removing temporary files,
+// releasing locks, stopping child processes, etc.) to be called by
+// Exit, ExitError, and TrapPanic before the process terminates. The
+// functions are called in reverse order of registration (LIFO) and
+// only once (they are cleared after being called). A panic in any of
+// them is logged and the rest are still called. The functions are
+// called even when DoNotExit is set.
+func AtExit(f func()) { atExit(f) }
+
+// atExit registers the function with AtExit returning another that
+// removes it again (without calling it) for those that are done before
+// the process exits and must not be kept around until then.
+func atExit(f func()) func() {
+	atexitmu.Lock()
+	defer atexitmu.Unlock()
+	p := &f
+	atexit = append(atexit, p)
+	return func() {
+		atexitmu.Lock()
+		defer atexitmu.Unlock()
+		for i, e := range atexit {
+			if e == p {
+				atexit = append(atexit[:i:i], atexit[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// ClearAtExit removes all functions registered with AtExit without
+// calling them (usually for test isolation).
+func ClearAtExit() {
+	atexitmu.Lock()
+	defer atexitmu.Unlock()
+	atexit = nil
+}
+
+func runAtExit() {
+	atexitmu.Lock()
+	fns := atexit
+	atexit = nil
+	atexitmu.Unlock()
+	for i := len(fns) - 1; i >= 0; i-- {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					logAt(LevelWarn, "", fmt.Sprintf("exit handler panicked: %v", r))
+				}
+			}()
+			(*fns[i])()
+		}()
+	}
+}
+
+// Exit exits with 0 (see DefaultExiter) unless DoNotExit has been set
+// to true. Cmds should never call Exit themselves returning a nil error
+// from their Methods instead. Any functions registered with AtExit are
+// called first.
+func Exit() { exit(0) }
+
+// exitErr records the error of the last ExitError (see TestRun).
+var exitErr error
+
+// exit calls any functions registered with AtExit and then the Exit of
+// the DefaultExiter with the code unless DoNotExit has been set.
+func exit(code int) {
+	runAtExit()
 	if !DoNotExit {
-		os.Exit(0)
+		DefaultExiter.Exit(code)
 	}
 }
 
-// ExitError prints err and exits with 1 return value unless DoNotExit
-// has been set to true. Commands should usually never call ExitError
-// themselves returning an error from their Method instead.
+// ExitError prints err (see ErrPrinter) and exits with 1, or ExitUsage
+// for an ArgError (see DefaultExiter), unless DoNotExit has been set to
+// true. Commands should usually never call ExitError themselves
+// returning an error from their Method instead. Any functions
+// registered with AtExit are called after printing.
 func ExitError(err ...interface{}) {
 	switch e := err[0].(type) {
 	case string:
 		if len(e) > 1 {
-			log.Printf(e+"\n", err[1:]...)
-		} else {
-			log.Println(e)
+			e = fmt.Sprintf(e, err[1:]...)
 		}
+		exitErr = errors.New(e)
+		printErr(exitErr)
 	case error:
-		out := fmt.Sprintf("%v", e)
-		if len(out) > 0 {
-			log.Println(out)
+		if len(e.Error()) > 0 {
+			printErr(e)
 		}
+		exitErr = e
 	}
-	if !DoNotExit {
-		os.Exit(1)
+	var aerr *ArgError
+	if errors.As(exitErr, &aerr) {
+		exit(ExitUsage)
+		return
+	}
+	exit(1)
+}
+
+// ErrPrinter is called by ExitError to print the error to the user
+// (PrintErr by default) unless LogErrors (or LogJSON) is set. Assign it
+// to change the destination or format of all error messages.
+var ErrPrinter = PrintErr
+
+// LogErrors causes ExitError to log errors (see Cmd.Error) instead of
+// calling ErrPrinter as was done before ErrPrinter existed.
+var LogErrors bool
+
+// PrintErr writes the error to os.Stderr prefixed with the ExeName
+// (ex: foo: something failed) like most UNIX commands.
+func PrintErr(err error) {
+	fmt.Fprintf(os.Stderr, "%v: %v\n", ExeName, err)
+}
+
+func printErr(err error) {
+	if LogErrors || LogJSON || ErrPrinter == nil {
+		logAt(LevelError, "", err.Error())
+		return
 	}
+	ErrPrinter(err)
 }
 
-// ArgsFrom returns a list of field strings split on space with an extra
-// trailing special space item appended if the line has any trailing
-// spaces at all signifying a definite word boundary and not a potential
-// prefix.
+// ArgsFrom returns a list of field strings split on unquoted white
+// space with an extra trailing special space item appended if the line
+// has any trailing (unquoted and unescaped) spaces at all signifying
+// a definite word boundary and not a potential prefix. Arguments are
+// parsed similar to a POSIX shell:
+//
+//     'single'  - everything is literal until the next single quote
+//     "double"  - backslash escapes only \, ", $, and ` characters
+//     \x        - backslash escapes any character outside of quotes
+//
+// Unterminated quotes include the remainder of the line as a single
+// argument (which is usually what is wanted when completing).
 func ArgsFrom(line string) []string {
 	args := []string{}
-	if line == "" {
-		return args
+	var buf []rune
+	var inword, escaped, trailing bool
+	var quote rune
+	for _, r := range line {
+		trailing = false
+		switch {
+		case escaped:
+			if quote == '"' && !strings.ContainsRune("\\\"$`", r) {
+				buf = append(buf, '\\')
+			}
+			buf = append(buf, r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+				continue
+			}
+			buf = append(buf, r)
+		case quote == '"':
+			switch r {
+			case '\\':
+				escaped = true
+			case '"':
+				quote = 0
+			default:
+				buf = append(buf, r)
+			}
+		case r == '\\':
+			escaped, inword = true, true
+		case r == '\'' || r == '"':
+			quote, inword = r, true
+		case unicode.IsSpace(r):
+			if inword {
+				args = append(args, string(buf))
+				buf = buf[:0]
+				inword = false
+			}
+			trailing = true
+		default:
+			buf = append(buf, r)
+			inword = true
+		}
 	}
-	args = strings.Fields(line)
-	if line[len(line)-1] == ' ' {
+	if inword {
+		args = append(args, string(buf))
+	}
+	if trailing {
 		args = append(args, "")
 	}
 	return args
@@ -250,16 +463,60 @@ func ArgsFrom(line string) []string {
 // until end of file reached (Cntl-D).
 func ArgsOrIn(args []string) string {
 	if args == nil || len(args) == 0 {
-		return term.Read()
+		return Term.Read()
 	}
 	return strings.Join(args, " ")
 }
 
+// LinesOrArgs returns the args unchanged unless there are none, in
+// which case all of standard input is read and returned as lines
+// (trimmed of white space, including any \r, with empty lines
+// skipped). Also see EachLineOrArg and ArgsOrIn.
+func LinesOrArgs(args []string) []string {
+	if len(args) > 0 {
+		return args
+	}
+	lines := []string{}
+	EachLineOrArg(nil, func(l string) error {
+		lines = append(lines, l)
+		return nil
+	})
+	return lines
+}
+
+// EachLineOrArg calls fn for each of the args or, if there are none,
+// each line of standard input (see LinesOrArgs) as it is read, without
+// buffering it all, stopping at and returning the first error.
+func EachLineOrArg(args []string, fn func(string) error) error {
+	if len(args) > 0 {
+		for _, a := range args {
+			if err := fn(a); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	s := bufio.NewScanner(os.Stdin)
+	s.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for s.Scan() {
+		l := strings.TrimSpace(s.Text())
+		if l == "" {
+			continue
+		}
+		if err := fn(l); err != nil {
+			return err
+		}
+	}
+	return s.Err()
+}
+
 // Aliases allows Bonzai tree developers to create aliases (similar to
 // shell aliases) that are directly translated into arguments to the
 // Bonzai tree executable by overriding the os.Args in a controlled way.
-// The value of an alias is always a slice of strings that will replace
-// the os.Args[2:]. A slice is used (instead of a string parsed with
+// The value of an alias is always a slice of strings that replaces the
+// alias itself wherever a Command name would be accepted: as the first
+// argument or following any Command with Commands of its own (see
+// ExpandAlias). A slice is used (instead of a string parsed with
 // strings.Fields) to ensure that hard-coded arguments containing
 // whitespace are properly handled.
 var Aliases = make(map[string][]string)
@@ -267,13 +524,34 @@ var Aliases = make(map[string][]string)
 // AllowPanic disables TrapPanic stopping it from cleaning panic errors.
 var AllowPanic = false
 
+// DebugPanic causes TrapPanic to also log the stack trace of the panic.
+// Setting either the DEBUG or BONZAI_DEBUG environment variable (to
+// anything) has the same effect.
+var DebugPanic bool
+
+// PanicHandler, if set, is called by TrapPanic with the recovered value
+// and stack trace of the panic before exiting so that applications can
+// write crash reports (to a file, telemetry, etc.).
+var PanicHandler func(recovered interface{}, stack []byte)
+
 // TrapPanic recovers from any panic and more gracefully displays the
-// panic by logging it before exiting with a return value of 1.
+// panic by logging it (a single line unless DebugPanic) before exiting
+// with ExitPanic (after calling PanicHandler and any functions
+// registered with AtExit) unless DoNotExit has been set.
 var TrapPanic = func() {
 	if !AllowPanic {
 		if r := recover(); r != nil {
-			log.Println(r)
-			os.Exit(1)
+			stack := debug.Stack()
+			logAt(LevelError, "", fmt.Sprint(r))
+			if DebugPanic || os.Getenv("DEBUG") != "" ||
+				os.Getenv("BONZAI_DEBUG") != "" {
+				logAt(LevelError, "", string(stack))
+			}
+			if PanicHandler != nil {
+				PanicHandler(r, stack)
+			}
+			exitErr = fmt.Errorf("%v", r)
+			exit(ExitPanic)
 		}
 	}
 }
@@ -4,8 +4,12 @@
 package Z_test
 
 import (
+	"errors"
 	"fmt"
+	"log"
 	"os"
+	"os/exec"
+	"testing"
 
 	Z "github.com/rwxrob/bonzai/z"
 )
@@ -18,6 +22,40 @@ func ExampleArgsFrom() {
 	// ["greet" "hi" "french" ""]
 }
 
+func ExampleArgsFrom_quoted() {
+	fmt.Printf("%q\n", Z.ArgsFrom(`add "my file.txt" 'it''s'`))
+	fmt.Printf("%q\n", Z.ArgsFrom(`add "say \"hi\"" 'no \escape' `))
+	fmt.Printf("%q\n", Z.ArgsFrom(`add my\ file.txt ""`))
+	fmt.Printf("%q\n", Z.ArgsFrom(`add "unterminated and more `))
+	fmt.Printf("%q\n", Z.ArgsFrom(`add escaped\ `))
+	// Output:
+	// ["add" "my file.txt" "its"]
+	// ["add" "say \"hi\"" "no \\escape" ""]
+	// ["add" "my file.txt" ""]
+	// ["add" "unterminated and more "]
+	// ["add" "escaped "]
+}
+
+func TestArgsFrom(t *testing.T) {
+	for _, tc := range []struct {
+		line string
+		want []string
+	}{
+		{`a b`, []string{"a", "b"}},
+		{`a b `, []string{"a", "b", ""}},
+		{`a\ b`, []string{"a b"}},
+		{`'a\b'`, []string{`a\b`}},
+		{`"C:\path\to"`, []string{`C:\path\to`}},
+		{"\"\\\\ \\\" \\$ \\`\"", []string{"\\ \" $ `"}},
+		{`"a\nb" c`, []string{`a\nb`, "c"}},
+	} {
+		got := Z.ArgsFrom(tc.line)
+		if fmt.Sprintf("%q", got) != fmt.Sprintf("%q", tc.want) {
+			t.Errorf("ArgsFrom(%q) = %q, want %q", tc.line, got, tc.want)
+		}
+	}
+}
+
 func ExampleArgsOrIn_read_Nil() {
 
 	orig := os.Stdin
@@ -50,6 +88,38 @@ func ExampleArgsOrIn_args_Joined() {
 	// some thing
 }
 
+func ExampleLinesOrArgs() {
+	orig := os.Stdin
+	defer func() { os.Stdin = orig }()
+	os.Stdin, _ = os.Open(`testdata/lines`)
+
+	fmt.Printf("%q\n", Z.LinesOrArgs([]string{"some", "thing"}))
+	fmt.Printf("%q\n", Z.LinesOrArgs(nil))
+
+	// Output:
+	// ["some" "thing"]
+	// ["one" "two" "three"]
+}
+
+func ExampleEachLineOrArg() {
+	orig := os.Stdin
+	defer func() { os.Stdin = orig }()
+	os.Stdin, _ = os.Open(`testdata/lines`)
+
+	err := Z.EachLineOrArg(nil, func(l string) error {
+		if l == "two" {
+			return errors.New("stopped at two")
+		}
+		fmt.Println(l)
+		return nil
+	})
+	fmt.Println(err)
+
+	// Output:
+	// one
+	// stopped at two
+}
+
 func ExampleEsc() {
 	fmt.Println(Z.Esc("|&;()<>![]"))
 	fmt.Printf("%q", Z.Esc(" \n\r"))
@@ -65,6 +135,41 @@ func ExampleEscAll() {
 	// [so\!me \<here\> other\&]
 }
 
+func ExampleEscCompletion() {
+	for _, word := range []string{
+		`my file.txt`, `it's "quoted"`, "$HOME and `cmd`", `a\b*?{c}`,
+	} {
+		esc := Z.EscCompletion(word)
+		back := Z.ArgsFrom(`cmd ` + esc)[1]
+		fmt.Println(esc, back == word)
+	}
+	// Output:
+	// my\ file.txt true
+	// it\'s\ \"quoted\" true
+	// \$HOME\ and\ \`cmd\` true
+	// a\\b\*\?\{c\} true
+}
+
+func TestEscCompletion_bash(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not found")
+	}
+	for _, word := range []string{
+		`my file.txt`, `it's "quoted"`, "$HOME and `cmd`", `a\b*?{c}`,
+		`semi;colon|pipe& (paren) <angle> !bang [x] ~tilde #hash`,
+	} {
+		out, err := exec.Command(bash, "-c",
+			`printf %s `+Z.EscCompletion(word)).Output()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) != word {
+			t.Errorf("bash reconstructed %q from %q", out, word)
+		}
+	}
+}
+
 func ExampleInferredUsage_optional_Param() {
 	x := &Z.Cmd{
 		Params: []string{"p1", "p2"},
@@ -72,7 +177,7 @@ func ExampleInferredUsage_optional_Param() {
 	}
 	fmt.Println(Z.InferredUsage(x))
 	// Output:
-	// (p1|p2)?
+	// [p1|p2]...
 }
 
 func ExampleInferredUsage_min_One_Param() {
@@ -83,7 +188,7 @@ func ExampleInferredUsage_min_One_Param() {
 	}
 	fmt.Println(Z.InferredUsage(x))
 	// Output:
-	// (p1|p2)+
+	// (p1|p2)...
 }
 
 func ExampleInferredUsage_min_3_Param() {
@@ -120,7 +225,7 @@ func ExampleInferredUsage_commands_and_Params() {
 	}
 	fmt.Println(Z.InferredUsage(x))
 	// Output:
-	// ((p1|p2)?|((f|foo)|bar))
+	// ([p1|p2]...|(f|foo)|bar)
 }
 
 func ExampleInferredUsage_error_No_Call_or_Command() {
@@ -154,3 +259,171 @@ func ExampleUsageGroup() {
 	// ""
 	// one
 }
+
+func ExampleAtExit() {
+	defer logErrs()()
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	defer Z.ClearAtExit()
+	defer log.SetOutput(os.Stderr)
+	defer log.SetFlags(log.Flags())
+	log.SetOutput(os.Stdout)
+	log.SetFlags(0)
+
+	Z.AtExit(func() { fmt.Println("first registered") })
+	Z.AtExit(func() { panic("oops") })
+	Z.AtExit(func() { fmt.Println("last registered") })
+	Z.ExitError(errors.New("failed"))
+
+	Z.Exit() // already called
+
+	Z.AtExit(func() { fmt.Println("not called") })
+	Z.ClearAtExit()
+	Z.Exit()
+
+	// Output:
+	// failed
+	// last registered
+	// warning: exit handler panicked: oops
+	// first registered
+}
+
+func ExampleTrapPanic() {
+	rec := new(Z.RecordingExiter)
+	defer Z.SetExiter(rec)()
+	defer log.SetOutput(os.Stderr)
+	defer log.SetFlags(log.Flags())
+	log.SetOutput(os.Stdout)
+	log.SetFlags(0)
+	defer func() { Z.PanicHandler = nil }()
+
+	Z.PanicHandler = func(r interface{}, stack []byte) {
+		fmt.Println("handled:", r, len(stack) > 0)
+	}
+	func() {
+		defer Z.TrapPanic()
+		panic("oops")
+	}()
+	fmt.Println(rec.Last())
+
+	// Output:
+	// oops
+	// handled: oops true
+	// 70
+}
+
+func ExampleRun_multicall() {
+	defer logErrs()()
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	defer log.SetOutput(os.Stderr)
+	defer log.SetFlags(log.Flags())
+	log.SetOutput(os.Stdout)
+	log.SetFlags(0)
+	defer func(n string) { Z.ExeName = n }(Z.ExeName)
+	defer func(c map[string][]any) { Z.Commands = c }(Z.Commands)
+	orig := os.Args
+	defer func() { os.Args = orig }()
+
+	x := &Z.Cmd{
+		Name: `foo`,
+		Call: func(_ *Z.Cmd, args ...string) error {
+			fmt.Println(args)
+			return nil
+		},
+	}
+	Z.Commands = map[string][]any{
+		"foo":  {x},
+		"fset": {x, "set"},
+		"lazy": {func() *Z.Cmd { fmt.Println("created"); return x }, "l"},
+		"bad":  {"foo"},
+	}
+
+	Z.ExeName = "foo"
+	os.Args = []string{"foo", "a", "b"}
+	Z.Run()
+
+	Z.ExeName = "fset.test"
+	os.Args = []string{"fset", "a"}
+	Z.Run()
+
+	Z.ExeName = "lazy"
+	os.Args = []string{"lazy"}
+	Z.Run()
+
+	Z.ExeName = "bad"
+	Z.Run()
+
+	Z.ExeName = "a.out"
+	os.Args = []string{"a.out"}
+	Z.Run()
+
+	Z.Commands[""] = []any{x, "fallback"}
+	os.Args = []string{"a.out", "a"}
+	Z.Run()
+
+	// Output:
+	// [a b]
+	// [set a]
+	// created
+	// [l]
+	// first value must be *Cmd or func() *Cmd (not string)
+	// unmapped multicall command: a.out (not one of: bad, foo, fset, lazy)
+	// [fallback a]
+}
+
+// fooTree returns a new command tree shared by many of the tests, each
+// adding to it whatever else they need:
+//
+//     foo
+//       d|db
+//         migrate [up down]
+//       secret (hidden)
+func fooTree() *Z.Cmd {
+	call := func(*Z.Cmd, ...string) error { return nil }
+	return &Z.Cmd{
+		Name:   `foo`,
+		Hidden: []string{`secret`},
+		Commands: []*Z.Cmd{
+			&Z.Cmd{
+				Name:    `db`,
+				Aliases: []string{`d`},
+				Summary: `database | things`,
+				Commands: []*Z.Cmd{
+					&Z.Cmd{
+						Name:    `migrate`,
+						Summary: `migrate the schema`,
+						Params:  []string{`up`, `down`},
+						Call:    call,
+					},
+				},
+			},
+			&Z.Cmd{Name: `secret`, Summary: `shh`, Call: call},
+		},
+	}
+}
+
+// logErrs sets LogErrors so that the error of any failed Run is logged
+// (and captured with the log output) and returns a function restoring it.
+func logErrs() func() {
+	orig := Z.LogErrors
+	Z.LogErrors = true
+	return func() { Z.LogErrors = orig }
+}
+
+func ExamplePrintErr() {
+	defer func(n string) { Z.ExeName = n }(Z.ExeName)
+	Z.ExeName = `foo`
+	defer func(e *os.File) { os.Stderr = e }(os.Stderr)
+	os.Stderr = os.Stdout
+	Z.PrintErr(errors.New(`something failed`))
+	// Output:
+	// foo: something failed
+}
+
+func ExampleErrPrinter() {
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	defer func(p func(error)) { Z.ErrPrinter = p }(Z.ErrPrinter)
+	Z.ErrPrinter = func(err error) { fmt.Println(`error:`, err) }
+	Z.ExitError(`nobody to %v`, `greet`)
+	// Output:
+	// error: nobody to greet
+}
@@ -4,62 +4,135 @@
 package Z
 
 import (
+	"errors"
 	"fmt"
-	"log"
 	"os"
-	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/rwxrob/bonzai"
 	"github.com/rwxrob/bonzai/comp"
-	"github.com/rwxrob/fn/each"
-	"github.com/rwxrob/fn/maps"
-	"github.com/rwxrob/fn/redu"
-	"github.com/rwxrob/structs/qstack"
 )
 
 type Cmd struct {
-	Name        string    `json:"name,omitempty"`
-	Aliases     []string  `json:"aliases,omitempty"`
-	Summary     string    `json:"summary,omitempty"`
-	Usage       string    `json:"usage,omitempty"`
-	Version     string    `json:"version,omitempty"`
-	Copyright   string    `json:"copyright,omitempty"`
-	License     string    `json:"license,omitempty"`
-	Description string    `json:"description,omitempty"`
-	Site        string    `json:"site,omitempty"`
-	Source      string    `json:"source,omitempty"`
-	Issues      string    `json:"issues,omitempty"`
-	Commands    []*Cmd    `json:"commands,omitempty"`
-	Params      []string  `json:"params,omitempty"`
-	Hidden      []string  `json:"hidden,omitempty"`
-	Other       []Section `json:"other,omitempty"`
-
-	Completer bonzai.Completer `json:"-"`
-	UsageFunc bonzai.UsageFunc `json:"-"`
-
-	Caller  *Cmd   `json:"-"`
-	Call    Method `json:"-"`
-	MinArgs int    `json:"-"` // minimum number of args required (including parms)
-	MinParm int    `json:"-"` // minimum number of params required
-	MaxParm int    `json:"-"` // maximum number of params required
-	ReqConf bool   `json:"-"` // requires Z.Conf be assigned
-
-	_aliases  map[string]*Cmd   // see cacheAliases called from Run
-	_sections map[string]string // see cacheSections called from Run
+	Name           string              `json:"name,omitempty"`
+	Aliases        []string            `json:"aliases,omitempty"`
+	Summary        string              `json:"summary,omitempty"`
+	Group          string              `json:"group,omitempty"` // heading when listed by Caller
+	Usage          string              `json:"usage,omitempty"`
+	Version        string              `json:"version,omitempty"`
+	Copyright      string              `json:"copyright,omitempty"`
+	License        string              `json:"license,omitempty"`
+	Description    string              `json:"description,omitempty"`
+	Site           string              `json:"site,omitempty"`
+	Source         string              `json:"source,omitempty"`
+	Issues         string              `json:"issues,omitempty"`
+	Commands       []*Cmd              `json:"commands,omitempty"`
+	CommandsFunc   func(x *Cmd) []*Cmd `json:"-"`                 // generated Commands (see AllCommands)
+	Default        string              `json:"default,omitempty"` // see DefaultCmd
+	NoBuiltinFlags bool                `json:"-"`                 // see BuiltinFlags
+	Params         []string            `json:"params,omitempty"`
+	Flags          []Flag              `json:"flags,omitempty"`      // opt-in (see Flag)
+	EnvVars        []EnvVar            `json:"envvars,omitempty"`    // see Env
+	ConfKeys       []ConfKey           `json:"confkeys,omitempty"`   // see ConfKey
+	Repeatable     []string            `json:"repeatable,omitempty"` // params allowed more than once
+	DepParams      map[string]string   `json:"depparams,omitempty"`  // deprecated params and messages
+	Excl           [][]string          `json:"excl,omitempty"`       // mutually exclusive params
+	Needs          map[string][]string `json:"needs,omitempty"`      // params requiring other params
+	Hidden         []string            `json:"hidden,omitempty"`
+	Hide           bool                `json:"hide,omitempty"`       // hidden from any Caller
+	Deprecated     string              `json:"deprecated,omitempty"` // message (ex: use 'sync' instead)
+	Other          []Section           `json:"other,omitempty"`
+	Examples       []Example           `json:"examples,omitempty"`
+	Tags           map[string]string   `json:"tags,omitempty"` // see GetTag
+
+	Completer        bonzai.Completer        `json:"-"`
+	ContextCompleter bonzai.ContextCompleter `json:"-"` // preferred to Completer
+	Describer        comp.Describer          `json:"-"` // completes with descriptions
+	CompTimeout      time.Duration           `json:"-"` // overrides Z.CompTimeout (<0 none)
+	CompCache        bool                    `json:"-"` // reuse last completion on timeout
+	UsageFunc        bonzai.UsageFunc        `json:"-"`
+
+	Caller  *Cmd     `json:"-"`
+	Call    Method   `json:"-"`
+	MinArgs int      `json:"-"` // minimum number of args required (including parms)
+	MinParm int      `json:"-"` // minimum number of params required
+	MaxParm int      `json:"-"` // maximum number of params required
+	ReqConf bool     `json:"-"` // requires Z.Conf be assigned
+	ReqVars bool     `json:"-"` // requires Z.Vars be assigned
+	Require []string `json:"-"` // external executables required (see InPath)
+
+	StrictParams bool `json:"-"` // args that are not Params are usage errors
+
+	WorkDirMarker string `json:"-"` // ex: go.mod (see WorkDir)
+	WorkDirChdir  bool   `json:"-"` // change to WorkDir before Call
+
+	SupportsDryRun bool `json:"-"` // recognize DryRunFlag (see DryRun)
+
+	Formats    []string `json:"-"` // ex: text, json (see Format, Print)
+	FormatAuto bool     `json:"-"` // json if not interactive (see Format)
+
+	Lock     bool          `json:"-"` // never run concurrently (see LockDir)
+	LockName string        `json:"-"` // shared lock (instead of PathString)
+	LockWait time.Duration `json:"-"` // for lock before failing (<0 forever)
+
+	Timeout time.Duration `json:"-"` // for Call, smallest of Callers (see Run)
+	Retries int           `json:"-"` // Call again on error (idempotent only)
+
+	ExpandArgFiles bool `json:"-"` // expand @file args (see ArgFiles)
+
+	IgnoreCase  bool `json:"-"` // resolve Commands ignoring case
+	PrefixMatch bool `json:"-"` // resolve unambiguous Command prefixes
+
+	_index    *cmdIndex         // see index called from Resolve (treemu)
+	_sections map[string]string // see cacheSections called from Run (treemu)
+	_call     bool              // see UnmarshalJSON and Callable
+	_flags    map[string]string // see extractFlags called from Run
+	_run      *runFlags         // see withFlags called from prepare
+	_gen      []*Cmd            // see AllCommands
+	_genrun   int               // see AllCommands
+	_all      []*Cmd            // see AllCommands
+	_cmdsi    *cmdsCache        // see GetCommands (treemu)
+	_otheri   *otherCache       // see GetOther (treemu)
+	_default  bool              // see WasDefaulted
 }
 
 // Section contains the Other sections of a command. Composition
 // notation (without Title and Body) is not only supported but
 // encouraged for clarity when reading the source for documentation.
 type Section struct {
-	Title string
-	Body  string
+	Title string `json:"title"`
+	Body  string `json:"body"`
 }
 
 func (s Section) GetTitle() string { return s.Title }
 func (s Section) GetBody() string  { return s.Body }
 
+// Example contains one of the Examples of a command. Cmd holds only the
+// arguments that follow the full command path (which is prepended when
+// rendered, see Invocation) so that examples remain correct no matter
+// what tree the command is composed into. Composition notation is
+// encouraged here as well.
+type Example struct {
+	Cmd  string `json:"cmd"`
+	Note string `json:"note,omitempty"`
+}
+
+func (e Example) GetCmd() string  { return e.Cmd }
+func (e Example) GetNote() string { return e.Note }
+
+// ExampleText, if set, is used to prefix the example hint added to
+// UsageErrors instead of the "example" message of the current Lang (see
+// Msg).
+var ExampleText string
+
+// Invocation returns the full command line (see DocData) to invoke the
+// Cmd with the given arguments (ex: "foo db migrate up").
+func (x *Cmd) Invocation(args string) string {
+	return strings.TrimSpace(x.DocData().Path + " " + args)
+}
+
 // Names returns the Name and any Aliases grouped such that the Name is
 // always last.
 func (x *Cmd) Names() []string {
@@ -75,14 +148,14 @@ func (x *Cmd) UsageNames() string { return UsageGroup(x.Names(), 1, 1) }
 
 // UsageParams returns the Params in UsageGroup notation.
 func (x *Cmd) UsageParams() string {
-	return UsageGroup(x.Params, x.MinParm, x.MaxParm)
+	return UsageGroup(usageParams(x.Params), x.MinParm, x.MaxParm)
 }
 
 // UsageCmdNames returns the Names for each of its Commands joined, if
 // more than one, with usage regex notation.
 func (x *Cmd) UsageCmdNames() string {
 	var names []string
-	for _, n := range x.Commands {
+	for _, n := range x.AllCommands() {
 		names = append(names, n.UsageNames())
 	}
 	return UsageGroup(names, 1, 1)
@@ -93,7 +166,7 @@ func (x *Cmd) UsageCmdNames() string {
 // a "{ERROR}".
 func (x *Cmd) Title() string {
 	if x.Name == "" {
-		return "{ERROR: Name is empty}"
+		return Msg(`name-empty`)
 	}
 	switch {
 	case len(x.Summary) > 0:
@@ -126,134 +199,407 @@ func (x *Cmd) Legal() string {
 	}
 }
 
-// OtherTitles returns just the ordered titles from Other.
-func (x *Cmd) OtherTitles() []string { return maps.Keys(x._sections) }
+// OtherTitles returns just the titles from Other in the order declared
+// (including any duplicates, see Validate).
+func (x *Cmd) OtherTitles() []string {
+	titles := make([]string, 0, len(x.Other))
+	for _, s := range x.Other {
+		titles = append(titles, s.Title)
+	}
+	return titles
+}
 
-func (x *Cmd) cacheAliases() {
-	x._aliases = map[string]*Cmd{}
-	if x.Commands == nil {
-		return
+// OtherSection returns the Body of the first of Other with the title
+// (looked up in the index cached by Run, if any) and whether found.
+func (x *Cmd) OtherSection(title string) (string, bool) {
+	treemu.Lock()
+	sections := x._sections
+	treemu.Unlock()
+	if sections != nil {
+		body, has := sections[title]
+		return body, has
 	}
-	for _, c := range x.Commands {
-		if c.Aliases == nil {
+	for _, s := range x.Other {
+		if s.Title == title {
+			return s.Body, true
+		}
+	}
+	return "", false
+}
+
+func (x *Cmd) injectBuiltins() {
+BUILTINS:
+	for _, b := range Builtins {
+		if b == nil {
 			continue
 		}
+		for _, c := range x.Commands {
+			if c.Name == b.Name {
+				continue BUILTINS
+			}
+		}
+		x.Commands = append(x.Commands, b)
+	}
+}
+
+// treemu guards everything cached on (or bound to) the Cmd values of a
+// tree at run time (_index, _sections, _gen, _all, _cmdsi, _otheri, and
+// Caller) so that the same tree can be used concurrently (see RunE).
+// The caches are always replaced, never changed, so only the assignment
+// and the lookup of the field itself need the lock.
+var treemu sync.Mutex
+
+// ResolveIndexOff disables the index of the names and aliases of
+// Commands used by Resolve (which then scans the Commands instead). By
+// default, the index of each Cmd is built the first time it is needed
+// and again for every Run (since CommandsFunc may generate different
+// Commands) or whenever the number of Commands changes. This makes
+// resolving names under branches with hundreds of Commands much faster.
+var ResolveIndexOff bool
+
+type cmdIndex struct {
+	names map[string]*Cmd // names and aliases
+	run   int             // see runs
+	count int             // of AllCommands when built
+}
+
+// index returns the names and aliases of the cmds (from AllCommands)
+// mapped to their Cmd (building it again if it is stale) or nil if
+// ResolveIndexOff. Names take precedence over aliases and the first
+// Cmd with the name (or alias) takes precedence over any others.
+func (x *Cmd) index(cmds []*Cmd) map[string]*Cmd {
+	if ResolveIndexOff {
+		return nil
+	}
+	treemu.Lock()
+	idx, run := x._index, runs
+	treemu.Unlock()
+	if idx != nil && idx.run == run && idx.count == len(cmds) {
+		return idx.names
+	}
+	names := make(map[string]*Cmd, len(cmds))
+	for _, c := range cmds {
 		for _, a := range c.Aliases {
-			x._aliases[a] = c
+			if _, has := names[a]; !has {
+				names[a] = c
+			}
 		}
 	}
+	for i := len(cmds) - 1; i >= 0; i-- {
+		names[cmds[i].Name] = cmds[i]
+	}
+	treemu.Lock()
+	x._index = &cmdIndex{names, run, len(cmds)}
+	treemu.Unlock()
+	return names
 }
 
 func (x *Cmd) cacheSections() {
-	x._sections = map[string]string{}
-	if len(x.Other) == 0 {
-		return
-	}
+	sections := map[string]string{}
 	for _, s := range x.Other {
-		x._sections[s.Title] = s.Body
+		if _, has := sections[s.Title]; !has {
+			sections[s.Title] = s.Body
+		}
+	}
+	treemu.Lock()
+	x._sections = sections
+	treemu.Unlock()
+}
+
+// bind returns the Cmd with its Caller set to the caller. If the Cmd is
+// already bound to a different Caller (because it is shared between
+// branches or trees) a shallow copy is bound and returned instead so
+// that concurrent invocations cannot change the Path of each other.
+func bind(c, caller *Cmd) *Cmd {
+	treemu.Lock()
+	defer treemu.Unlock()
+	switch c.Caller {
+	case caller:
+		return c
+	case nil:
+		c.Caller = caller
+		return c
 	}
+	cp := *c
+	cp.Caller = caller
+	return &cp
+}
+
+// bindDefault returns a shallow copy of the default Command c (see
+// DefaultCmd) bound to the caller and marked as implicitly invoked (see
+// WasDefaulted). A copy is always used so that explicit invocations of
+// the same Command (and concurrent ones) are never marked.
+func bindDefault(c, caller *Cmd) *Cmd {
+	treemu.Lock()
+	defer treemu.Unlock()
+	cp := *c
+	cp.Caller = caller
+	cp._default = true
+	return &cp
+}
+
+// withFlags returns a shallow copy of the Cmd (bound to the same
+// Caller) carrying the flags passed to a single Run, RunE, or Shell
+// line (see prepare) so that concurrent runs never share them.
+func withFlags(c *Cmd, f runFlags) *Cmd {
+	treemu.Lock()
+	defer treemu.Unlock()
+	cp := *c
+	cp._run = &f
+	return &cp
 }
 
+// WasDefaulted returns true if the Cmd was called by Run (or RunE or
+// Shell) only because it is the DefaultCmd of its Caller, which has no
+// Call of its own, rather than because its name was given explicitly.
+// The Caller is always set in that case. UsageError uses the Name of
+// the Caller instead so that users are not shown a name they never
+// typed.
+func (x *Cmd) WasDefaulted() bool { return x != nil && x._default }
+
 // Run is for running a command within a specific runtime (shell) and
 // performs completion if completion context is detected.  Otherwise, it
 // executes the leaf Cmd returned from Seek calling its Method, and then
 // Exits. Normally, Run is called from within main() to convert the Cmd
 // into an actual executable program and normally it exits the program.
-// Exiting can be controlled, however, with ExitOn/ExitOff when testing
-// or for other purposes requiring multiple Run calls. Using Call
-// instead will also just call the Cmd's Call Method without exiting.
-// Note: Only bash runtime ("COMP_LINE") is currently supported, but
-// others such a zsh and shell-less REPLs are planned.
+// Exiting can be controlled, however, with SetExiter (or
+// ExitOn/ExitOff) when testing or for other purposes requiring multiple
+// Run calls. Using Call instead will also just call the Cmd's Call
+// Method without exiting. Completion context is detected from COMP_LINE
+// (set by bash itself or by the adapters from CompletionScript for
+// other shells, see CompShell). For an interactive prompt running
+// commands from the tree one line at a time see REPL and Shell. If the
+// Cmd (or any of its Callers) has a Timeout its Call is abandoned once
+// it passes and Run prints a TimeoutError and exits with ExitTimeout
+// (after calling any functions registered with AtExit, see also
+// TimeoutParam). If the Cmd has Retries its Call is attempted again
+// after any error (see Retry).
 func (x *Cmd) Run() {
 	defer TrapPanic()
+	treemu.Lock()
+	runs++
+	treemu.Unlock()
+
+	x.injectBuiltins()
+
+	if StrictTree {
+		var msgs []string
+		for _, e := range x.Validate() {
+			var w *ValidationWarning
+			if errors.As(e, &w) {
+				logAt(LevelWarn, w.Path, w.Msg)
+				continue
+			}
+			msgs = append(msgs, e.Error())
+		}
+		if len(msgs) > 0 {
+			ExitError(errors.New(strings.Join(msgs, "\n")))
+			return
+		}
+	}
 
-	x.cacheAliases()
 	x.cacheSections()
 
-	// resolve Z.Aliases (if completion didn't replace them)
+	// resolve Z.Aliases and ConfAliases (unless completion did already)
 	if len(os.Args) > 1 {
-		args := []string{os.Args[0]}
-		alias := Aliases[os.Args[1]]
-		if alias != nil {
-			args = append(args, alias...)
-			args = append(args, os.Args[2:]...)
-			os.Args = args
-		}
-	}
-
-	// bash completion context
-	line := os.Getenv("COMP_LINE")
-	if line != "" {
-		var list []string
-		lineargs := ArgsFrom(line)
-		if len(lineargs) == 2 {
-			list = append(list, maps.KeysWithPrefix(Aliases, lineargs[1])...)
-		}
-		cmd, args := x.Seek(lineargs[1:])
-		if cmd.Completer == nil {
-			list = append(list, comp.Standard(cmd, args...)...)
-			if len(list) == 1 && len(lineargs) == 2 {
-				if v, has := Aliases[list[0]]; has {
-					fmt.Println(strings.Join(EscAll(v), " "))
-					Exit()
-				}
+		words, err := x.expandAliases(os.Args[1:])
+		if err != nil {
+			if os.Getenv("COMP_LINE") == "" {
+				ExitError(err)
+				return
 			}
-			each.Println(list)
-			Exit()
+		} else {
+			os.Args = append([]string{os.Args[0]}, words...)
 		}
-		each.Println(cmd.Completer(cmd, args...))
+	}
+
+	// completion context (see CompletionScript)
+	if os.Getenv("COMP_LINE") != "" {
+		x.complete(CompLine())
 		Exit()
+		return
 	}
 
+	// extract any declared Flags
+	in, flags, err := x.extractFlags(os.Args[1:])
+	if err != nil {
+		ExitError(err)
+		return
+	}
+	setVerbosity(flags)
+
+	cmd, args, err := x.prepare(in, flags)
+	if err != nil {
+		ExitError(err)
+		return
+	}
+	setLast(cmd)
+	if traceOnly() {
+		Exit()
+		return
+	}
+
+	// delegate
+	if HandleSignals {
+		stop := handleSignals()
+		err = cmd.call(args)
+		stop()
+		if code := signaled(); code != 0 {
+			exit(code)
+			return
+		}
+	} else {
+		err = cmd.call(args)
+	}
+	if _, is := err.(*TimeoutError); is {
+		exitErr = err
+		printErr(err)
+		exit(ExitTimeout)
+		return
+	}
+	if err != nil {
+		ExitError(err)
+		return
+	}
+	Exit()
+}
+
+// RunE calls the leaf Cmd sought from the args (without the Name of the
+// Cmd itself) after the same checks as Run (see Seek, DefaultCmd,
+// MinArgs, and Require) while holding its lock (see Lock) and returns
+// any error instead of printing it and exiting (including
+// a TimeoutError, see Timeout). Completion, Aliases, Flags (which are
+// passed on as arguments), Builtins, and signals are not handled at
+// all. Unlike Run, RunE may be called concurrently on the same tree
+// (from the handlers of a server, for example) provided the Call
+// Methods themselves are safe to do so.
+func (x *Cmd) RunE(args ...string) error {
+	cmd, args, err := x.prepare(args, runFlags{})
+	if err != nil {
+		return err
+	}
+	return cmd.call(args)
+}
+
+// prepare seeks the leaf Cmd and its arguments from the args (with
+// Flags already extracted) and returns an error for anything that must
+// prevent calling it (ambiguity, missing Call, invalid arguments, and
+// unmet requirements). The leaf returned is always a copy carrying the
+// flags (see withFlags). It is shared by Run, RunE, and Shell.
+func (x *Cmd) prepare(in []string, flags runFlags) (*Cmd, []string, error) {
+
 	// seek should never fail to return something, but ...
-	cmd, args := x.Seek(os.Args[1:])
+	cmd, args := x.seek(in, tracing())
 	if cmd == nil {
-		ExitError(x.UsageError())
+		return nil, nil, x.UsageError()
+	}
+
+	if len(args) > 0 {
+		if names := cmd.Ambiguous(args[0]); names != nil {
+			return nil, nil, errors.New(Msg(`ambiguous-command`,
+				args[0], strings.Join(names, ", ")))
+		}
 	}
 
-	// default to first Command if no Call defined
+	// default command (see DefaultCmd) if no Call defined
 	if cmd.Call == nil {
-		if len(cmd.Commands) > 0 {
-			fcmd := cmd.Commands[0]
-			if fcmd.Call == nil {
-				ExitError(fmt.Errorf("default commands require Call function"))
-			}
-			fcmd.Caller = cmd
-			cmd = fcmd
-		} else {
-			ExitError(x.Unimplemented())
+		fcmd := cmd.DefaultCmd()
+		if fcmd == nil {
+			return nil, nil, x.Unimplemented()
+		}
+		if fcmd.Call == nil {
+			return nil, nil, errors.New(Msg(`default-needs-call`))
 		}
+		if tracing() {
+			tracef("default %v -> %v", cmd.Name, fcmd.Name)
+		}
+		cmd = bindDefault(fcmd, cmd)
+	}
+
+	if cmd.ExpandArgFiles {
+		var err error
+		if args, err = cmd.ArgFiles(args); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := cmd.checkKV(args); err != nil {
+		return nil, nil, err
+	}
+
+	if err := cmd.checkParams(args); err != nil {
+		return nil, nil, err
+	}
+
+	if err := cmd.checkDeprecated(args); err != nil {
+		return nil, nil, err
+	}
+
+	if err := cmd.checkDryRun(flags); err != nil {
+		return nil, nil, err
+	}
+
+	if err := cmd.checkFormat(flags); err != nil {
+		return nil, nil, err
 	}
 
 	if len(args) < cmd.MinArgs {
-		ExitError(cmd.UsageError())
+		return nil, nil, cmd.UsageError()
 	}
 
-	if x.ReqConf && Conf == nil {
-		ExitError(cmd.ReqConfError())
+	for _, c := range append(cmd.Callers(), cmd) {
+		if err := c.checkReqs(); err != nil {
+			return nil, nil, err
+		}
 	}
 
-	// delegate
-	if cmd.Caller == nil {
-		cmd.Caller = x
+	if tracing() {
+		tracef("leaf %v", cmd.logPath())
+		tracef("args %q", args)
 	}
-	if err := cmd.Call(cmd, args...); err != nil {
-		ExitError(err)
+
+	if cmd != x && cmd.Caller == nil {
+		cmd = bind(cmd, x)
 	}
-	Exit()
+	flags.args = args
+	return withFlags(cmd, flags), args, nil
 }
 
 // UsageError returns an error with a single-line usage string. The word
-// "usage" can be changed by assigning Z.UsageText to something else.
-// The commands own UsageFunc will be used if defined. If undefined, the
-// Z.UsageFunc will be used instead (which can also be assigned
-// to something else if needed).
+// "usage" depends on the current Lang (see Msg) unless Z.UsageText is
+// assigned. The Usage string is used if set. Otherwise, the commands
+// own UsageFunc will be used if defined. If undefined, the Z.UsageFunc
+// will be used instead (which can also be assigned to something else if
+// needed). The word "usage" and the name are styled for standard error
+// (see Style and Styled). If the Cmd has any Examples the first is
+// added on a second line as a hint. If the Cmd WasDefaulted the Name of
+// its Caller is used instead since that is what was actually typed.
 func (x *Cmd) UsageError() error {
-	usage := x.UsageFunc
-	if usage == nil {
-		usage = UsageFunc
+	name := x.Name
+	if x.WasDefaulted() {
+		name = x.Caller.Name
+	}
+	pre := Styled(os.Stderr, Style.Error, usageText()) + ": " +
+		Styled(os.Stderr, Style.Name, name) + " "
+	msg := pre + Hanging(usageOf(x), Columns, Width(pre))
+	if len(x.Examples) > 0 {
+		msg += "\n" + exampleText() + ": " + x.Invocation(x.Examples[0].Cmd)
 	}
-	return fmt.Errorf("%v: %v %v", UsageText, x.Name, usage(x))
+	return errors.New(msg)
+}
+
+func usageText() string {
+	if UsageText != "" {
+		return UsageText
+	}
+	return Msg(`usage`)
+}
+
+func exampleText() string {
+	if ExampleText != "" {
+		return ExampleText
+	}
+	return Msg(`example`)
 }
 
 // ReqConfError returns stating that the given command requires that
@@ -261,21 +607,50 @@ func (x *Cmd) UsageError() error {
 // those composing commands that import a given command to help the
 // develop know about the dependency.
 func (x *Cmd) ReqConfError() error {
-	return fmt.Errorf(
-		"cmd %q requires a configurer (Z.Conf must be assigned)",
-		x.Name,
-	)
+	return errors.New(Msg(`requires-conf`, x.Name))
 }
 
-// Unimplemented returns an error with a single-line usage string.
+// ReqVarsError returns stating that the given command requires that
+// Z.Vars be set to something besides null (see ReqConfError).
+func (x *Cmd) ReqVarsError() error {
+	return errors.New(Msg(`requires-vars`, x.Name))
+}
+
+// checkReqs returns an error for the first requirement declared by the
+// Cmd itself that is not met (ReqConf, ConfKeys, ReqVars, EnvVars,
+// Require, WorkDirMarker). Run checks every Cmd from the root to the
+// leaf so that requirements of branches apply to all of their commands.
+func (x *Cmd) checkReqs() error {
+	if x.ReqConf && Conf == nil {
+		return x.ReqConfError()
+	}
+	if err := x.checkConf(); err != nil {
+		return err
+	}
+	if x.ReqVars && Vars == nil {
+		return x.ReqVarsError()
+	}
+	if err := x.checkEnv(); err != nil {
+		return err
+	}
+	if missing := MissingFromPath(x.Require...); len(missing) > 0 {
+		return errors.New(Msg(`requires-path`,
+			x.logPath(), strings.Join(missing, ", ")))
+	}
+	return x.checkWorkDir()
+}
+
+// Unimplemented returns an error stating that the Cmd has not yet been
+// implemented (in the current Lang, see Msg).
 func (x *Cmd) Unimplemented() error {
-	return fmt.Errorf("%q has not yet been implemented", x.Name)
+	return errors.New(Msg(`unimplemented`, x.Name))
 }
 
 // MissingConfig returns an error showing the expected configuration
-// entry that is missing from the given path.
+// entry that is missing from the given path. The error is
+// a *ConfigError and wraps ErrMissingConfig.
 func (x *Cmd) MissingConfig(path string) error {
-	return fmt.Errorf("missing config: %v", x.PathString()+"."+path)
+	return &ConfigError{Path: x.PathString() + "." + path}
 }
 
 // Add creates a new Cmd and sets the name and aliases and adds to
@@ -290,26 +665,105 @@ func (x *Cmd) Add(name string, aliases ...string) *Cmd {
 	return c
 }
 
-// Resolve looks up a given Command by name or name from Aliases.
+// DefaultCmd returns the Command that Run calls when the Cmd has no
+// Call of its own. This is the Command resolved from Default (see
+// Resolve) if set. Otherwise, for compatibility, it is the first of
+// Commands (see AllCommands), which StrictTree warns about since
+// reordering Commands would then silently change behavior. Returns
+// nil if there is no such Command.
+func (x *Cmd) DefaultCmd() *Cmd {
+	if x.Default != "" {
+		return x.Resolve(x.Default)
+	}
+	if cmds := x.AllCommands(); len(cmds) > 0 {
+		return cmds[0]
+	}
+	return nil
+}
+
+// Resolve looks up a given Command by name or name from Aliases. If
+// IgnoreCase is in effect (see GetIgnoreCase) names and aliases are
+// also compared case-insensitively. If PrefixMatch is in effect (see
+// GetPrefixMatch) a name that is the prefix of exactly one Command that
+// is not Hidden also resolves to it. Ambiguous prefixes resolve to nil
+// (see Ambiguous).
 func (x *Cmd) Resolve(name string) *Cmd {
-	if x.Commands == nil {
+	c, _ := x.resolve(name)
+	return c
+}
+
+// Ambiguous returns the names of every Command that could have been
+// meant by the name when it is an ambiguous prefix (see Resolve) and
+// nil otherwise.
+func (x *Cmd) Ambiguous(name string) []string {
+	_, names := x.resolve(name)
+	if len(names) < 2 {
 		return nil
 	}
-	for _, c := range x.Commands {
-		if name == c.Name {
-			return c
+	return names
+}
+
+func (x *Cmd) resolve(name string) (*Cmd, []string) {
+	cmds := x.AllCommands()
+	if len(cmds) == 0 {
+		return nil, nil
+	}
+	if idx := x.index(cmds); idx != nil {
+		if c, has := idx[name]; has {
+			return c, nil
+		}
+	} else {
+		for _, c := range cmds {
+			if name == c.Name {
+				return c, nil
+			}
+		}
+		for _, c := range cmds {
+			for _, a := range c.Aliases {
+				if name == a {
+					return c, nil
+				}
+			}
 		}
 	}
-	if c, has := x._aliases[name]; has {
-		return c
+	fold := x.GetIgnoreCase()
+	if fold {
+		for _, c := range cmds {
+			for _, n := range c.Names() {
+				if strings.EqualFold(name, n) {
+					return c, nil
+				}
+			}
+		}
 	}
-	return nil
+	if name == "" || !x.GetPrefixMatch() {
+		return nil, nil
+	}
+	var match *Cmd
+	var names []string
+	for _, c := range cmds {
+		if x.IsHidden(c.Name) {
+			continue
+		}
+		for _, n := range c.Names() {
+			if strings.HasPrefix(n, name) ||
+				(fold && strings.HasPrefix(strings.ToLower(n), strings.ToLower(name))) {
+				match = c
+				names = append(names, c.Name)
+				break
+			}
+		}
+	}
+	if len(names) == 1 {
+		return match, nil
+	}
+	return nil, names
 }
 
 // CmdNames returns the names of every Command.
 func (x *Cmd) CmdNames() []string {
 	list := []string{}
-	for _, c := range x.Commands {
+	for _, c := range x.AllCommands() {
 		if c.Name == "" {
 			continue
 		}
@@ -320,27 +774,119 @@ func (x *Cmd) CmdNames() []string {
 
 // UsageCmdTitles returns a single string with the titles of each
 // subcommand indented and with a maximum title signature length for
-// justification.  Hidden commands are not included. Note that the order
-// of the Commands is preserved (not necessarily alphabetic).
+// justification. Hidden commands (see IsHidden) and those without a
+// Name are not included (nor counted for the justification). Note that
+// the order of the Commands is preserved (not necessarily alphabetic).
+// Summaries too long for the Columns are truncated with an ellipsis
+// (see TruncateDisplay). Names are styled with Style.Name (see Styled).
+// If any of the Commands has a Group they are listed under a heading
+// for each (see Groups) separated by blank lines. The name column is
+// aligned across all groups. Deprecated commands have DeprecatedText
+// added to their summary. The explicit Default command (if any) is
+// marked with an asterisk (*).
 func (x *Cmd) UsageCmdTitles() string {
-	var set []string
-	var summaries []string
-	for _, c := range x.Commands {
-		set = append(set, strings.Join(c.Names(), "|"))
-		summaries = append(summaries, c.Summary)
+	var visible []*Cmd
+	var longest int
+	var def *Cmd
+	if x.Default != "" {
+		def = x.DefaultCmd()
 	}
-	longest := redu.Longest(set)
+	names := func(c *Cmd) string {
+		n := strings.Join(c.Names(), "|")
+		if c == def {
+			n += "*"
+		}
+		return n
+	}
+	for _, c := range x.AllCommands() {
+		if c.Name == "" || x.IsHidden(c.Name) {
+			continue
+		}
+		visible = append(visible, c)
+		if n := Width(names(c)); n > longest {
+			longest = n
+		}
+	}
+	groups := groupCmds(visible)
 	var buf string
-	for n := 0; n < len(set); n++ {
-		if len(summaries[n]) > 0 {
-			buf += fmt.Sprintf(`%-`+strconv.Itoa(longest)+"v - %v\n", set[n], summaries[n])
-		} else {
-			buf += fmt.Sprintf(`%-`+strconv.Itoa(longest)+"v\n", set[n])
+	for i, g := range groups {
+		if len(groups) > 1 || g.Name != "" {
+			if i > 0 {
+				buf += "\n"
+			}
+			name := g.Name
+			if name == "" {
+				name = ungroupedText()
+			}
+			buf += Styled(os.Stdout, Style.Title, name) + ":\n"
+		}
+		for _, c := range g.Commands {
+			set := names(c)
+			pad := strings.Repeat(" ", longest-Width(set))
+			name := Styled(os.Stdout, Style.Name, set)
+			summary := c.Summary
+			if c.Deprecated != "" {
+				summary = strings.TrimSpace(summary + " (" + deprecatedText() + ")")
+			}
+			if len(summary) > 0 {
+				buf += name + pad + " - " +
+					TruncateDisplay(summary, Columns-longest-3) + "\n"
+			} else {
+				buf += name + pad + "\n"
+			}
 		}
 	}
 	return buf
 }
 
+// UngroupedText, if set, is the heading used for Commands without
+// a Group when any others have one (see Groups) instead of the
+// "ungrouped" message of the current Lang (see Msg).
+var UngroupedText string
+
+func ungroupedText() string {
+	if UngroupedText != "" {
+		return UngroupedText
+	}
+	return Msg(`ungrouped`)
+}
+
+// CmdGroup is a named group of Commands (see Groups).
+type CmdGroup struct {
+	Name     string
+	Commands []*Cmd
+}
+
+// Groups returns the Commands clustered by their Group in order of
+// first appearance with those without a Group last (in a CmdGroup with
+// an empty Name). Order within each group is preserved.
+func (x *Cmd) Groups() []CmdGroup {
+	return groupCmds(x.AllCommands())
+}
+
+func groupCmds(cmds []*Cmd) []CmdGroup {
+	var groups []CmdGroup
+	var other []*Cmd
+	index := map[string]int{}
+	for _, c := range cmds {
+		if c.Group == "" {
+			other = append(other, c)
+			continue
+		}
+		i, has := index[c.Group]
+		if !has {
+			i = len(groups)
+			index[c.Group] = i
+			groups = append(groups, CmdGroup{Name: c.Group})
+		}
+		groups[i].Commands = append(groups[i].Commands, c)
+	}
+	if len(other) > 0 {
+		groups = append(groups, CmdGroup{Commands: other})
+	}
+	return groups
+}
+
 // Param returns Param matching name if found, empty string if not.
 func (x *Cmd) Param(p string) string {
 	if x.Params == nil {
@@ -355,21 +901,33 @@ func (x *Cmd) Param(p string) string {
 }
 
 // IsHidden returns true if the specified name is in the list of
-// Hidden commands.
+// Hidden commands or is the Name of one of the Commands that Hides
+// itself (see Hide).
 func (x *Cmd) IsHidden(name string) bool {
-	if x.Hidden == nil {
-		return false
-	}
 	for _, h := range x.Hidden {
 		if h == name {
 			return true
 		}
 	}
+	for _, c := range x.AllCommands() {
+		if c.Name == name && c.Hide {
+			return true
+		}
+	}
 	return false
 }
 
+// Seek returns the deepest Cmd named by the leading args (see Resolve)
+// along with the args that remain. The Caller of each Cmd along the way
+// is set (see Path) unless it is already bound to a different Caller in
+// which case a shallow copy is bound instead so that commands can be
+// shared by several branches (or trees) and sought concurrently.
 func (x *Cmd) Seek(args []string) (*Cmd, []string) {
-	if args == nil || x.Commands == nil {
+	return x.seek(args, false)
+}
+
+func (x *Cmd) seek(args []string, trace bool) (*Cmd, []string) {
+	if args == nil || len(x.AllCommands()) == 0 {
 		return x, args
 	}
 	cur := x
@@ -377,10 +935,15 @@ func (x *Cmd) Seek(args []string) (*Cmd, []string) {
 	for ; n < len(args); n++ {
 		next := cur.Resolve(args[n])
 		if next == nil {
+			if trace {
+				tracef("seek %v %q stopped", cur.Name, args[n])
+			}
 			break
 		}
-		next.Caller = cur
-		cur = next
+		if trace {
+			tracef("seek %v %q -> %v", cur.Name, args[n], next.Name)
+		}
+		cur = bind(next, cur)
 	}
 	return cur, args[n:]
 }
@@ -390,13 +953,31 @@ func (x *Cmd) Seek(args []string) (*Cmd, []string) {
 // Caller up rather than depending on anything from the command line
 // used to invoke the composing binary. Also see PathString.
 func (x *Cmd) Path() []string {
-	path := qstack.New[string]()
-	path.Unshift(x.Name)
-	for p := x.Caller; p != nil; p = p.Caller {
-		path.Unshift(p.Name)
+	path := []string{}
+	for c := x; c.Caller != nil; c = c.Caller {
+		path = append([]string{c.Name}, path...)
+	}
+	return path
+}
+
+// Root returns the top-most Cmd by walking up the Caller chain. If
+// there is no Caller the Cmd itself is returned.
+func (x *Cmd) Root() *Cmd {
+	r := x
+	for r.Caller != nil {
+		r = r.Caller
+	}
+	return r
+}
+
+// Callers returns every Cmd in the Caller chain starting with the Root
+// and ending with the immediate Caller (not including the Cmd itself).
+func (x *Cmd) Callers() []*Cmd {
+	var list []*Cmd
+	for c := x.Caller; c != nil; c = c.Caller {
+		list = append([]*Cmd{c}, list...)
 	}
-	path.Shift()
-	return path.Items()
+	return list
 }
 
 // PathString returns a dotted notation of the Path. This is useful for
@@ -406,108 +987,339 @@ func (x *Cmd) PathString() string {
 	return strings.Join(x.Path(), ".")
 }
 
-// Log is currently short for log.Printf() but may be supplemented in
-// the future to have more fine-grained control of logging.
+// Log logs at LevelInfo exactly like log.Printf() (without any prefix)
+// unless filtered by LogLevel. See Debug, Info, Warn, and Error for
+// messages prefixed with the PathString.
 func (x *Cmd) Log(format string, a ...any) {
-	log.Printf(format, a...)
+	logAt(LevelInfo, "", fmt.Sprintf(format, a...))
 }
 
 // Q is a shorter version of Z.Conf.Query(x.Path()+"."+q) for
-// convenience. Logs the error and returns a blank string if Z.Conf is
-// not defined (see ReqConf).
+// convenience. Returns a blank string if the result is null (or the
+// Default of the key from ConfKeys, if declared). Logs the error and
+// returns a blank string if Z.Conf is not defined (see ReqConf). Use QE
+// to tell missing configuration from empty values.
 func (x *Cmd) Q(q string) string {
-	if Conf == nil {
-		log.Printf("cmd %q requires a configurer (Z.Conf must be assigned)", x.Name)
-		return ""
+	v, err := x.QE(q)
+	if err != nil && Conf == nil {
+		logAt(LevelError, "", x.ReqConfError().Error())
 	}
-	return Conf.Query(x.PathString() + "." + q)
+	return v
 }
 
 // --------------------- bonzai.Command interface ---------------------
 
+// Every getter fulfilling the bonzai.Command interface returns the zero
+// value of its type (rather than panicking) for a nil *Cmd so that
+// interface values holding one can be used defensively.
+
+// nilCmd is returned by safe for a nil *Cmd.
+var nilCmd = new(Cmd)
+
+// safe returns the Cmd or, if nil, an empty one (see nilCmd).
+func (x *Cmd) safe() *Cmd {
+	if x == nil {
+		return nilCmd
+	}
+	return x
+}
+
+// sameCmds returns true if both contain the same Cmd pointers in the
+// same order.
+func sameCmds(a, b []*Cmd) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// cmdsCache contains the results of GetCommands and GetCommandNames
+// for the Commands (and their names) from which they were built.
+type cmdsCache struct {
+	cmds     []*Cmd
+	names    []string
+	commands []bonzai.Command
+	cmdnames []string
+}
+
+// cmds returns the cmdsCache for the current AllCommands building it
+// again if any of them (or their names) have changed since.
+func (x *Cmd) cmds() *cmdsCache {
+	all := x.AllCommands()
+	treemu.Lock()
+	c := x._cmdsi
+	treemu.Unlock()
+	if c != nil && sameCmds(c.cmds, all) {
+		same := true
+		for i, cmd := range all {
+			if cmd.Name != c.names[i] {
+				same = false
+				break
+			}
+		}
+		if same {
+			return c
+		}
+	}
+	c = &cmdsCache{
+		cmds:     append([]*Cmd{}, all...),
+		names:    make([]string, len(all)),
+		commands: make([]bonzai.Command, len(all)),
+		cmdnames: []string{},
+	}
+	for i, cmd := range all {
+		c.names[i] = cmd.Name
+		c.commands[i] = cmd
+		if cmd.Name != "" {
+			c.cmdnames = append(c.cmdnames, cmd.Name)
+		}
+	}
+	c.cmdnames = c.cmdnames[:len(c.cmdnames):len(c.cmdnames)]
+	treemu.Lock()
+	x._cmdsi = c
+	treemu.Unlock()
+	return c
+}
+
+// otherCache contains the result of GetOther for the Other sections
+// from which it was built during the Run.
+type otherCache struct {
+	other    []Section
+	run      int
+	sections []bonzai.Section
+}
+
 // GetName fulfills the bonzai.Command interface.
-func (x *Cmd) GetName() string { return x.Name }
+func (x *Cmd) GetName() string { return x.safe().Name }
 
 // GetTitle fulfills the bonzai.Command interface.
-func (x *Cmd) GetTitle() string { return x.Title() }
+func (x *Cmd) GetTitle() string {
+	if x == nil {
+		return ""
+	}
+	return x.Title()
+}
 
 // GetAliases fulfills the bonzai.Command interface.
-func (x *Cmd) GetAliases() []string { return x.Aliases }
+func (x *Cmd) GetAliases() []string { return x.safe().Aliases }
 
 // Summary fulfills the bonzai.Command interface.
-func (x *Cmd) GetSummary() string { return x.Summary }
+func (x *Cmd) GetSummary() string { return x.safe().Summary }
 
 // Usage fulfills the bonzai.Command interface.
-func (x *Cmd) GetUsage() string { return x.Usage }
+func (x *Cmd) GetUsage() string { return x.safe().Usage }
 
 // Version fulfills the bonzai.Command interface.
-func (x *Cmd) GetVersion() string { return x.Version }
+func (x *Cmd) GetVersion() string { return x.safe().Version }
 
 // Copyright fulfills the bonzai.Command interface.
-func (x *Cmd) GetCopyright() string { return x.Copyright }
+func (x *Cmd) GetCopyright() string { return x.safe().Copyright }
 
 // License fulfills the bonzai.Command interface.
-func (x *Cmd) GetLicense() string { return x.License }
+func (x *Cmd) GetLicense() string { return x.safe().License }
 
 // Description fulfills the bonzai.Command interface.
-func (x *Cmd) GetDescription() string { return x.Description }
+// The Description is filled in as a template first (see Fill).
+func (x *Cmd) GetDescription() string {
+	if x == nil {
+		return ""
+	}
+	return x.Fill(x.Description)
+}
 
 // Site fulfills the bonzai.Command interface.
-func (x *Cmd) GetSite() string { return x.Site }
+func (x *Cmd) GetSite() string { return x.safe().Site }
 
 // Source fulfills the bonzai.Command interface.
-func (x *Cmd) GetSource() string { return x.Source }
+func (x *Cmd) GetSource() string { return x.safe().Source }
 
 // Issues fulfills the bonzai.Command interface.
-func (x *Cmd) GetIssues() string { return x.Issues }
+func (x *Cmd) GetIssues() string { return x.safe().Issues }
 
 // MinArgs fulfills the bonzai.Command interface.
-func (x *Cmd) GetMinArgs() int { return x.MinArgs }
+func (x *Cmd) GetMinArgs() int { return x.safe().MinArgs }
 
 // MinParm fulfills the bonzai.Command interface.
-func (x *Cmd) GetMinParm() int { return x.MinParm }
+func (x *Cmd) GetMinParm() int { return x.safe().MinParm }
 
 // MaxParm fulfills the bonzai.Command interface.
-func (x *Cmd) GetMaxParm() int { return x.MaxParm }
+func (x *Cmd) GetMaxParm() int { return x.safe().MaxParm }
 
 // ReqConf fulfills the bonzai.Command interface.
-func (x *Cmd) GetReqConf() bool { return x.ReqConf }
+func (x *Cmd) GetReqConf() bool { return x.safe().ReqConf }
+
+// ReqVars fulfills the bonzai.Command interface.
+func (x *Cmd) GetReqVars() bool { return x.safe().ReqVars }
 
 // UsageFunc fulfills the bonzai.Command interface.
-func (x *Cmd) GetUsageFunc() bonzai.UsageFunc { return x.UsageFunc }
+func (x *Cmd) GetUsageFunc() bonzai.UsageFunc { return x.safe().UsageFunc }
 
-// GetCommands fulfills the bonzai.Command interface.
+// GetCommands fulfills the bonzai.Command interface. The slice is only
+// built again when AllCommands has changed (see cmds) and is shared
+// (and must not be changed).
 func (x *Cmd) GetCommands() []bonzai.Command {
-	var commands []bonzai.Command
-	for _, s := range x.Commands {
-		commands = append(commands, bonzai.Command(s))
+	if x == nil {
+		return nil
+	}
+	return x.cmds().commands
+}
+
+// GetCommandNames fulfills the bonzai.Command interface returning the
+// CmdNames (shared and only built again like GetCommands).
+func (x *Cmd) GetCommandNames() []string {
+	if x == nil {
+		return []string{}
 	}
-	return commands
+	return x.cmds().cmdnames
 }
 
-// GetCommandNames fulfills the bonzai.Command interface.
-func (x *Cmd) GetCommandNames() []string { return x.CmdNames() }
+// GetGroup fulfills the bonzai.Command interface.
+func (x *Cmd) GetGroup() string { return x.safe().Group }
 
 // GetHidden fulfills the bonzai.Command interface.
-func (x *Cmd) GetHidden() []string { return x.Hidden }
+func (x *Cmd) GetHidden() []string { return x.safe().Hidden }
+
+// GetHide fulfills the bonzai.Command interface.
+func (x *Cmd) GetHide() bool { return x.safe().Hide }
 
 // GetParams fulfills the bonzai.Command interface.
-func (x *Cmd) GetParams() []string { return x.Params }
+func (x *Cmd) GetParams() []string { return x.safe().Params }
+
+// GetRepeatable fulfills the bonzai.Command interface.
+func (x *Cmd) GetRepeatable() []string { return x.safe().Repeatable }
+
+// GetDeprecated fulfills the bonzai.Command interface.
+func (x *Cmd) GetDeprecated() string { return x.safe().Deprecated }
+
+// GetDepParams fulfills the bonzai.Command interface.
+func (x *Cmd) GetDepParams() map[string]string { return x.safe().DepParams }
 
-// GetOther fulfills the bonzai.Command interface.
+// GetExcl fulfills the bonzai.Command interface.
+func (x *Cmd) GetExcl() [][]string { return x.safe().Excl }
+
+// GetNeeds fulfills the bonzai.Command interface.
+func (x *Cmd) GetNeeds() map[string][]string { return x.safe().Needs }
+
+// GetOther fulfills the bonzai.Command interface. Each Body is filled
+// in as a template first (see Fill). The slice is only built again for
+// each Run or when the Other sections have changed and is shared (and
+// must not be changed).
 func (x *Cmd) GetOther() []bonzai.Section {
+	if x == nil {
+		return nil
+	}
+	treemu.Lock()
+	c, run := x._otheri, runs
+	treemu.Unlock()
+	if c != nil && c.run == run && len(c.other) == len(x.Other) {
+		same := true
+		for i, s := range x.Other {
+			if s != c.other[i] {
+				same = false
+				break
+			}
+		}
+		if same {
+			return c.sections
+		}
+	}
 	var sections []bonzai.Section
 	for _, s := range x.Other {
-		sections = append(sections, bonzai.Section(s))
+		sections = append(sections, Section{s.Title, x.Fill(s.Body)})
 	}
+	sections = sections[:len(sections):len(sections)]
+	treemu.Lock()
+	x._otheri = &otherCache{append([]Section{}, x.Other...), run, sections}
+	treemu.Unlock()
 	return sections
 }
 
 // GetOtherTitles fulfills the bonzai.Command interface.
-func (x *Cmd) GetOtherTitles() []string { return x.OtherTitles() }
+func (x *Cmd) GetOtherTitles() []string { return x.safe().OtherTitles() }
+
+// GetOtherSection fulfills the bonzai.Command interface. The Body is
+// filled (see Fill) and nil is returned if there is no such section.
+func (x *Cmd) GetOtherSection(title string) bonzai.Section {
+	body, has := x.safe().OtherSection(title)
+	if !has {
+		return nil
+	}
+	return Section{title, x.Fill(body)}
+}
+
+// GetExamples fulfills the bonzai.Command interface. The Cmd of each
+// is the full Invocation.
+func (x *Cmd) GetExamples() []bonzai.Example {
+	if x == nil {
+		return nil
+	}
+	var examples []bonzai.Example
+	for _, e := range x.Examples {
+		examples = append(examples, Example{x.Invocation(e.Cmd), e.Note})
+	}
+	return examples
+}
 
 // GetCompleter fulfills the Command interface.
-func (x *Cmd) GetCompleter() bonzai.Completer { return x.Completer }
+func (x *Cmd) GetCompleter() bonzai.Completer { return x.safe().Completer }
+
+// GetContextCompleter fulfills the Command interface.
+func (x *Cmd) GetContextCompleter() bonzai.ContextCompleter {
+	return x.safe().ContextCompleter
+}
+
+// GetCaller fulfills the bonzai.Command interface. A nil interface
+// value (rather than a nil *Cmd) is returned when there is no Caller.
+func (x *Cmd) GetCaller() bonzai.Command {
+	if x == nil || x.Caller == nil {
+		return nil
+	}
+	return x.Caller
+}
+
+// GetTag fulfills the bonzai.Command interface returning the value of
+// the tag (see Tags) from the Cmd or, if not set, from the nearest of
+// its Callers that has it. An empty string is returned if not found.
+func (x *Cmd) GetTag(key string) string {
+	for c := x; c != nil; c = c.Caller {
+		if v, has := c.Tags[key]; has {
+			return v
+		}
+	}
+	return ""
+}
 
-// GetCaller fulfills the bonzai.Command interface.
-func (x *Cmd) GetCaller() bonzai.Command { return x.Caller }
+// SetTag sets the tag (see Tags) creating the map if needed.
+func (x *Cmd) SetTag(key, value string) {
+	if x.Tags == nil {
+		x.Tags = map[string]string{}
+	}
+	x.Tags[key] = value
+}
+
+// GetIgnoreCase fulfills the bonzai.Command interface returning true if
+// IgnoreCase is set on the Cmd or any of its Callers.
+func (x *Cmd) GetIgnoreCase() bool {
+	for c := x; c != nil; c = c.Caller {
+		if c.IgnoreCase {
+			return true
+		}
+	}
+	return false
+}
+
+// GetPrefixMatch fulfills the bonzai.Command interface returning true
+// if PrefixMatch is set on the Cmd or any of its Callers.
+func (x *Cmd) GetPrefixMatch() bool {
+	for c := x; c != nil; c = c.Caller {
+		if c.PrefixMatch {
+			return true
+		}
+	}
+	return false
+}
@@ -5,8 +5,14 @@ package Z_test
 
 import (
 	"fmt"
+	"log"
 	"os"
+	"strings"
+	"sync"
+	"testing"
 
+	"github.com/rwxrob/bonzai"
+	"github.com/rwxrob/bonzai/comp"
 	Z "github.com/rwxrob/bonzai/z"
 )
 
@@ -114,7 +120,7 @@ func ExampleCmd_GetParams() {
 }
 
 func ExampleCmd_PathString() {
-	Z.ExitOff()
+	defer Z.SetExiter(new(Z.RecordingExiter))()
 
 	z := new(Z.Cmd)
 	c := z.Add("some")
@@ -177,8 +183,8 @@ func ExampleCmd_UsageParams() {
 	fmt.Println(x.UsageParams())
 
 	//Output:
-	// (p1|p2)?
-	// (p1|p2)+
+	// [p1|p2]...
+	// (p1|p2)...
 	// (p1|p2)
 }
 
@@ -246,3 +252,552 @@ func ExampleCmd_UsageCmdTitles() {
 	// nosum
 
 }
+
+func ExampleCmd_UsageCmdTitles_hidden() {
+	x := &Z.Cmd{
+		Name:   `cmd`,
+		Hidden: []string{`maintenance-only`},
+		Commands: []*Z.Cmd{
+			{Name: `foo`, Summary: `foo the things`},
+			{Name: `maintenance-only`, Summary: `hidden by the caller`},
+			{Name: `really-long-secret`, Summary: `hidden by itself`, Hide: true},
+			{Summary: `no name`},
+			{Name: `bar`, Summary: `bar the things`},
+		},
+	}
+	fmt.Print(x.UsageCmdTitles())
+	// Output:
+	// foo - foo the things
+	// bar - bar the things
+}
+
+func ExampleCmd_UsageCmdTitles_truncated() {
+	defer func(c int) { Z.Columns = c }(Z.Columns)
+	Z.Columns = 30
+	x := &Z.Cmd{
+		Name: `cmd`,
+		Commands: []*Z.Cmd{
+			&Z.Cmd{
+				Name:    "foo",
+				Summary: "foo the things with a very long summary",
+			},
+			&Z.Cmd{
+				Name:    "日本",
+				Summary: "wide names stay aligned too",
+			},
+		},
+	}
+	fmt.Print(x.UsageCmdTitles())
+	// Output:
+	// foo  - foo the things with a …
+	// 日本 - wide names stay aligne…
+}
+
+func ExampleCmd_UsageCmdTitles_default() {
+	x := &Z.Cmd{
+		Name:    `cmd`,
+		Default: `bar`,
+		Commands: []*Z.Cmd{
+			&Z.Cmd{Name: "foo", Summary: "foo the things"},
+			&Z.Cmd{Name: "bar", Summary: "bar the things"},
+		},
+	}
+	fmt.Print(x.UsageCmdTitles())
+	// Output:
+	// foo  - foo the things
+	// bar* - bar the things
+}
+
+func ExampleCmd_DefaultCmd() {
+	x := &Z.Cmd{
+		Name: `cmd`,
+		Commands: []*Z.Cmd{
+			&Z.Cmd{Name: "foo"},
+			&Z.Cmd{Name: "bar", Aliases: []string{"b"}},
+		},
+	}
+	fmt.Println(x.DefaultCmd().Name)
+	x.Default = "b"
+	fmt.Println(x.DefaultCmd().Name)
+	x.Default = "nothere"
+	fmt.Println(x.DefaultCmd())
+	// Output:
+	// foo
+	// bar
+	// <nil>
+}
+
+func ExampleCmd_Run_default() {
+	defer logErrs()()
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	defer log.SetOutput(os.Stderr)
+	defer log.SetFlags(log.Flags())
+	log.SetOutput(os.Stdout)
+	log.SetFlags(0)
+
+	call := func(x *Z.Cmd, args ...string) error {
+		fmt.Println(x.Name, x.Caller.Name, x.WasDefaulted(), args)
+		return nil
+	}
+	x := &Z.Cmd{
+		Name:    `foo`,
+		Default: `bar`,
+		Commands: []*Z.Cmd{
+			&Z.Cmd{Name: `first`, Call: call},
+			&Z.Cmd{Name: `bar`, Params: []string{`a`, `b`}, MinArgs: 1, Call: call},
+		},
+	}
+
+	orig := os.Args
+	defer func() { os.Args = orig }()
+
+	os.Args = []string{"foo", "arg"}
+	x.Run()
+
+	os.Args = []string{"foo", "bar", "arg"}
+	x.Run()
+
+	os.Args = []string{"foo"}
+	x.Run()
+
+	os.Args = []string{"foo", "bar"}
+	x.Run()
+
+	// Output:
+	// bar foo true [arg]
+	// bar foo false [arg]
+	// usage: foo [a|b]...
+	// usage: bar [a|b]...
+}
+
+func ExampleCmd_Resolve() {
+	x := &Z.Cmd{
+		Name:   `foo`,
+		Hidden: []string{"secret"},
+		Commands: []*Z.Cmd{
+			&Z.Cmd{Name: `status`, Aliases: []string{"st"}},
+			&Z.Cmd{Name: `start`},
+			&Z.Cmd{Name: `build`},
+			&Z.Cmd{Name: `secret`},
+		},
+	}
+
+	fmt.Println(x.Resolve("st").Name)
+	fmt.Println(x.Resolve("Status"))
+	fmt.Println(x.Resolve("bu"))
+
+	x.IgnoreCase = true
+	fmt.Println(x.Resolve("Status").Name)
+
+	x.PrefixMatch = true
+	fmt.Println(x.Resolve("BU").Name)
+	fmt.Println(x.Resolve("sta"), x.Ambiguous("sta"))
+	fmt.Println(x.Resolve("sec"))
+
+	// Output:
+	// status
+	// <nil>
+	// <nil>
+	// status
+	// build
+	// <nil> [status start]
+	// <nil>
+}
+
+func ExampleCmd_Run_ambiguous() {
+	defer logErrs()()
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	log.SetOutput(os.Stdout)
+	log.SetFlags(0)
+	defer log.SetOutput(os.Stderr)
+	defer log.SetFlags(log.LstdFlags)
+
+	call := func(x *Z.Cmd, _ ...string) error {
+		fmt.Println(x.Name)
+		return nil
+	}
+	x := &Z.Cmd{
+		Name:        `foo`,
+		PrefixMatch: true,
+		Commands: []*Z.Cmd{
+			&Z.Cmd{Name: `status`, Call: call},
+			&Z.Cmd{Name: `start`, Call: call},
+		},
+	}
+
+	orig := os.Args
+	defer func() { os.Args = orig }()
+
+	os.Args = []string{"foo", "stat"}
+	x.Run()
+
+	os.Args = []string{"foo", "st"}
+	x.Run()
+
+	// Output:
+	// status
+	// ambiguous command "st" (status, start)
+}
+
+func ExampleCmd_GetExamples() {
+	migrate := &Z.Cmd{
+		Name:    `migrate`,
+		Params:  []string{"up", "down"},
+		MinParm: 1,
+		Call:    func(_ *Z.Cmd, _ ...string) error { return nil },
+		Examples: []Z.Example{
+			{`up`, `apply all migrations`},
+			{Cmd: `down`},
+		},
+	}
+	x := &Z.Cmd{Name: `foo`, Commands: []*Z.Cmd{migrate}}
+	x.Seek([]string{"migrate"})
+	for _, e := range migrate.GetExamples() {
+		fmt.Printf("%q %q\n", e.GetCmd(), e.GetNote())
+	}
+	fmt.Println(migrate.UsageError())
+	// Output:
+	// "foo migrate up" "apply all migrations"
+	// "foo migrate down" ""
+	// usage: migrate (up|down)...
+	// example: foo migrate up
+}
+
+func ExampleCmd_UsageCmdTitles_groups() {
+	x := &Z.Cmd{
+		Name: `cmd`,
+		Commands: []*Z.Cmd{
+			&Z.Cmd{Name: "migrate", Group: "Database", Summary: "migrate it"},
+			&Z.Cmd{Name: "help", Summary: "show help"},
+			&Z.Cmd{Name: "serve", Group: "Server", Summary: "serve it"},
+			&Z.Cmd{Name: "seed", Group: "Database", Summary: "seed it"},
+		},
+	}
+	fmt.Print(x.UsageCmdTitles())
+	// Output:
+	// Database:
+	// migrate - migrate it
+	// seed    - seed it
+	//
+	// Server:
+	// serve   - serve it
+	//
+	// Other Commands:
+	// help    - show help
+}
+
+func ExampleCmd_Hide() {
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+
+	// composed from elsewhere, hides itself
+	maint := &Z.Cmd{
+		Name:    `maint`,
+		Summary: `maintenance`,
+		Hide:    true,
+		Call: func(_ *Z.Cmd, _ ...string) error {
+			fmt.Println("maintained")
+			return nil
+		},
+	}
+	x := &Z.Cmd{
+		Name: `foo`,
+		Commands: []*Z.Cmd{
+			&Z.Cmd{Name: `bar`, Summary: `bar it`, Call: maint.Call},
+			maint,
+		},
+	}
+
+	fmt.Println(x.IsHidden("maint"))
+	fmt.Print(x.UsageCmdTitles())
+	fmt.Println(comp.Standard(x, "m"))
+	md, _ := Z.ToMarkdown(x)
+	fmt.Println(strings.Contains(md, "maint"))
+
+	orig := os.Args
+	defer func() { os.Args = orig }()
+	os.Args = []string{"foo", "maint"}
+	x.Run()
+
+	// Output:
+	// true
+	// bar - bar it
+	// []
+	// false
+	// maintained
+}
+
+func ExampleCmd_GetTag() {
+	x := &Z.Cmd{
+		Name: `foo`,
+		Tags: map[string]string{"perm": "admin", "team": "core"},
+		Commands: []*Z.Cmd{
+			&Z.Cmd{Name: `bar`, Tags: map[string]string{"perm": "user"}},
+		},
+	}
+	bar, _ := x.Seek([]string{"bar"})
+	bar.SetTag("flag", "beta")
+	fmt.Println(bar.GetTag("perm"))
+	fmt.Println(bar.GetTag("team"))
+	fmt.Println(bar.GetTag("flag"))
+	fmt.Printf("%q\n", bar.GetTag("nope"))
+	// Output:
+	// user
+	// core
+	// beta
+	// ""
+}
+
+func ExampleInPath() {
+	fmt.Println(Z.InPath("go"))
+	fmt.Println(Z.InPath("go", "__inoexist"))
+	fmt.Println(Z.MissingFromPath("go", "__inoexist", "__nope"))
+	// Output:
+	// true
+	// false
+	// [__inoexist __nope]
+}
+
+func ExampleCmd_Run_require() {
+	defer logErrs()()
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	defer log.SetOutput(os.Stderr)
+	defer log.SetFlags(log.Flags())
+	log.SetOutput(os.Stdout)
+	log.SetFlags(0)
+	orig := os.Args
+	defer func() { os.Args = orig }()
+
+	x := &Z.Cmd{
+		Name: `foo`,
+		Commands: []*Z.Cmd{
+			&Z.Cmd{
+				Name:    `build`,
+				Require: []string{"go", "__inoexist", "__nope"},
+				Call: func(_ *Z.Cmd, _ ...string) error {
+					fmt.Println("never called")
+					return nil
+				},
+			},
+		},
+	}
+
+	os.Args = []string{"foo", "build"}
+	x.Run()
+
+	// Output:
+	// build requires (not found in PATH): __inoexist, __nope
+}
+
+func ExampleCmd_Run_requirements_branch() {
+	defer logErrs()()
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	defer log.SetOutput(os.Stderr)
+	defer log.SetFlags(log.Flags())
+	log.SetOutput(os.Stdout)
+	log.SetFlags(0)
+	orig := os.Args
+	defer func() { os.Args = orig }()
+	origVars := Z.Vars
+	defer func() { Z.Vars = origVars }()
+
+	x := &Z.Cmd{
+		Name: `foo`,
+		Commands: []*Z.Cmd{
+			&Z.Cmd{
+				Name:    `db`,
+				ReqConf: true,
+				ReqVars: true,
+				Require: []string{"__inoexist"},
+				Commands: []*Z.Cmd{
+					&Z.Cmd{
+						Name: `migrate`,
+						Call: func(_ *Z.Cmd, _ ...string) error {
+							fmt.Println("migrated")
+							return nil
+						},
+					},
+				},
+			},
+		},
+	}
+
+	os.Args = []string{"foo", "db", "migrate"}
+	x.Run()
+	Z.Conf = Z.NewMapConf()
+	defer func() { Z.Conf = nil }()
+	Z.Vars = nil
+	x.Run()
+	Z.Vars = origVars
+	x.Run()
+	x.Commands[0].Require = nil
+	x.Run()
+
+	// Output:
+	// cmd "db" requires a configurer (Z.Conf must be assigned)
+	// cmd "db" requires vars (Z.Vars must be assigned)
+	// db requires (not found in PATH): __inoexist
+	// migrated
+}
+
+func ExampleCmd_OtherTitles() {
+	x := &Z.Cmd{
+		Name: `foo`,
+		Other: []Z.Section{
+			{`Zebra`, `last alphabetically`},
+			{`Apple`, `first alphabetically`},
+			{`Mango`, `in the middle`},
+		},
+	}
+	for i := 0; i < 3; i++ {
+		fmt.Println(x.OtherTitles())
+	}
+	fmt.Println(x.GetOtherSection(`Mango`).GetBody())
+	fmt.Println(x.GetOtherSection(`Kiwi`) == nil)
+	// Output:
+	// [Zebra Apple Mango]
+	// [Zebra Apple Mango]
+	// [Zebra Apple Mango]
+	// in the middle
+	// true
+}
+
+func ExampleCmd_RunE() {
+	x := &Z.Cmd{
+		Name: `foo`,
+		Commands: []*Z.Cmd{{
+			Name:    `greet`,
+			MinArgs: 1,
+			Call: func(x *Z.Cmd, args ...string) error {
+				fmt.Println(x.PathString(), args)
+				return nil
+			},
+		}},
+	}
+	fmt.Println(x.RunE(`greet`, `you`))
+	fmt.Println(x.RunE(`greet`))
+	// Output:
+	// greet [you]
+	// <nil>
+	// usage: greet
+}
+
+func TestCmd_RunE_parallel(t *testing.T) {
+	var mu sync.Mutex
+	got := map[string]int{}
+	leaf := &Z.Cmd{
+		Name:    `leaf`,
+		Aliases: []string{`l`},
+		Call: func(x *Z.Cmd, args ...string) error {
+			mu.Lock()
+			got[x.PathString()+" "+strings.Join(args, " ")]++
+			mu.Unlock()
+			return nil
+		},
+	}
+	x := &Z.Cmd{
+		Name: `foo`,
+		Commands: []*Z.Cmd{
+			{Name: `a`, Commands: []*Z.Cmd{leaf}},
+			{Name: `b`, Commands: []*Z.Cmd{leaf}},
+		},
+		Other: []Z.Section{{`Notes`, `some`}},
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		for _, args := range [][]string{{`a`, `leaf`, `1`}, {`b`, `l`, `2`}} {
+			wg.Add(1)
+			go func(args []string) {
+				defer wg.Done()
+				if err := x.RunE(args...); err != nil {
+					t.Error(err)
+				}
+				x.GetOtherSection(`Notes`)
+			}(args)
+		}
+	}
+	wg.Wait()
+
+	if got["a.leaf 1"] != 50 || got["b.leaf 2"] != 50 || len(got) != 2 {
+		t.Errorf("unexpected calls: %v", got)
+	}
+}
+
+func benchmarkResolve(b *testing.B, off bool) {
+	x := &Z.Cmd{Name: `big`}
+	for i := 0; i < 500; i++ {
+		x.Add(fmt.Sprintf("leaf%03d", i), fmt.Sprintf("l%03d", i))
+	}
+	Z.ResolveIndexOff = off
+	defer func() { Z.ResolveIndexOff = false }()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		x.Resolve(`leaf499`)
+		x.Resolve(`l250`)
+	}
+}
+
+func BenchmarkCmd_Resolve_indexed(b *testing.B) { benchmarkResolve(b, false) }
+func BenchmarkCmd_Resolve_scanned(b *testing.B) { benchmarkResolve(b, true) }
+
+func ExampleCmd_GetCommands_nil() {
+	var x *Z.Cmd
+	var c bonzai.Command = x
+	fmt.Printf("%q %q %v %v %v %v\n", c.GetName(), c.GetTitle(),
+		c.GetCommands(), c.GetCommandNames(), c.GetOther(), c.GetCaller())
+	// Output:
+	// "" "" [] [] [] <nil>
+}
+
+func ExampleCmd_GetCommands_changed() {
+	x := &Z.Cmd{Name: `foo`}
+	x.Add(`a`)
+	fmt.Println(x.GetCommandNames(), len(x.GetCommands()))
+	x.Add(`b`)
+	fmt.Println(x.GetCommandNames(), len(x.GetCommands()))
+	x.Commands[0].Name = `c`
+	fmt.Println(x.GetCommandNames())
+	x.Other = []Z.Section{{`Notes`, `some`}}
+	fmt.Println(x.GetOther()[0].GetBody())
+	x.Other[0].Body = `more`
+	fmt.Println(x.GetOther()[0].GetBody())
+	// Output:
+	// [a] 1
+	// [a b] 2
+	// [c b]
+	// some
+	// more
+}
+
+func largeTree() *Z.Cmd {
+	x := &Z.Cmd{Name: `big`, Other: []Z.Section{{`Notes`, `some`}}}
+	for i := 0; i < 1000; i++ {
+		x.Add(fmt.Sprintf("leaf%03d", i))
+	}
+	return x
+}
+
+func BenchmarkCmd_GetCommands(b *testing.B) {
+	x := largeTree()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		x.GetCommands()
+		x.GetCommandNames()
+		x.GetOther()
+	}
+}
+
+func BenchmarkCmd_Run_completion(b *testing.B) {
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	defer os.Unsetenv("COMP_LINE")
+	defer func(o *os.File) { os.Stdout = o }(os.Stdout)
+	os.Stdout, _ = os.Open(os.DevNull)
+	x := largeTree()
+	os.Setenv("COMP_LINE", "big leaf99")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		x.Run()
+	}
+}
@@ -0,0 +1,434 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rwxrob/bonzai"
+	"github.com/rwxrob/bonzai/comp"
+)
+
+// CompShells are the shells for which CompletionScript can produce
+// a completion script.
+var CompShells = []string{"bash", "zsh", "fish"}
+
+// CompTimeout is the longest any Completer or Describer of a Cmd may
+// take before its candidates are abandoned in favor of those from
+// comp.Static (along with the last ones cached if CompCache is set, see
+// complete) so that a slow completer (one using the network, for
+// example) never hangs the shell. Set CompTimeout on the Cmd itself to
+// override it (less than zero for no deadline at all). Zero or less
+// also disables the deadline for every Cmd not overriding it.
+var CompTimeout = 2 * time.Second
+
+// CompDescWidth is the most cells (see Width) taken by the description
+// of any completion candidate (for the shells that display them, see
+// CompShell) beyond which it is truncated (see TruncateDisplay).
+var CompDescWidth = 60
+
+// CompMatch is the strategy used by comp.Standard to match the word
+// being completed (see comp.Matching and comp.Filter). Fuzzy matches
+// are left in the order ranked rather than sorted (see complete). Note
+// that some shells (zsh and fish) filter the candidates by prefix
+// again.
+var CompMatch = comp.Prefix
+
+const bashCompScript = `complete -C %[2]v %[2]v
+`
+
+const zshCompScript = `#compdef %[2]v
+%[1]v() {
+  local -a completions
+  completions=("${(@f)$(BONZAI_COMP_SHELL=zsh COMP_LINE="${words[1,CURRENT]}" %[2]v 2>/dev/null)}")
+  _describe 'command' completions
+}
+compdef %[1]v %[2]v
+`
+
+const fishCompScript = `function %[1]v
+  set -lx BONZAI_COMP_SHELL fish
+  set -lx COMP_LINE (commandline -cp)
+  %[2]v 2>/dev/null
+end
+complete -c %[2]v -f -a '(%[1]v)'
+`
+
+// CompShell returns the name of the shell requesting completion as
+// indicated by the BONZAI_COMP_SHELL environment variable set by the
+// adapters from CompletionScript. Since bash sets COMP_LINE itself
+// (complete -C) and has no such adapter, "bash" is returned when
+// BONZAI_COMP_SHELL is unset.
+func CompShell() string {
+	if s := os.Getenv("BONZAI_COMP_SHELL"); s != "" {
+		return s
+	}
+	return "bash"
+}
+
+// CompLine returns the COMP_LINE environment variable truncated at the
+// cursor position (COMP_POINT) the same way bash does so that any words
+// after the cursor are ignored when completing. If the cursor is inside
+// a word only the prefix up to the cursor is completed. If COMP_POINT
+// is missing or invalid the full line is returned.
+func CompLine() string {
+	line := os.Getenv("COMP_LINE")
+	point, err := strconv.Atoi(os.Getenv("COMP_POINT"))
+	if err != nil || point < 0 || point > len(line) {
+		return line
+	}
+	return line[:point]
+}
+
+// CompletionScript returns a script ready to be sourced (or saved into
+// a shell's rc file) that registers the root command name (or ExeName
+// if the Cmd has no Name) for completion with the given shell (see
+// CompShells) along with every multicall name from Commands (see Run).
+// Every script simply invokes the binary itself in completion mode so
+// that all completion remains in Go. The bash script is the one-line
+// "complete -C" while zsh and fish require a small adapter function to
+// set COMP_LINE (and BONZAI_COMP_SHELL, see CompShell) and read back
+// the candidates.
+func CompletionScript(shell string, x *Cmd) (string, error) {
+	name := x.Name
+	if name == "" {
+		name = ExeName
+	}
+	var script string
+	switch shell {
+	case "bash":
+		script = bashCompScript
+	case "zsh":
+		script = zshCompScript
+	case "fish":
+		script = fishCompScript
+	default:
+		return "", errors.New(Msg(`unsupported-shell`, shell))
+	}
+	var out string
+	for _, n := range append([]string{name}, multicallNames()...) {
+		if n == name && out != "" {
+			continue
+		}
+		out += fmt.Sprintf(script, compFuncName(n), n)
+	}
+	return out, nil
+}
+
+// CompletionCmd is an optional builtin leaf command (see Builtins) that
+// prints the CompletionScript for the root Cmd and the shell passed as
+// the only param (see CompShells). If no shell is passed the base name
+// of the SHELL environment variable is used. Output is always to
+// standard output so that it can be redirected or sourced directly:
+//
+//     source <(foo completion bash)
+//     foo completion fish | source
+//
+// With the --static flag the StaticCompletion script is printed
+// instead (for bash and zsh only).
+var CompletionCmd = &Cmd{
+	Name:    `completion`,
+	Summary: `print shell completion script`,
+	Params:  CompShells,
+	MaxParm: 1,
+	Flags: []Flag{{
+		Name:    `static`,
+		Summary: `complete without invoking the binary (bash and zsh only)`,
+	}},
+	Call: func(x *Cmd, args ...string) error {
+		if len(args) > 1 {
+			return x.UsageError()
+		}
+		shell := filepath.Base(os.Getenv("SHELL"))
+		if len(args) > 0 {
+			shell = args[0]
+		}
+		var script string
+		var err error
+		if x.Flag(`static`) == "true" {
+			script, err = StaticCompletion(x.Root(), shell)
+		} else {
+			script, err = CompletionScript(shell, x.Root())
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Print(script)
+		return nil
+	},
+}
+
+// complete prints the completion candidates for the given line (see
+// CompLine) in the format expected by the CompShell. Z.Aliases (and
+// ConfAliases) are included wherever a Command name would be accepted
+// and those already on the line are expanded first so that completion
+// continues as if their words had been typed (see rewriteAliases).
+// Descriptions are only printed for shells that support them. The
+// Describer of the Cmd is preferred over its ContextCompleter (passed
+// the full line, see bonzai.CompContext), which is preferred over its
+// Completer, which is preferred over comp.Standard. Completers are
+// described with comp.Describe. Words beginning with a dash complete
+// the available Flags instead and those beginning with @ complete files
+// if ExpandArgFiles is set. Whatever the source, the candidates are
+// de-duplicated (the first Description is kept) and sorted
+// lexicographically by Value (unless CompMatch is comp.Fuzzy) with any
+// deprecated Commands and Params (see Deprecated and DepParams) last.
+// Those printed for bash are escaped (see EscCompletion) since bash
+// inserts them exactly as printed. The Describer or Completer is called
+// in its own goroutine under a deadline (see CompTimeout) that, once
+// passed, abandons it (leaving it to finish or leak until the process
+// exits, which is almost immediately). If CompCache is set the last
+// successful candidates of the Cmd for the same word being completed
+// are saved in Vars and included whenever the deadline passes. If
+// BONZAI_COMP_DEBUG is set (see comp.DebugEnv) a trace of each step is
+// appended to the file it names. Descriptions are truncated to the
+// CompDescWidth.
+func (x *Cmd) complete(line string) {
+	comp.Debugf("COMP_LINE=%q COMP_POINT=%q shell=%v",
+		os.Getenv("COMP_LINE"), os.Getenv("COMP_POINT"), CompShell())
+	cands, expanded := x.completions(line)
+	comp.Debugf("candidates: %q", comp.Values(cands))
+	for i, c := range cands {
+		cands[i].Description = TruncateDisplay(c.Description, CompDescWidth)
+	}
+	switch CompShell() {
+	case "zsh":
+		for _, c := range cands {
+			v := strings.ReplaceAll(c.Value, ":", `\:`)
+			if c.Description != "" {
+				v += ":" + c.Description
+			}
+			fmt.Println(v)
+		}
+	case "fish":
+		for _, c := range cands {
+			if c.Description != "" {
+				c.Value += "\t" + c.Description
+			}
+			fmt.Println(c.Value)
+		}
+	default:
+		for _, v := range comp.Values(cands) {
+			if !expanded {
+				v = EscCompletion(v)
+			}
+			fmt.Println(v)
+		}
+	}
+}
+
+// candidates returns the completion candidates for the line (see
+// complete).
+func (x *Cmd) candidates(line string) []comp.Candidate {
+	cands, _ := x.completions(line)
+	return cands
+}
+
+// completions returns the candidates for the line (see candidates) and
+// whether the only one is the expansion of an alias, which is already
+// escaped (see EscAll) since it is made of several words.
+func (x *Cmd) completions(line string) ([]comp.Candidate, bool) {
+	var cands []comp.Candidate
+	comp.Matching = CompMatch
+	lineargs := ArgsFrom(line)
+	comp.Debugf("args: %q", lineargs)
+	if len(lineargs) == 0 {
+		return nil, false
+	}
+	words := lineargs[1:]
+	all := AllAliases()
+	var fvalue bool // completing the value of FormatFlag
+	if n := len(words); n > 0 {
+		pre, err := x.rewriteAliases(all, words[:n-1])
+		if err != nil {
+			comp.Debugf("aliases: %v", err)
+			return nil, false
+		}
+		if m := len(pre); m > 0 && pre[m-1] == "--"+FormatFlag.Name {
+			pre, fvalue = pre[:m-1], true
+		}
+		in, _, err := x.extractFlags(pre)
+		if err != nil {
+			comp.Debugf("flags: %v", err)
+			return nil, false
+		}
+		words = append(in, words[n-1])
+	}
+	cmd, args := x.Seek(words)
+	comp.Debugf("cmd: %q args: %q", append([]string{x.Name}, cmd.Path()...), args)
+	var last string
+	if len(args) > 0 {
+		last = args[len(args)-1]
+	}
+	switch {
+	case fvalue || strings.HasPrefix(last, "--"+FormatFlag.Name+"="):
+		comp.Debugf("completer: formats")
+		for _, f := range cmd.formatCandidates(last) {
+			cands = append(cands, comp.Candidate{Value: f})
+		}
+	case strings.HasPrefix(last, "-"):
+		comp.Debugf("completer: flags")
+		cands = cmd.flagCandidates(last)
+	case cmd.ExpandArgFiles && strings.HasPrefix(last, "@") &&
+		!strings.HasPrefix(last, "@@"):
+		comp.Debugf("completer: arg files")
+		for _, f := range comp.Files(cmd, last[1:]) {
+			cands = append(cands, comp.Candidate{Value: "@" + f})
+		}
+	case cmd.Describer != nil:
+		comp.Debugf("completer: Describer (%T)", cmd.Describer)
+		cands = cmd.timedComplete(args, func() []comp.Candidate {
+			return cmd.Describer.Complete(cmd, args...)
+		})
+	case cmd.ContextCompleter != nil:
+		comp.Debugf("completer: ContextCompleter")
+		ctx := cmd.compContext(lineargs, args)
+		cands = cmd.timedComplete(args, func() []comp.Candidate {
+			return comp.Describe(cmd, cmd.ContextCompleter(ctx))
+		})
+	case cmd.Completer != nil:
+		comp.Debugf("completer: Completer")
+		cands = cmd.timedComplete(args, func() []comp.Candidate {
+			return comp.Describe(cmd, cmd.Completer(cmd, args...))
+		})
+	default:
+		comp.Debugf("completer: aliases and comp.Standard")
+		aliases := map[string][]string{}
+		if len(args) == 1 && (cmd == x || len(cmd.AllCommands()) > 0) {
+			aliases = all
+			for _, k := range keysWithPrefix(aliases, last) {
+				cands = append(cands, comp.Candidate{
+					Value:       k,
+					Description: strings.Join(aliases[k], " "),
+				})
+			}
+		}
+		cands = append(cands, comp.StandardDescriber.Complete(cmd, args...)...)
+		if len(cands) == 1 {
+			if v, has := aliases[cands[0].Value]; has {
+				comp.Debugf("alias: %v = %q", cands[0].Value, v)
+				return []comp.Candidate{{Value: strings.Join(EscAll(v), " ")}}, true
+			}
+		}
+	}
+	return cmd.sortCands(cands), false
+}
+
+// compContext returns the context for completing the line (see
+// ArgsFrom) with the args left after seeking x from it.
+func (x *Cmd) compContext(line, args []string) bonzai.CompContext {
+	ctx := bonzai.CompContext{Line: line, Index: len(line) - 1, Args: args}
+	for _, c := range append(x.Callers(), x) {
+		ctx.Chain = append(ctx.Chain, c)
+	}
+	return ctx
+}
+
+// timedComplete returns the candidates from the function unless it
+// takes longer than the CompTimeout in which case those of comp.Static
+// for the args are returned instead along with any cached for the last
+// of them (see complete).
+func (x *Cmd) timedComplete(args []string, f func() []comp.Candidate) []comp.Candidate {
+	var word string
+	if len(args) > 0 {
+		word = args[len(args)-1]
+	}
+	timeout := CompTimeout
+	if x.CompTimeout != 0 {
+		timeout = x.CompTimeout
+	}
+	if timeout <= 0 {
+		cands := f()
+		x.cacheComp(word, cands)
+		return cands
+	}
+	done := make(chan []comp.Candidate, 1)
+	go func() { done <- f() }()
+	select {
+	case cands := <-done:
+		x.cacheComp(word, cands)
+		return cands
+	case <-time.After(timeout):
+		comp.Debugf("timed out after %v", timeout)
+		cands := x.cachedComp(word)
+		return append(cands, comp.Describe(x, comp.Static(x, args...))...)
+	}
+}
+
+// compKey returns the Vars key (see pathKey) for the completion cache
+// of the word.
+func (x *Cmd) compKey(word string) string {
+	return x.pathKey("comp." + url.QueryEscape(word))
+}
+
+// cacheComp saves the candidates for the word in Vars (one per line
+// with any Description after a tab) if CompCache is set.
+func (x *Cmd) cacheComp(word string, cands []comp.Candidate) {
+	if !x.CompCache || Vars == nil {
+		return
+	}
+	var lines []string
+	for _, c := range cands {
+		lines = append(lines, c.Value+"\t"+c.Description)
+	}
+	if err := Vars.Set(x.compKey(word), strings.Join(lines, "\n")); err != nil {
+		comp.Debugf("cache: %v", err)
+	}
+}
+
+// cachedComp returns the candidates saved by cacheComp for the word.
+func (x *Cmd) cachedComp(word string) []comp.Candidate {
+	if !x.CompCache || Vars == nil {
+		return nil
+	}
+	var cands []comp.Candidate
+	for _, line := range strings.Split(Vars.Get(x.compKey(word)), "\n") {
+		if line == "" {
+			continue
+		}
+		v, d, _ := strings.Cut(line, "\t")
+		cands = append(cands, comp.Candidate{Value: v, Description: d})
+	}
+	comp.Debugf("cached: %q", comp.Values(cands))
+	return cands
+}
+
+// sortCands de-duplicates and sorts the candidates (see complete).
+func (x *Cmd) sortCands(cands []comp.Candidate) []comp.Candidate {
+	dep := map[string]bool{}
+	for p := range x.DepParams {
+		dep[p] = true
+	}
+	for _, c := range x.AllCommands() {
+		if c.Deprecated != "" {
+			for _, n := range c.Names() {
+				dep[n] = true
+			}
+		}
+	}
+	seen := map[string]bool{}
+	out := []comp.Candidate{}
+	for _, c := range cands {
+		if seen[c.Value] {
+			continue
+		}
+		seen[c.Value] = true
+		out = append(out, c)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if dep[out[i].Value] != dep[out[j].Value] {
+			return !dep[out[i].Value]
+		}
+		return CompMatch != comp.Fuzzy && out[i].Value < out[j].Value
+	})
+	return out
+}
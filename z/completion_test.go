@@ -0,0 +1,455 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rwxrob/bonzai"
+	"github.com/rwxrob/bonzai/comp"
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func ExampleCompletionScript_bash() {
+	out, _ := Z.CompletionScript("bash", &Z.Cmd{Name: `foo`})
+	fmt.Print(out)
+	// Output:
+	// complete -C foo foo
+}
+
+func ExampleCompletionScript_zsh() {
+	out, _ := Z.CompletionScript("zsh", &Z.Cmd{Name: `foo-bar`})
+	fmt.Print(out)
+	// Output:
+	// #compdef foo-bar
+	// __foo_bar_complete() {
+	//   local -a completions
+	//   completions=("${(@f)$(BONZAI_COMP_SHELL=zsh COMP_LINE="${words[1,CURRENT]}" foo-bar 2>/dev/null)}")
+	//   _describe 'command' completions
+	// }
+	// compdef __foo_bar_complete foo-bar
+}
+
+func ExampleCompletionScript_fish() {
+	out, _ := Z.CompletionScript("fish", &Z.Cmd{Name: `foo`})
+	fmt.Print(out)
+	// Output:
+	// function __foo_complete
+	//   set -lx BONZAI_COMP_SHELL fish
+	//   set -lx COMP_LINE (commandline -cp)
+	//   foo 2>/dev/null
+	// end
+	// complete -c foo -f -a '(__foo_complete)'
+}
+
+func ExampleCompletionScript_unsupported() {
+	_, err := Z.CompletionScript("csh", &Z.Cmd{Name: `foo`})
+	fmt.Println(err)
+	// Output:
+	// unsupported completion shell: "csh"
+}
+
+func ExampleCmd_Run_zsh() {
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+
+	x := &Z.Cmd{
+		Name: `foo`,
+		Commands: []*Z.Cmd{
+			&Z.Cmd{Name: `bar`, Summary: `bar the things`},
+			&Z.Cmd{Name: `baz`},
+		},
+	}
+
+	os.Setenv("COMP_LINE", "foo b")
+	os.Setenv("BONZAI_COMP_SHELL", "zsh")
+	defer os.Unsetenv("COMP_LINE")
+	defer os.Unsetenv("BONZAI_COMP_SHELL")
+
+	x.Run()
+
+	// Output:
+	// bar:bar the things
+	// baz
+}
+
+func ExampleCmd_Run_fish() {
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+
+	x := &Z.Cmd{
+		Name: `foo`,
+		Commands: []*Z.Cmd{
+			&Z.Cmd{Name: `bar`, Summary: `bar the things`},
+			&Z.Cmd{Name: `baz`},
+		},
+	}
+
+	os.Setenv("COMP_LINE", "foo b")
+	os.Setenv("BONZAI_COMP_SHELL", "fish")
+	defer os.Unsetenv("COMP_LINE")
+	defer os.Unsetenv("BONZAI_COMP_SHELL")
+
+	x.Run()
+
+	// Output:
+	// bar	bar the things
+	// baz
+}
+
+func ExampleCompLine() {
+	os.Setenv("COMP_LINE", "foo bar baz")
+	defer os.Unsetenv("COMP_LINE")
+	defer os.Unsetenv("COMP_POINT")
+
+	fmt.Printf("%q\n", Z.CompLine())
+
+	os.Setenv("COMP_POINT", "5") // inside bar
+	fmt.Printf("%q\n", Z.CompLine())
+
+	os.Setenv("COMP_POINT", "8") // right after space
+	fmt.Printf("%q\n", Z.CompLine())
+
+	os.Setenv("COMP_POINT", "99") // invalid
+	fmt.Printf("%q\n", Z.CompLine())
+
+	// Output:
+	// "foo bar baz"
+	// "foo b"
+	// "foo bar "
+	// "foo bar baz"
+}
+
+func ExampleCmd_Run_comp_Point() {
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+
+	x := &Z.Cmd{
+		Name: `foo`,
+		Commands: []*Z.Cmd{
+			&Z.Cmd{
+				Name:   `bar`,
+				Params: []string{"one", "two"},
+				Call:   func(_ *Z.Cmd, _ ...string) error { return nil },
+			},
+			&Z.Cmd{Name: `baz`},
+		},
+	}
+
+	os.Setenv("COMP_LINE", "foo bar t other")
+	os.Setenv("COMP_POINT", "9")
+	defer os.Unsetenv("COMP_LINE")
+	defer os.Unsetenv("COMP_POINT")
+
+	x.Run()
+
+	// Output:
+	// two
+}
+
+func ExampleCmd_Run_describer() {
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+
+	x := &Z.Cmd{
+		Name: `foo`,
+		Call: func(_ *Z.Cmd, _ ...string) error { return nil },
+		Describer: comp.DescriberFunc(
+			func(_ bonzai.Command, _ ...string) []comp.Candidate {
+				return []comp.Candidate{
+					{Value: "mon", Description: "Monday"},
+					{Value: "tue", Description: "Tuesday"},
+				}
+			},
+		),
+	}
+
+	os.Setenv("COMP_LINE", "foo ")
+	defer os.Unsetenv("COMP_LINE")
+	defer os.Unsetenv("BONZAI_COMP_SHELL")
+
+	x.Run()
+
+	os.Setenv("BONZAI_COMP_SHELL", "fish")
+	x.Run()
+
+	// Output:
+	// mon
+	// tue
+	// mon	Monday
+	// tue	Tuesday
+}
+
+func ExampleCompletionScript_multicall() {
+	defer func(c map[string][]any) { Z.Commands = c }(Z.Commands)
+	x := &Z.Cmd{Name: `mybox`}
+	Z.Commands = map[string][]any{"mybox": {x}, "sub": {x, "sub"}}
+	script, _ := Z.CompletionScript("bash", x)
+	fmt.Print(script)
+	// Output:
+	// complete -C mybox mybox
+	// complete -C sub sub
+}
+
+func ExampleRun_completion() {
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	defer func(n string) { Z.ExeName = n }(Z.ExeName)
+	defer func(c map[string][]any) { Z.Commands = c }(Z.Commands)
+	orig := os.Getenv("COMP_LINE")
+	defer os.Setenv("COMP_LINE", orig)
+	args := os.Args
+	defer func() { os.Args = args }()
+	os.Args = []string{"mybox"}
+
+	x := &Z.Cmd{
+		Name: `mybox`,
+		Commands: []*Z.Cmd{
+			&Z.Cmd{Name: `top`},
+			&Z.Cmd{
+				Name: `sub`,
+				Commands: []*Z.Cmd{
+					&Z.Cmd{Name: `child`},
+					&Z.Cmd{Name: `other`},
+				},
+			},
+		},
+	}
+	Z.Commands = map[string][]any{"mybox": {x, "sub"}}
+	Z.ExeName = "mybox"
+
+	os.Setenv("COMP_LINE", "mybox ")
+	Z.Run()
+
+	// Output:
+	// child
+	// other
+}
+
+func ExampleCmd_Run_completion_sorted() {
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	defer func(a map[string][]string) { Z.Aliases = a }(Z.Aliases)
+	Z.Aliases = map[string][]string{`bump`: {`build`, `--major`}}
+
+	x := &Z.Cmd{
+		Name:   `foo`,
+		Params: []string{`bump`, `bake`},
+		Commands: []*Z.Cmd{
+			{Name: `build`, Summary: `build it`},
+			{Name: `bag`, Deprecated: `use bake`},
+			{Name: `batch`},
+		},
+	}
+	y := &Z.Cmd{
+		Name: `bar`,
+		Completer: func(_ bonzai.Command, _ ...string) []string {
+			return []string{`zeta`, `alpha`, `zeta`, `mid`}
+		},
+	}
+
+	defer os.Unsetenv("COMP_LINE")
+	os.Setenv("COMP_LINE", "foo b")
+	x.Run()
+	fmt.Println()
+	os.Setenv("COMP_LINE", "bar ")
+	y.Run()
+
+	// Output:
+	// bake
+	// batch
+	// build
+	// bump
+	// bag
+	//
+	// alpha
+	// mid
+	// zeta
+}
+
+func ExampleCmd_Run_completion_debug() {
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	dir, _ := os.MkdirTemp("", "bonzai")
+	defer os.RemoveAll(dir)
+	trace := filepath.Join(dir, "comp.log")
+
+	x := &Z.Cmd{
+		Name: `foo`,
+		Commands: []*Z.Cmd{{
+			Name: `bar`,
+			Completer: func(x bonzai.Command, args ...string) []string {
+				comp.Debugf("custom completer for %v", x.GetName())
+				return comp.List(`one`, `two`)(x, args...)
+			},
+		}},
+	}
+
+	defer os.Unsetenv("COMP_LINE")
+	defer os.Unsetenv(comp.DebugEnv)
+	os.Setenv("COMP_LINE", "foo bar t")
+	os.Setenv(comp.DebugEnv, trace)
+	x.Run()
+
+	buf, _ := os.ReadFile(trace)
+	fmt.Print(string(buf))
+
+	// Output:
+	// two
+	// COMP_LINE="foo bar t" COMP_POINT="" shell=bash
+	// args: ["foo" "bar" "t"]
+	// cmd: ["foo" "bar"] args: ["t"]
+	// completer: Completer
+	// custom completer for bar
+	// candidates: ["two"]
+}
+
+func ExampleCompTimeout() {
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	defer func(v bonzai.Vars) { Z.Vars = v }(Z.Vars)
+	dir, _ := os.MkdirTemp("", "bonzai")
+	defer os.RemoveAll(dir)
+	Z.Vars = &Z.VarsFile{File: filepath.Join(dir, "vars")}
+
+	hang := make(chan struct{})
+	defer close(hang)
+	slow := false
+
+	x := &Z.Cmd{
+		Name: `foo`,
+		Commands: []*Z.Cmd{{
+			Name:        `remote`,
+			Params:      []string{`origin`},
+			CompTimeout: 10 * time.Millisecond,
+			CompCache:   true,
+			Completer: func(x bonzai.Command, args ...string) []string {
+				if slow {
+					<-hang
+				}
+				return comp.List(`origin`, `upstream`)(x, args...)
+			},
+		}},
+	}
+
+	defer os.Unsetenv("COMP_LINE")
+	os.Setenv("COMP_LINE", "foo remote ")
+	x.Run()
+	fmt.Println("--- slow")
+	slow = true
+	x.Run()
+	fmt.Println("--- slow and different word")
+	os.Setenv("COMP_LINE", "foo remote o")
+	x.Run()
+
+	// Output:
+	// origin
+	// upstream
+	// --- slow
+	// origin
+	// upstream
+	// --- slow and different word
+	// origin
+}
+
+func ExampleCompMatch() {
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	defer func() { Z.CompMatch = comp.Prefix }()
+	defer os.Unsetenv("COMP_LINE")
+
+	x := &Z.Cmd{
+		Name: `foo`,
+		Commands: []*Z.Cmd{
+			{Name: `schema`}, {Name: `migrate`}, {Name: `remigrate`},
+			{Name: `mirror`},
+		},
+	}
+
+	os.Setenv("COMP_LINE", "foo mi")
+	x.Run()
+	fmt.Println("--- substring")
+	Z.CompMatch = comp.Substring
+	x.Run()
+	fmt.Println("--- fuzzy")
+	Z.CompMatch = comp.Fuzzy
+	os.Setenv("COMP_LINE", "foo mr")
+	x.Run()
+
+	// Output:
+	// migrate
+	// mirror
+	// --- substring
+	// migrate
+	// mirror
+	// remigrate
+	// --- fuzzy
+	// mirror
+	// migrate
+	// remigrate
+}
+
+func ExampleCmd_Run_completion_context() {
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	defer os.Unsetenv("COMP_LINE")
+
+	paths := map[string][]string{
+		`web`: {`/srv`, `/var/log`},
+		`db`:  {`/data`},
+	}
+	x := &Z.Cmd{
+		Name:  `foo`,
+		Flags: []Z.Flag{{Name: `user`, Value: true}},
+		Commands: []*Z.Cmd{{
+			Name: `copy`,
+			ContextCompleter: func(ctx bonzai.CompContext) []string {
+				fmt.Printf("%q %v %v %q\n", ctx.Line, ctx.Index,
+					len(ctx.Chain), ctx.Word())
+				switch len(ctx.Args) {
+				case 1: // host
+					return comp.List(`web`, `db`)(ctx.Leaf(), ctx.Args...)
+				case 2: // path on host
+					return comp.List(paths[ctx.Args[0]]...)(ctx.Leaf(), ctx.Args...)
+				}
+				return nil
+			},
+		}},
+	}
+
+	os.Setenv("COMP_LINE", "foo --user me copy w")
+	x.Run()
+	os.Setenv("COMP_LINE", "foo copy web /")
+	x.Run()
+
+	// Output:
+	// ["foo" "--user" "me" "copy" "w"] 4 2 "w"
+	// web
+	// ["foo" "copy" "web" "/"] 3 2 "/"
+	// /srv
+	// /var/log
+}
+
+func ExampleCmd_Run_completion_hidden() {
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	defer os.Unsetenv("COMP_LINE")
+
+	x := &Z.Cmd{
+		Name: `foo`,
+		Commands: []*Z.Cmd{
+			{Name: `secret`, Hide: true, Commands: []*Z.Cmd{
+				{Name: `reveal`}, {Name: `rotate`},
+			}},
+			{Name: `search`},
+		},
+	}
+
+	for _, line := range []string{"foo se", "foo secret", "foo secret r"} {
+		fmt.Println("---", line)
+		os.Setenv("COMP_LINE", line)
+		x.Run()
+	}
+
+	// Output:
+	// --- foo se
+	// search
+	// --- foo secret
+	// secret
+	// --- foo secret r
+	// reveal
+	// rotate
+}
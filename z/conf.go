@@ -0,0 +1,360 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rwxrob/bonzai"
+)
+
+// ErrConfReadOnly is returned by QSet when Z.Conf does not implement
+// bonzai.ConfigurerWriter.
+var ErrConfReadOnly = errors.New("configurer is read-only")
+
+// ErrMissingConfig is wrapped by every ConfigError so that Methods can
+// check for missing configuration with errors.Is.
+var ErrMissingConfig = errors.New("missing config")
+
+// ConfigError is returned by QE and MissingConfig when a configuration
+// entry is missing. It always wraps ErrMissingConfig.
+type ConfigError struct {
+	Path   string // full dotted path (ex: db.migrate.port)
+	NoConf bool   // Z.Conf was not assigned (see ReqConf)
+}
+
+// Error fulfills the error interface.
+func (e *ConfigError) Error() string {
+	if e.NoConf {
+		return Msg(`missing-config-conf`, e.Path)
+	}
+	return Msg(`missing-config`, e.Path)
+}
+
+// Unwrap returns ErrMissingConfig.
+func (e *ConfigError) Unwrap() error { return ErrMissingConfig }
+
+// ConfKey declares one of the configuration keys of a Cmd (see
+// ConfKeys) relative to its PathString (ex: url for db.migrate.url).
+// Run exits with an error listing every Required key that is missing
+// (and has no Default) before calling the Cmd. The Default is returned
+// by Q, QE, QOr, and the rest when the key is missing.
+type ConfKey struct {
+	Key      string `json:"key"`
+	Summary  string `json:"summary,omitempty"`
+	Default  string `json:"default,omitempty"`
+	Required bool   `json:"required,omitempty"`
+}
+
+// QE is the same as Q but returns a *ConfigError (see MissingConfig)
+// if Z.Conf is not defined or the query result is null (or empty)
+// instead of a blank string. The Default of the key declared in
+// ConfKeys (if any) is returned without error in either case.
+func (x *Cmd) QE(key string) (string, error) {
+	if Conf == nil {
+		if def, has := x.confDefault(key); has {
+			return def, nil
+		}
+		return "", &ConfigError{Path: x.PathString() + "." + key, NoConf: true}
+	}
+	v := cachedQuery(x.PathString() + "." + key)
+	if v == "" || v == "null" {
+		if def, has := x.confDefault(key); has {
+			return def, nil
+		}
+		return "", x.MissingConfig(key)
+	}
+	return v, nil
+}
+
+// QSet sets the value of the key under the PathString of the Cmd (ex:
+// db.migrate.url) if Z.Conf implements bonzai.ConfigurerWriter and
+// returns ErrConfReadOnly if it does not (see QE).
+func (x *Cmd) QSet(key, val string) error {
+	if Conf == nil {
+		return x.ReqConfError()
+	}
+	w, ok := Conf.(bonzai.ConfigurerWriter)
+	if !ok {
+		return ErrConfReadOnly
+	}
+	defer ConfCacheReset()
+	return w.Set(x.pathKey(key), val)
+}
+
+// ConfCacheOff disables the caching of the results of the queries made
+// by Q, QE, QInt, and the rest. By default, each result is cached by
+// its full dotted path for the rest of the Run (or until
+// ConfCacheReset) since some Configurer implementations are slow to
+// query. The cache is also reset whenever Z.Conf is reassigned or QSet
+// is called.
+var ConfCacheOff bool
+
+var confCache struct {
+	sync.Mutex
+	run  int
+	conf bonzai.Configurer
+	m    map[string]string
+}
+
+// ConfCacheReset clears the cached query results (see ConfCacheOff).
+// Embedders that keep a tree running (rather than calling Run for
+// every command) should call it whenever the configuration may have
+// changed.
+func ConfCacheReset() {
+	confCache.Lock()
+	defer confCache.Unlock()
+	confCache.m = nil
+}
+
+// cachedQuery returns the result of Conf.Query for q from the cache
+// (see ConfCacheOff) querying and caching it if needed.
+func cachedQuery(q string) string {
+	if ConfCacheOff {
+		return Conf.Query(q)
+	}
+	confCache.Lock()
+	defer confCache.Unlock()
+	if confCache.m == nil || confCache.run != runs || !sameConf(confCache.conf) {
+		confCache.m = map[string]string{}
+		confCache.run = runs
+		confCache.conf = Conf
+	}
+	v, has := confCache.m[q]
+	if !has {
+		v = Conf.Query(q)
+		confCache.m[q] = v
+	}
+	return v
+}
+
+// sameConf returns true if c is Conf. Configurers with types that
+// cannot be compared (and panic) are never the same.
+func sameConf(c bonzai.Configurer) (same bool) {
+	defer func() { recover() }()
+	return c == Conf
+}
+
+// confDefault returns the non-empty Default of the key from ConfKeys.
+func (x *Cmd) confDefault(key string) (string, bool) {
+	for _, k := range x.ConfKeys {
+		if k.Key == key && k.Default != "" {
+			return k.Default, true
+		}
+	}
+	return "", false
+}
+
+// checkConf returns an error wrapping ErrMissingConfig listing the full
+// dotted path of every Required key of ConfKeys that is missing.
+func (x *Cmd) checkConf() error {
+	var missing []string
+	for _, k := range x.ConfKeys {
+		if !k.Required {
+			continue
+		}
+		if _, err := x.QE(k.Key); err != nil {
+			missing = append(missing, x.pathKey(k.Key))
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%v: %w: %v",
+		x.logPath(), ErrMissingConfig, strings.Join(missing, ", "))
+}
+
+// UsageConf returns a single string with the full dotted path of each
+// of the ConfKeys (one per line) aligned and followed by its Summary,
+// Default, and whether it is Required (see UsageEnv). An empty string
+// is returned if there are no ConfKeys.
+func (x *Cmd) UsageConf() string {
+	var keys, summaries []string
+	for _, k := range x.ConfKeys {
+		keys = append(keys, x.pathKey(k.Key))
+		summaries = append(summaries, docSummary(k.Summary, k.Default, k.Required))
+	}
+	return usageTable(keys, summaries)
+}
+
+// EnvOverlay returns a bonzai.Configurer that answers every Query for
+// a simple path (see ConfEnvName) with the value of the environment
+// variable derived from it when it is set (even if empty) and passes
+// everything else to the Configurer c. Assign it to Z.Conf to allow
+// overriding individual values (ex: in CI) without editing the
+// configuration:
+//
+//     Z.Conf = Z.EnvOverlay(conf.New())
+//
+// Since Q, QE, QInt, and the rest all query Z.Conf the overlay applies
+// to them transparently. Data, Print, and Edit are never affected.
+func EnvOverlay(c bonzai.Configurer) bonzai.Configurer { return envOverlay{c} }
+
+type envOverlay struct{ bonzai.Configurer }
+
+func (o envOverlay) Query(q string) string {
+	if name := ConfEnvName(q); name != "" {
+		if v, has := os.LookupEnv(name); has {
+			return v
+		}
+	}
+	return o.Configurer.Query(q)
+}
+
+func (o envOverlay) QueryPrint(q string) { fmt.Println(o.Query(q)) }
+
+// ConfEnvName returns the name of the environment variable that
+// overrides the configuration query q (see EnvOverlay) or an empty
+// string if q is not a simple path. A simple path contains only
+// letters, digits, underscores, dashes, and dots (with an optional
+// leading dot). The name is the prefix followed by the path in
+// uppercase with every dot and dash replaced by an underscore. The
+// prefix is EnvPrefix or, if empty, the ExeName transformed the same
+// way followed by an underscore. For example, the query
+// .db.dry-run.url for the foo executable is FOO_DB_DRY_RUN_URL.
+func ConfEnvName(q string) string {
+	q = strings.TrimPrefix(q, ".")
+	if q == "" {
+		return ""
+	}
+	for _, r := range q {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '_', r == '-', r == '.':
+		default:
+			return ""
+		}
+	}
+	prefix := EnvPrefix
+	if prefix == "" {
+		prefix = envWord(ExeName) + "_"
+	}
+	return prefix + envWord(q)
+}
+
+// qval returns the trimmed result of Q for the key and false if it is
+// empty or null (not set).
+func (x *Cmd) qval(key string) (string, bool) {
+	v := strings.TrimSpace(x.Q(key))
+	return v, v != ""
+}
+
+// qinvalid logs a warning that the configured value for the key could
+// not be parsed and that the default is being used instead.
+func (x *Cmd) qinvalid(key, kind, val string, def any) {
+	logAt(LevelWarn, x.logPath(), fmt.Sprintf(
+		"invalid %v for %v: %q (using %v)", kind, key, val, def))
+}
+
+// QOr returns the result of Q for the key or def if it is not set.
+func (x *Cmd) QOr(key, def string) string {
+	if v, ok := x.qval(key); ok {
+		return v
+	}
+	return def
+}
+
+// QInt returns the result of Q for the key as an int (see strconv.Atoi)
+// or def if it is not set. A warning is logged and def returned if it
+// cannot be parsed.
+func (x *Cmd) QInt(key string, def int) int {
+	v, ok := x.qval(key)
+	if !ok {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		x.qinvalid(key, "integer", v, def)
+		return def
+	}
+	return n
+}
+
+// QBool returns the result of Q for the key as a bool or def if it is
+// not set. In addition to the values accepted by strconv.ParseBool, the
+// YAML words yes, no, on, and off (in any case) are accepted. A warning
+// is logged and def returned if it cannot be parsed.
+func (x *Cmd) QBool(key string, def bool) bool {
+	v, ok := x.qval(key)
+	if !ok {
+		return def
+	}
+	switch strings.ToLower(v) {
+	case "yes", "on":
+		return true
+	case "no", "off":
+		return false
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		x.qinvalid(key, "boolean", v, def)
+		return def
+	}
+	return b
+}
+
+// QDuration returns the result of Q for the key as a time.Duration (see
+// time.ParseDuration) or def if it is not set. A warning is logged and
+// def returned if it cannot be parsed.
+func (x *Cmd) QDuration(key string, def time.Duration) time.Duration {
+	v, ok := x.qval(key)
+	if !ok {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		x.qinvalid(key, "duration", v, def)
+		return def
+	}
+	return d
+}
+
+// QStringSlice returns the result of Q for the key as a slice of
+// strings or def if it is not set. The value may be a JSON or YAML flow
+// array of strings (ex: ["a", "b"], [a, b]), a YAML block sequence (one
+// "- item" per line), or a single string with items separated by commas
+// (ex: a, b). Surrounding white space is trimmed from each item and
+// empty items are dropped. A warning is logged and def returned if an
+// array cannot be parsed.
+func (x *Cmd) QStringSlice(key string, def []string) []string {
+	v, ok := x.qval(key)
+	if !ok {
+		return def
+	}
+	var items []string
+	switch {
+	case strings.HasPrefix(v, "["):
+		if json.Unmarshal([]byte(v), &items) == nil {
+			break
+		}
+		if !strings.HasSuffix(v, "]") {
+			x.qinvalid(key, "array", v, def)
+			return def
+		}
+		items = strings.Split(v[1:len(v)-1], ",")
+		for n, i := range items {
+			items[n] = strings.Trim(strings.TrimSpace(i), `"'`)
+		}
+	case strings.HasPrefix(v, "- "):
+		for _, line := range strings.Split(v, "\n") {
+			items = append(items, strings.TrimPrefix(strings.TrimSpace(line), "- "))
+		}
+	default:
+		items = strings.Split(v, ",")
+	}
+	list := []string{}
+	for _, i := range items {
+		if i = strings.TrimSpace(i); i != "" {
+			list = append(list, i)
+		}
+	}
+	return list
+}
@@ -0,0 +1,357 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rwxrob/bonzai"
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+// withConf sets Z.Conf to c and returns the leaf db.migrate of a new
+// tree and a function to restore Z.Conf and the log output.
+func withConf(c *Z.MapConf) (*Z.Cmd, *bytes.Buffer, func()) {
+	orig := Z.Conf
+	Z.Conf = c
+	buf := new(bytes.Buffer)
+	flags := log.Flags()
+	log.SetOutput(buf)
+	log.SetFlags(0)
+	x := &Z.Cmd{
+		Name:     `foo`,
+		Commands: []*Z.Cmd{{Name: `db`, Commands: []*Z.Cmd{{Name: `migrate`}}}},
+	}
+	leaf, _ := x.Seek([]string{"db", "migrate"})
+	return leaf, buf, func() {
+		Z.Conf = orig
+		log.SetOutput(os.Stderr)
+		log.SetFlags(flags)
+	}
+}
+
+func TestCmd_QInt(t *testing.T) {
+	tests := []struct {
+		val  string
+		want int
+		warn bool
+	}{
+		{"", 7, false},
+		{"null", 7, false},
+		{"42", 42, false},
+		{" -3 ", -3, false},
+		{"4.2", 7, true},
+		{"many", 7, true},
+	}
+	for _, tt := range tests {
+		x, buf, done := withConf(Z.NewMapConf("db.migrate.n", tt.val))
+		got := x.QInt("n", 7)
+		done()
+		if got != tt.want || (buf.Len() > 0) != tt.warn {
+			t.Errorf("%q: got %v (log %q), want %v", tt.val, got, buf, tt.want)
+		}
+	}
+}
+
+func TestCmd_QBool(t *testing.T) {
+	tests := []struct {
+		val  string
+		def  bool
+		want bool
+		warn bool
+	}{
+		{"null", true, true, false},
+		{"true", false, true, false},
+		{"1", false, true, false},
+		{"Yes", false, true, false},
+		{"on", false, true, false},
+		{"false", true, false, false},
+		{"0", true, false, false},
+		{"NO", true, false, false},
+		{"off", true, false, false},
+		{"maybe", true, true, true},
+	}
+	for _, tt := range tests {
+		x, buf, done := withConf(Z.NewMapConf("db.migrate.b", tt.val))
+		got := x.QBool("b", tt.def)
+		done()
+		if got != tt.want || (buf.Len() > 0) != tt.warn {
+			t.Errorf("%q: got %v (log %q), want %v", tt.val, got, buf, tt.want)
+		}
+	}
+}
+
+func TestCmd_QDuration(t *testing.T) {
+	tests := []struct {
+		val  string
+		want time.Duration
+		warn bool
+	}{
+		{"null", time.Second, false},
+		{"1m30s", 90 * time.Second, false},
+		{"0", 0, false},
+		{"10", time.Second, true},
+	}
+	for _, tt := range tests {
+		x, buf, done := withConf(Z.NewMapConf("db.migrate.d", tt.val))
+		got := x.QDuration("d", time.Second)
+		done()
+		if got != tt.want || (buf.Len() > 0) != tt.warn {
+			t.Errorf("%q: got %v (log %q), want %v", tt.val, got, buf, tt.want)
+		}
+	}
+}
+
+func TestCmd_QStringSlice(t *testing.T) {
+	def := []string{"def"}
+	tests := []struct {
+		val  string
+		want []string
+		warn bool
+	}{
+		{"null", def, false},
+		{"a", []string{"a"}, false},
+		{"a, b,,c ", []string{"a", "b", "c"}, false},
+		{`["a", "b c"]`, []string{"a", "b c"}, false},
+		{`[a, 'b', "c"]`, []string{"a", "b", "c"}, false},
+		{"[]", []string{}, false},
+		{"- a\n- b c\n", []string{"a", "b c"}, false},
+		{"[a, b", def, true},
+	}
+	for _, tt := range tests {
+		x, buf, done := withConf(Z.NewMapConf("db.migrate.s", tt.val))
+		got := x.QStringSlice("s", def)
+		done()
+		if !reflect.DeepEqual(got, tt.want) || (buf.Len() > 0) != tt.warn {
+			t.Errorf("%q: got %q (log %q), want %q", tt.val, got, buf, tt.want)
+		}
+	}
+}
+
+func ExampleCmd_QOr() {
+	x, _, done := withConf(Z.NewMapConf("db.migrate.env", "prod"))
+	defer done()
+	fmt.Println(x.QOr("env", "dev"), x.QOr("region", "us-east"))
+
+	// Output:
+	// prod us-east
+}
+
+func ExampleCmd_QInt() {
+	x, buf, done := withConf(Z.NewMapConf("db.migrate.port", "fifty"))
+	fmt.Println(x.QInt("port", 5432))
+	done()
+	fmt.Print(buf)
+
+	// Output:
+	// 5432
+	// db.migrate: warning: invalid integer for port: "fifty" (using 5432)
+}
+
+func ExampleCmd_QE() {
+	x, _, done := withConf(Z.NewMapConf("db.migrate.env", "prod"))
+	defer done()
+
+	fmt.Println(x.QE("env"))
+	_, err := x.QE("region")
+	fmt.Println(err, errors.Is(err, Z.ErrMissingConfig))
+	fmt.Printf("%q\n", x.Q("region"))
+
+	Z.Conf = nil
+	_, err = x.QE("env")
+	fmt.Println(err, errors.Is(err, Z.ErrMissingConfig))
+
+	// Output:
+	// prod <nil>
+	// missing config: db.migrate.region true
+	// ""
+	// missing config: db.migrate.env (Z.Conf must be assigned) true
+}
+
+func ExampleConfEnvName() {
+	defer func(p, n string) { Z.EnvPrefix, Z.ExeName = p, n }(Z.EnvPrefix, Z.ExeName)
+	Z.ExeName = `my-app`
+	fmt.Println(Z.ConfEnvName(`.db.migrate.url`))
+	fmt.Println(Z.ConfEnvName(`db.dry-run.Max_Conns`))
+	fmt.Printf("%q\n", Z.ConfEnvName(`.aliases | @json`))
+	fmt.Printf("%q\n", Z.ConfEnvName(`.`))
+	Z.EnvPrefix = `FOO_`
+	fmt.Println(Z.ConfEnvName(`.db.migrate.url`))
+
+	// Output:
+	// MY_APP_DB_MIGRATE_URL
+	// MY_APP_DB_DRY_RUN_MAX_CONNS
+	// ""
+	// ""
+	// FOO_DB_MIGRATE_URL
+}
+
+func ExampleEnvOverlay() {
+	x, _, done := withConf(Z.NewMapConf("db.migrate.port", "5432", "db.migrate.env", "dev"))
+	defer done()
+	defer func(p string) { Z.EnvPrefix = p }(Z.EnvPrefix)
+	Z.EnvPrefix = `FOO_`
+	defer os.Unsetenv(`FOO_DB_MIGRATE_PORT`)
+	os.Setenv(`FOO_DB_MIGRATE_PORT`, `6543`)
+
+	fmt.Println(x.QInt("port", 0), x.Q("env"))
+	Z.Conf = Z.EnvOverlay(Z.Conf)
+	fmt.Println(x.QInt("port", 0), x.Q("env"))
+	Z.Conf.QueryPrint(".db.migrate.port")
+
+	// Output:
+	// 5432 dev
+	// 6543 dev
+	// 6543
+}
+
+func confKeysTree() *Z.Cmd {
+	return &Z.Cmd{
+		Name: `foo`,
+		Commands: []*Z.Cmd{{
+			Name: `db`,
+			Commands: []*Z.Cmd{{
+				Name: `migrate`,
+				ConfKeys: []Z.ConfKey{
+					{Key: `url`, Summary: `database URL`, Required: true},
+					{Key: `token`, Summary: `access token`, Required: true},
+					{Key: `steps`, Summary: `steps to take`, Default: `1`, Required: true},
+				},
+				Call: func(x *Z.Cmd, _ ...string) error {
+					fmt.Println(x.Q("url"), x.QInt("steps", 0), x.QOr("token", "none"))
+					return nil
+				},
+			}},
+		}},
+	}
+}
+
+func ExampleCmd_Run_confkeys() {
+	defer logErrs()()
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	defer log.SetOutput(os.Stderr)
+	defer log.SetFlags(log.Flags())
+	log.SetOutput(os.Stdout)
+	log.SetFlags(0)
+	orig := os.Args
+	defer func() { os.Args = orig }()
+	defer func() { Z.Conf = nil }()
+	os.Args = []string{"foo", "db", "migrate"}
+
+	Z.Conf = Z.NewMapConf("db.migrate.url", "db://x")
+	confKeysTree().Run()
+
+	Z.Conf = Z.NewMapConf("db.migrate.url", "db://x", "db.migrate.token", "t")
+	confKeysTree().Run()
+
+	// Output:
+	// db.migrate: missing config: db.migrate.token
+	// db://x 1 t
+}
+
+func ExampleCmd_UsageConf() {
+	x, _ := confKeysTree().Seek([]string{"db", "migrate"})
+	fmt.Print(x.UsageConf())
+
+	// Output:
+	// db.migrate.url   - database URL (required)
+	// db.migrate.token - access token (required)
+	// db.migrate.steps - steps to take (default: 1) (required)
+}
+
+func ExampleCmd_QSet() {
+	x, _, done := withConf(Z.NewMapConf("db.migrate.url", "db://x"))
+	defer done()
+
+	fmt.Println(x.QSet("url", "db://y"), x.Q("url"))
+	fmt.Println(x.QSet("opts", `{"tls":true,"pool":{"max":"3"}}`), x.Q("opts.pool.max"))
+	fmt.Println(Z.Conf.Data())
+
+	Z.Conf = struct{ bonzai.Configurer }{Z.Conf} // hides Set
+	err := x.QSet("url", "db://z")
+	fmt.Println(err, errors.Is(err, Z.ErrConfReadOnly))
+
+	// Output:
+	// <nil> db://y
+	// <nil> 3
+	// {"db":{"migrate":{"opts":{"pool":{"max":"3"},"tls":"true"},"url":"db://y"}}}
+	// configurer is read-only true
+}
+
+func ExampleConfCacheOff() {
+	x, _, done := withConf(Z.NewMapConf("db.migrate.url", "db://x"))
+	defer done()
+	conf := Z.Conf.(*Z.MapConf)
+
+	for i := 0; i < 3; i++ {
+		x.Q("url")
+	}
+	fmt.Println(len(conf.Queries()))
+
+	x.QSet("url", "db://y")
+	fmt.Println(x.Q("url"), len(conf.Queries()))
+
+	Z.ConfCacheOff = true
+	defer func() { Z.ConfCacheOff = false }()
+	for i := 0; i < 3; i++ {
+		x.Q("url")
+	}
+	fmt.Println(len(conf.Queries()))
+
+	// Output:
+	// 1
+	// db://y 2
+	// 5
+}
+
+func TestCmd_Q_concurrent(t *testing.T) {
+	x, _, done := withConf(Z.NewMapConf("db.migrate.n", "42"))
+	defer done()
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if n := x.QInt("n", 0); n != 42 {
+				t.Errorf("got %v, want 42", n)
+			}
+			if i%5 == 0 {
+				Z.ConfCacheReset()
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+// slowConf is a Configurer that takes a while to answer every Query
+// (like those that shell out or parse a file each time).
+type slowConf struct{ *Z.MapConf }
+
+func (c slowConf) Query(q string) string {
+	time.Sleep(50 * time.Microsecond)
+	return c.MapConf.Query(q)
+}
+
+func benchmarkQ(b *testing.B, off bool) {
+	x, _, done := withConf(nil)
+	defer done()
+	Z.Conf = slowConf{Z.NewMapConf("db.migrate.url", "db://x")}
+	Z.ConfCacheOff = off
+	defer func() { Z.ConfCacheOff = false }()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		x.Q("url")
+	}
+}
+
+func BenchmarkCmd_Q_cached(b *testing.B)   { benchmarkQ(b, false) }
+func BenchmarkCmd_Q_uncached(b *testing.B) { benchmarkQ(b, true) }
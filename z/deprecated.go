@@ -0,0 +1,56 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrorOnDeprecated turns the warnings about Deprecated commands and
+// deprecated params (see DepParams) into errors that stop Run. This is
+// mostly useful in CI to catch scripts that need updating.
+var ErrorOnDeprecated bool
+
+// DeprecatedText, if set, is used to annotate deprecated commands and
+// in the warnings instead of the "deprecated" message of the current
+// Lang (see Msg).
+var DeprecatedText string
+
+func deprecatedText() string {
+	if DeprecatedText != "" {
+		return DeprecatedText
+	}
+	return Msg(`deprecated`)
+}
+
+// checkDeprecated logs a warning (or returns an error if
+// ErrorOnDeprecated) for the Cmd and each of its Callers that is
+// Deprecated and for each of the args in DepParams.
+func (x *Cmd) checkDeprecated(args []string) error {
+	var msgs []string
+	for c := x; c != nil; c = c.Caller {
+		if c.Deprecated != "" {
+			msgs = append([]string{Msg(`is-deprecated`,
+				c.Name, deprecatedText(), c.Deprecated)}, msgs...)
+		}
+	}
+	seen := map[string]bool{}
+	for _, a := range args {
+		if m, has := x.DepParams[a]; has && !seen[a] {
+			seen[a] = true
+			msgs = append(msgs, Msg(`is-deprecated`, a, deprecatedText(), m))
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	if ErrorOnDeprecated {
+		return errors.New(strings.Join(msgs, "\n"))
+	}
+	for _, m := range msgs {
+		logAt(LevelWarn, "", m)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func ExampleCmd_Run_deprecated() {
+	defer logErrs()()
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	defer log.SetOutput(os.Stderr)
+	defer log.SetFlags(log.Flags())
+	log.SetOutput(os.Stdout)
+	log.SetFlags(0)
+
+	x := &Z.Cmd{
+		Name: `foo`,
+		Commands: []*Z.Cmd{
+			&Z.Cmd{
+				Name:       `push`,
+				Deprecated: `use sync instead`,
+				Params:     []string{"old", "new"},
+				DepParams:  map[string]string{"old": "use new instead"},
+				Call: func(_ *Z.Cmd, args ...string) error {
+					fmt.Println("pushed", args)
+					return nil
+				},
+			},
+		},
+	}
+	fmt.Print(x.UsageCmdTitles())
+
+	orig := os.Args
+	defer func() { os.Args = orig }()
+	os.Args = []string{"foo", "push", "old"}
+	x.Run()
+
+	Z.ErrorOnDeprecated = true
+	defer func() { Z.ErrorOnDeprecated = false }()
+	x.Run()
+
+	// Output:
+	// push - (deprecated)
+	// warning: "push" is deprecated: use sync instead
+	// warning: "old" is deprecated: use new instead
+	// pushed [old]
+	// "push" is deprecated: use sync instead
+	// "old" is deprecated: use new instead
+}
@@ -0,0 +1,129 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// DescribeVersion is the version of the DescribeDoc schema (its
+// "bonzai" field) and is incremented whenever fields are changed or
+// removed (but not when added) so that consumers can detect it.
+const DescribeVersion = 1
+
+// DescribeDoc is the stable machine-readable description of a single
+// command returned by Describe.
+type DescribeDoc struct {
+	Bonzai     int            `json:"bonzai"` // DescribeVersion
+	Name       string         `json:"name"`
+	Path       string         `json:"path"` // PathString (empty for root)
+	Aliases    []string       `json:"aliases,omitempty"`
+	Summary    string         `json:"summary,omitempty"`
+	Usage      string         `json:"usage"` // full invocation
+	Callable   bool           `json:"callable"`
+	Deprecated string         `json:"deprecated,omitempty"`
+	Params     DescribeParams `json:"params"`
+	Flags      []Flag         `json:"flags,omitempty"`
+	EnvVars    []EnvVar       `json:"envvars,omitempty"`
+	ConfKeys   []ConfKey      `json:"confkeys,omitempty"`
+	Examples   []Example      `json:"examples,omitempty"`
+	Commands   []string       `json:"commands,omitempty"` // paths
+	Hidden     []string       `json:"hidden,omitempty"`   // paths
+}
+
+// DescribeParams are the Params of a DescribeDoc with MinParm,
+// MaxParm, StrictParams, Excl, and Needs.
+type DescribeParams struct {
+	Names  []string            `json:"names"`
+	Min    int                 `json:"min"`
+	Max    int                 `json:"max"`
+	Strict bool                `json:"strict,omitempty"`
+	Excl   [][]string          `json:"excl,omitempty"`
+	Needs  map[string][]string `json:"needs,omitempty"`
+}
+
+// Describe returns the DescribeDoc for the Cmd (which must have its
+// Callers set, see Seek). The Usage is the full invocation (names from
+// the root followed by the usage of the Cmd, see UsageFunc) as are the
+// Examples (see Invocation). The Flags include the DryRunFlag and
+// FormatFlag if supported. Commands and Hidden contain the PathString
+// of each of the Commands that is visible or hidden (see IsHidden).
+func Describe(x *Cmd) DescribeDoc {
+	path := x.PathString()
+	names := append([]string{x.Root().Name}, x.Path()...)
+	d := DescribeDoc{
+		Bonzai:     DescribeVersion,
+		Name:       x.Name,
+		Path:       path,
+		Aliases:    x.Aliases,
+		Summary:    x.Summary,
+		Usage:      strings.TrimSpace(strings.Join(names, " ") + " " + usageOf(x)),
+		Callable:   x.Callable(),
+		Deprecated: x.Deprecated,
+		Params:     DescribeParams{Names: x.Params, Min: x.MinParm, Max: x.MaxParm},
+		Flags:      x.docFlags(),
+		EnvVars:    x.EnvVars,
+		ConfKeys:   x.ConfKeys,
+	}
+	d.Params.Strict = x.StrictParams
+	d.Params.Excl, d.Params.Needs = x.Excl, x.Needs
+	if d.Params.Names == nil {
+		d.Params.Names = []string{}
+	}
+	for _, e := range x.Examples {
+		d.Examples = append(d.Examples, Example{x.Invocation(e.Cmd), e.Note})
+	}
+	for _, c := range x.AllCommands() {
+		if c.Name == "" {
+			continue
+		}
+		p := c.Name
+		if path != "" {
+			p = path + "." + c.Name
+		}
+		if x.IsHidden(c.Name) {
+			d.Hidden = append(d.Hidden, p)
+			continue
+		}
+		d.Commands = append(d.Commands, p)
+	}
+	return d
+}
+
+// DescribeCmd is an optional hidden builtin leaf command (see Builtins)
+// that prints the DescribeDoc (see Describe) of the command at the
+// dotted path (see PathString) passed as the only argument (or of the
+// root if none) as indented JSON.
+var DescribeCmd = &Cmd{
+	Name:    `describe`,
+	Summary: `print the JSON description of a command`,
+	Usage:   `[PATH]`,
+	Hide:    true,
+	Call: func(x *Cmd, args ...string) error {
+		if len(args) > 1 {
+			return x.UsageError()
+		}
+		cur := x.Root()
+		if len(args) > 0 {
+			for _, name := range strings.Split(args[0], ".") {
+				if name == "" {
+					continue
+				}
+				next := cur.Resolve(name)
+				if next == nil {
+					return fmt.Errorf("command not found: %v", args[0])
+				}
+				cur = bind(next, cur)
+			}
+		}
+		buf, err := json.MarshalIndent(Describe(cur), "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(buf))
+		return nil
+	},
+}
@@ -0,0 +1,93 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+// describeTree adds what is described to db and migrate in the shared
+// tree (see fooTree) along with a hidden repair command.
+func describeTree() *Z.Cmd {
+	x := fooTree()
+	db := x.Commands[0]
+	db.EnvVars = []Z.EnvVar{{Name: `DB_URL`, Summary: `where`, Required: true}}
+	db.ConfKeys = []Z.ConfKey{{Key: `timeout`, Default: `30s`}}
+	migrate := db.Commands[0]
+	migrate.MinParm = 1
+	migrate.MaxParm = 1
+	migrate.SupportsDryRun = true
+	migrate.Examples = []Z.Example{{`up`, `apply all`}}
+	repair := &Z.Cmd{Name: `repair`, Hide: true, Call: migrate.Call}
+	db.Commands = append(db.Commands, repair)
+	return x
+}
+
+func TestDescribe(t *testing.T) {
+	x := describeTree()
+	db := x.Commands[0]
+	db.Caller = x
+	for _, c := range db.Commands {
+		c.Caller = db
+	}
+	for _, name := range []string{"db", "migrate"} {
+		c := db
+		if name == "migrate" {
+			c = db.Commands[0]
+		}
+		doc := Z.Describe(c)
+		buf, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := Z.TestGolden("testdata/describe."+name+".golden", string(buf)+"\n"); err != nil {
+			t.Error(err)
+		}
+		var back Z.DescribeDoc
+		if err := json.Unmarshal(buf, &back); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(back, doc) {
+			t.Errorf("round trip differs:\n%+v\n%+v", back, doc)
+		}
+	}
+}
+
+func ExampleDescribeCmd() {
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	orig := os.Args
+	defer func() { os.Args = orig }()
+	x := describeTree()
+	x.Commands = append(x.Commands, Z.DescribeCmd)
+
+	os.Args = []string{"foo", "describe", "d.repair"}
+	x.Run()
+
+	// Output:
+	// {
+	//   "bonzai": 1,
+	//   "name": "repair",
+	//   "path": "db.repair",
+	//   "usage": "foo db repair",
+	//   "callable": true,
+	//   "params": {
+	//     "names": [],
+	//     "min": 0,
+	//     "max": 0
+	//   }
+	// }
+}
+
+func ExampleDescribe() {
+	doc := Z.Describe(describeTree())
+	fmt.Println(doc.Bonzai, doc.Path == "", doc.Usage, doc.Commands)
+	// Output:
+	// 1 true foo (d|db) [db]
+}
@@ -0,0 +1,88 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"errors"
+	"strings"
+)
+
+// DryRunFlag is recognized by every Cmd that supports it (see
+// SupportsDryRun, which applies to all the subcommands of a Cmd as
+// well) and is extracted from the arguments by Run just like the
+// BuiltinFlags (unless NoBuiltinFlags or the Cmd declares a flag of its
+// own with the same name). Passing it to any other Cmd is an error. It
+// is included in the Flags listed in usage and documentation (see
+// UsageFlags) and completed only where supported.
+var DryRunFlag = Flag{Name: "dry-run", Summary: "show what would be done without doing it"}
+
+// DryRun returns true if the DryRunFlag was passed. Commands that
+// mutate anything should check it and report what they would have done
+// instead. Exec, Out, Pipe, PipeOut, and SysExec (and their Context
+// forms) do so automatically by printing the command (or pipeline) that
+// would have been executed (see DryRunLine).
+func (x *Cmd) DryRun() bool { return x.passed().dryrun }
+
+// dryRunOK returns true if the Cmd or any of its Callers has set
+// SupportsDryRun.
+func (x *Cmd) dryRunOK() bool {
+	for c := x; c != nil; c = c.Caller {
+		if c.SupportsDryRun {
+			return true
+		}
+	}
+	return false
+}
+
+// checkDryRun returns an error if the DryRunFlag was passed to a Cmd
+// that does not support it.
+func (x *Cmd) checkDryRun(f runFlags) error {
+	if f.dryrun && !x.dryRunOK() {
+		return errors.New(Msg(`flag-unsupported`, x.logPath(), DryRunFlag.Name))
+	}
+	return nil
+}
+
+// docFlags returns the Flags of the Cmd followed by the DryRunFlag and
+// FormatFlag (with the Formats and default) if supported and not
+// already declared.
+func (x *Cmd) docFlags() []Flag {
+	declared := map[string]bool{}
+	for _, f := range x.Flags {
+		declared[f.Name] = true
+	}
+	flags := x.Flags
+	if x.dryRunOK() && !declared[DryRunFlag.Name] {
+		flags = append(append([]Flag{}, flags...), DryRunFlag)
+	}
+	if formats, _ := x.formats(); len(formats) > 0 && !declared[FormatFlag.Name] {
+		f := FormatFlag
+		f.Summary += " (" + strings.Join(formats, ", ") + ")"
+		f.Default = formats[0]
+		flags = append(append([]Flag{}, flags...), f)
+	}
+	return flags
+}
+
+// DryRunLine returns the arguments joined by spaces with any containing
+// anything other than letters, digits, or -_./:=@%+, quoted for a POSIX
+// shell so that the line can be copied and run.
+func DryRunLine(args ...string) string {
+	out := make([]string, len(args))
+	for i, a := range args {
+		out[i] = a
+		if a == "" || strings.IndexFunc(a, unsafeShellRune) >= 0 {
+			out[i] = shQuote(a)
+		}
+	}
+	return strings.Join(out, " ")
+}
+
+func unsafeShellRune(r rune) bool {
+	switch {
+	case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9':
+		return false
+	}
+	return !strings.ContainsRune("-_./:=@%+,", r)
+}
@@ -0,0 +1,103 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func ExampleCmd_DryRun() {
+	defer logErrs()()
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	defer log.SetOutput(os.Stderr)
+	defer log.SetFlags(log.Flags())
+	log.SetOutput(os.Stdout)
+	log.SetFlags(0)
+	orig := os.Args
+	defer func() { os.Args = orig }()
+
+	x := &Z.Cmd{
+		Name: `foo`,
+		Commands: []*Z.Cmd{
+			&Z.Cmd{
+				Name:           `deploy`,
+				SupportsDryRun: true,
+				Commands: []*Z.Cmd{
+					&Z.Cmd{
+						Name: `push`,
+						Call: func(x *Z.Cmd, args ...string) error {
+							fmt.Println("dry run:", x.DryRun())
+							return Z.Exec("__inoexist", "push", "a message", args[0])
+						},
+					},
+				},
+			},
+			&Z.Cmd{
+				Name: `status`,
+				Call: func(_ *Z.Cmd, _ ...string) error {
+					fmt.Println("status")
+					return nil
+				},
+			},
+		},
+	}
+
+	os.Args = []string{"foo", "deploy", "--dry-run", "push", "it's"}
+	x.Run()
+
+	os.Args = []string{"foo", "--dry-run", "status"}
+	x.Run()
+
+	os.Args = []string{"foo", "status"}
+	x.Run()
+
+	// Output:
+	// dry run: true
+	// __inoexist push 'a message' 'it'\''s'
+	// status does not support --dry-run
+	// status
+}
+
+func ExampleDryRunFlag() {
+	x := &Z.Cmd{
+		Name:           `foo`,
+		SupportsDryRun: true,
+		Flags:          []Z.Flag{{Name: `all`, Short: `a`, Summary: `everything`}},
+	}
+	fmt.Print(x.UsageFlags())
+	// Output:
+	// -a, --all - everything
+	// --dry-run - show what would be done without doing it
+}
+
+func ExampleCmd_Run_dryrun_completion() {
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	defer os.Unsetenv("COMP_LINE")
+	x := &Z.Cmd{
+		Name: `foo`,
+		Commands: []*Z.Cmd{
+			&Z.Cmd{Name: `deploy`, SupportsDryRun: true, Call: func(*Z.Cmd, ...string) error { return nil }},
+			&Z.Cmd{Name: `status`, Call: func(*Z.Cmd, ...string) error { return nil }},
+		},
+	}
+
+	os.Setenv("COMP_LINE", "foo deploy --d")
+	x.Run()
+
+	os.Setenv("COMP_LINE", "foo status --d")
+	x.Run()
+
+	// Output:
+	// --dry-run
+}
+
+func ExampleDryRunLine() {
+	fmt.Println(Z.DryRunLine("git", "commit", "-m", "fix it", "--author=me@x.org", ""))
+	// Output:
+	// git commit -m 'fix it' --author=me@x.org ''
+}
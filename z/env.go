@@ -0,0 +1,124 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// EnvVar describes an environment variable used by a Cmd (see EnvVars
+// and Env). Required variables that are unset (and have no Default)
+// cause Run to exit with an error before calling the Cmd.
+type EnvVar struct {
+	Name     string `json:"name"`
+	Summary  string `json:"summary,omitempty"`
+	Default  string `json:"default,omitempty"`
+	Required bool   `json:"required,omitempty"`
+}
+
+// EnvPrefix is prepended to all names returned by EnvName. It is
+// usually set once at init() time to the uppercase name of the tree
+// followed by an underscore (ex: MYAPP_).
+var EnvPrefix string
+
+// EnvName returns the conventional name of an environment variable for
+// the Cmd: the EnvPrefix followed by the PathString in uppercase (with
+// dots and dashes replaced by underscores and a trailing underscore)
+// and then the name (ex: MYAPP_DB_MIGRATE_URL). Using EnvName for
+// EnvVars prevents composed commands from colliding.
+func (x *Cmd) EnvName(name string) string {
+	path := envWord(x.PathString())
+	if path != "" {
+		path += "_"
+	}
+	return EnvPrefix + path + name
+}
+
+// envWord returns s in uppercase with dots and dashes replaced by
+// underscores (ex: db.dry-run becomes DB_DRY_RUN).
+func envWord(s string) string {
+	return strings.NewReplacer(".", "_", "-", "_").Replace(strings.ToUpper(s))
+}
+
+// Env returns the value of the named environment variable or the
+// Default of the EnvVar by that name declared by the Cmd or the nearest
+// of its Callers (if any) when it is unset or empty.
+func (x *Cmd) Env(name string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	for c := x; c != nil; c = c.Caller {
+		for _, e := range c.EnvVars {
+			if e.Name == name {
+				return e.Default
+			}
+		}
+	}
+	return ""
+}
+
+// checkEnv returns an error naming every Required EnvVar of the Cmd
+// that is unset or empty and has no Default.
+func (x *Cmd) checkEnv() error {
+	var missing []string
+	for _, e := range x.EnvVars {
+		if e.Required && e.Default == "" && os.Getenv(e.Name) == "" {
+			missing = append(missing, e.Name)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%v requires environment variables: %v",
+		x.logPath(), strings.Join(missing, ", "))
+}
+
+// UsageEnv returns a single string with the Name of each of the
+// EnvVars (one per line) aligned and followed by its Summary, Default,
+// and whether it is Required similar to UsageFlags. An empty string is
+// returned if there are no EnvVars.
+func (x *Cmd) UsageEnv() string {
+	var names, summaries []string
+	for _, e := range x.EnvVars {
+		names = append(names, e.Name)
+		summaries = append(summaries, docSummary(e.Summary, e.Default, e.Required))
+	}
+	return usageTable(names, summaries)
+}
+
+// usageTable returns the names (one per line) aligned and followed by
+// their summaries (if any) with hanging indentation.
+func usageTable(names, summaries []string) string {
+	var longest int
+	for _, n := range names {
+		if w := Width(n); w > longest {
+			longest = w
+		}
+	}
+	var buf string
+	for i, n := range names {
+		pad := strings.Repeat(" ", longest-Width(n))
+		name := Styled(os.Stdout, Style.Param, n)
+		if summaries[i] == "" {
+			buf += name + "\n"
+			continue
+		}
+		buf += name + pad + " - " + Hanging(summaries[i], Columns, longest+3) + "\n"
+	}
+	return buf
+}
+
+// docSummary returns the summary followed by the default (if any) and
+// whether it is required for documentation.
+func docSummary(summary, def string, required bool) string {
+	if def != "" {
+		summary += " (default: " + def + ")"
+	}
+	if required {
+		summary += " (required)"
+	}
+	return strings.TrimSpace(summary)
+}
@@ -0,0 +1,97 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func envTree() *Z.Cmd {
+	migrate := &Z.Cmd{
+		Name: `migrate`,
+		EnvVars: []Z.EnvVar{
+			{Name: `FOO_URL`, Summary: `database URL`, Required: true},
+			{Name: `FOO_STEPS`, Summary: `steps to take`, Default: `1`},
+		},
+		Call: func(x *Z.Cmd, _ ...string) error {
+			fmt.Println(x.Env(`FOO_URL`), x.Env(`FOO_STEPS`), x.Env(`FOO_DB`))
+			return nil
+		},
+	}
+	return &Z.Cmd{
+		Name: `foo`,
+		Commands: []*Z.Cmd{
+			{
+				Name:     `db-tool`,
+				EnvVars:  []Z.EnvVar{{Name: `FOO_DB`, Default: `main`}},
+				Commands: []*Z.Cmd{migrate},
+			},
+		},
+	}
+}
+
+func ExampleCmd_Env() {
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	orig := os.Args
+	defer func() { os.Args = orig }()
+	defer os.Unsetenv(`FOO_URL`)
+	defer os.Unsetenv(`FOO_STEPS`)
+
+	os.Setenv(`FOO_URL`, `db://here`)
+	os.Args = []string{"foo", "db-tool", "migrate"}
+	envTree().Run()
+
+	os.Setenv(`FOO_STEPS`, `3`)
+	envTree().Run()
+
+	// Output:
+	// db://here 1 main
+	// db://here 3 main
+}
+
+func ExampleCmd_Run_env_required() {
+	defer logErrs()()
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	defer log.SetOutput(os.Stderr)
+	defer log.SetFlags(log.Flags())
+	log.SetOutput(os.Stdout)
+	log.SetFlags(0)
+	orig := os.Args
+	defer func() { os.Args = orig }()
+	os.Unsetenv(`FOO_URL`)
+
+	os.Args = []string{"foo", "db-tool", "migrate"}
+	envTree().Run()
+
+	// Output:
+	// db-tool.migrate requires environment variables: FOO_URL
+}
+
+func ExampleCmd_EnvName() {
+	defer func(p string) { Z.EnvPrefix = p }(Z.EnvPrefix)
+	Z.EnvPrefix = `FOO_`
+	x := envTree()
+	migrate, _ := x.Seek([]string{"db-tool", "migrate"})
+	fmt.Println(x.EnvName(`URL`))
+	fmt.Println(migrate.Caller.EnvName(`URL`))
+	fmt.Println(migrate.EnvName(`URL`))
+
+	// Output:
+	// FOO_URL
+	// FOO_DB_TOOL_URL
+	// FOO_DB_TOOL_MIGRATE_URL
+}
+
+func ExampleCmd_UsageEnv() {
+	x := envTree()
+	fmt.Print(x.Commands[0].Commands[0].UsageEnv())
+
+	// Output:
+	// FOO_URL   - database URL (required)
+	// FOO_STEPS - steps to take (default: 1)
+}
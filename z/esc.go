@@ -1,6 +1,6 @@
 package Z
 
-import "github.com/rwxrob/fn"
+import "strings"
 
 // EscThese is set to the default UNIX shell characters which require
 // escaping to be used safely on the terminal. It can be changed to suit
@@ -23,4 +23,32 @@ func Esc(s string) string {
 }
 
 // EscAll calls Esc on all passed strings.
-func EscAll(args []string) []string { return fn.Map(args, Esc) }
+func EscAll(args []string) []string {
+	list := []string{}
+	for _, a := range args {
+		list = append(list, Esc(a))
+	}
+	return list
+}
+
+// EscCompThese are the characters escaped by EscCompletion, which
+// includes all of EscThese along with the quotes, backslash, and
+// expansion characters of bash.
+var EscCompThese = EscThese + "'\"\\$`*?{}~#"
+
+// EscCompletion returns the completion candidate escaped with
+// backslashes (see EscCompThese) so that bash (which inserts the
+// candidates of "complete -C" as is) reconstructs exactly the original
+// word. Every candidate printed for bash is escaped this way. Custom
+// Completers do not need to call it unless they print candidates
+// themselves.
+func EscCompletion(s string) string {
+	var buf []rune
+	for _, r := range s {
+		if strings.ContainsRune(EscCompThese, r) {
+			buf = append(buf, '\\')
+		}
+		buf = append(buf, r)
+	}
+	return string(buf)
+}
@@ -4,10 +4,12 @@
 package Z
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
-	"syscall"
+	"strings"
 )
 
 // SysExec will check for the existence of the first argument as an
@@ -17,17 +19,25 @@ import (
 // Generally speaking, this is only available on UNIX variations.  This
 // is exceptionally faster and cleaner than calling any of the os/exec
 // variations, but it can make your code far be less compatible
-// with different operating systems.
+// with different operating systems. Elsewhere, SysExec falls back to
+// Exec followed by exiting with the same exit code. Any functions
+// registered with AtExit are called first. During a dry run (see
+// DryRun) the command is printed instead (see DryRunLine).
 func SysExec(args ...string) error {
 	if len(args) == 0 {
 		return fmt.Errorf("missing name of executable")
 	}
+	if lastFlags().dryrun {
+		fmt.Println(DryRunLine(args...))
+		return nil
+	}
 	path, err := exec.LookPath(args[0])
 	if err != nil {
 		return err
 	}
+	runAtExit()
 	// exits the program unless there is an error
-	return syscall.Exec(path, args, os.Environ())
+	return sysexec(path, args)
 }
 
 // Exec checks for existence of first argument as an executable on the
@@ -35,18 +45,91 @@ func SysExec(args ...string) error {
 // across all architectures that Go supports. The stdin, stdout, and stderr are
 // connected directly to that of the calling program. Sometimes this is
 // insufficient and the UNIX-specific SysExec is preferred. For example,
-// when handing over control to a terminal editor such as Vim.
+// when handing over control to a terminal editor such as Vim. Errors
+// are prefixed with the name of the executable (ex: "git: exit status
+// 128"). During a dry run (see DryRun) the command is printed to
+// standard output instead of being executed (see DryRunLine).
 func Exec(args ...string) error {
-	if len(args) == 0 {
-		return fmt.Errorf("missing name of executable")
+	return ExecContext(context.Background(), args...)
+}
+
+// ExecContext is the same as Exec but kills the executable if the
+// context is done before it completes.
+func ExecContext(ctx context.Context, args ...string) error {
+	if lastFlags().dryrun && len(args) > 0 {
+		fmt.Println(DryRunLine(args...))
+		return nil
 	}
-	path, err := exec.LookPath(args[0])
+	cmd, err := command(ctx, args)
 	if err != nil {
 		return err
 	}
-	cmd := exec.Command(path, args[1:]...)
 	cmd.Stdout = os.Stdout
 	cmd.Stdin = os.Stdin
 	cmd.Stderr = os.Stderr
-	return cmd.Run()
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%v: %w", args[0], err)
+	}
+	return nil
+}
+
+// Out is the same as Exec but returns the standard output (with leading
+// and trailing white space trimmed) instead. Standard error is still
+// connected to that of the calling program. During a dry run (see
+// DryRun) the command is printed instead (as with Exec) and an empty
+// string is returned. Use os/exec directly for anything whose output is
+// needed even during a dry run.
+func Out(args ...string) (string, error) {
+	return OutContext(context.Background(), args...)
+}
+
+// OutContext is the same as Out but kills the executable if the
+// context is done before it completes.
+func OutContext(ctx context.Context, args ...string) (string, error) {
+	if lastFlags().dryrun && len(args) > 0 {
+		fmt.Println(DryRunLine(args...))
+		return "", nil
+	}
+	cmd, err := command(ctx, args)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return strings.TrimSpace(out.String()), fmt.Errorf("%v: %w", args[0], err)
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// command looks up the executable (see exec.LookPath) returning the
+// exec.Cmd for it.
+func command(ctx context.Context, args []string) (*exec.Cmd, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("missing name of executable")
+	}
+	path, err := exec.LookPath(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return exec.CommandContext(ctx, path, args[1:]...), nil
+}
+
+// InPath returns true if every one of the names is an executable found
+// in the PATH (see exec.LookPath, which also considers PATHEXT on
+// Windows).
+func InPath(names ...string) bool { return len(MissingFromPath(names...)) == 0 }
+
+// MissingFromPath returns the names that are not executables found in
+// the PATH (see InPath).
+func MissingFromPath(names ...string) []string {
+	var missing []string
+	for _, n := range names {
+		if _, err := exec.LookPath(n); err != nil {
+			missing = append(missing, n)
+		}
+	}
+	return missing
 }
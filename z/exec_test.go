@@ -4,9 +4,14 @@
 package Z
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"os/exec"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 // go coverage detection is fucked for this sort of stuff, oh well, we
@@ -54,3 +59,48 @@ func TestExec(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+// TestHelperProcess is not a real test but is used as an external
+// executable by the other tests (when TESTING_HELPER is set).
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("TESTING_HELPER") != "1" {
+		return
+	}
+	fmt.Println("  helper output  ")
+	if os.Getenv("TESTING_HELPER_SLEEP") == "1" {
+		time.Sleep(10 * time.Second)
+	}
+	code, _ := strconv.Atoi(os.Getenv("TESTING_HELPER_EXIT"))
+	os.Exit(code)
+}
+
+func TestOut(t *testing.T) {
+	t.Setenv("TESTING_HELPER", "1")
+	out, err := Out(os.Args[0], "-test.run=TestHelperProcess")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "helper output" {
+		t.Errorf("unexpected output: %q", out)
+	}
+}
+
+func TestExec_exitStatus(t *testing.T) {
+	t.Setenv("TESTING_HELPER", "1")
+	t.Setenv("TESTING_HELPER_EXIT", "3")
+	_, err := Out(os.Args[0], "-test.run=TestHelperProcess")
+	if err == nil || !strings.HasSuffix(err.Error(), "exit status 3") {
+		t.Errorf("should have failed with exit status: %v", err)
+	}
+}
+
+func TestOutContext(t *testing.T) {
+	t.Setenv("TESTING_HELPER", "1")
+	t.Setenv("TESTING_HELPER_SLEEP", "1")
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := OutContext(ctx, os.Args[0], "-test.run=TestHelperProcess")
+	if err == nil {
+		t.Error("should have been killed")
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"os"
+	"sync"
+)
+
+// ExitPanic is the exit code used by TrapPanic (EX_SOFTWARE from
+// sysexits.h) so that crashes can be told apart from errors (1).
+const ExitPanic = 70
+
+// ExitUsage is the exit code used by ExitError for errors in the
+// arguments passed to a command (see ArgError) rather than in what it
+// does (1) following the convention of most UNIX commands.
+const ExitUsage = 2
+
+// ExitTimeout is the exit code used by Run when the Call of a command
+// does not return within its Timeout (the same as timeout(1)).
+const ExitTimeout = 124
+
+// Exiter is called by Exit, ExitError, TrapPanic, and the rest with the
+// exit code after any functions registered with AtExit (see
+// DefaultExiter).
+type Exiter interface {
+	Exit(code int)
+}
+
+// DefaultExiter is the Exiter used to end the process (OSExiter by
+// default). It is ignored while DoNotExit is set (which is the same as
+// assigning NopExiter). Tests should assign a RecordingExiter with
+// SetExiter rather than setting DoNotExit (see ExitOff):
+//
+//     rec := new(Z.RecordingExiter)
+//     defer Z.SetExiter(rec)()
+//     x.Run()
+//     fmt.Println(rec.Last())
+var DefaultExiter Exiter = OSExiter{}
+
+// SetExiter assigns DefaultExiter and returns a function that restores
+// the previous one (usually deferred).
+func SetExiter(e Exiter) (restore func()) {
+	prev := DefaultExiter
+	DefaultExiter = e
+	return func() { DefaultExiter = prev }
+}
+
+// OSExiter calls os.Exit.
+type OSExiter struct{}
+
+// Exit fulfills the Exiter interface.
+func (OSExiter) Exit(code int) { os.Exit(code) }
+
+// NopExiter does nothing at all (see DoNotExit).
+type NopExiter struct{}
+
+// Exit fulfills the Exiter interface.
+func (NopExiter) Exit(code int) {}
+
+// RecordingExiter records every exit code instead of exiting and is
+// safe for concurrent use.
+type RecordingExiter struct {
+	mu    sync.Mutex
+	codes []int
+}
+
+// Exit fulfills the Exiter interface.
+func (r *RecordingExiter) Exit(code int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.codes = append(r.codes, code)
+}
+
+// Codes returns every exit code recorded in order.
+func (r *RecordingExiter) Codes() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]int{}, r.codes...)
+}
+
+// Last returns the last exit code recorded or -1 if there is none.
+func (r *RecordingExiter) Last() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.codes) == 0 {
+		return -1
+	}
+	return r.codes[len(r.codes)-1]
+}
@@ -0,0 +1,59 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func ExampleRecordingExiter() {
+	defer logErrs()()
+	defer log.SetOutput(os.Stderr)
+	defer log.SetFlags(log.Flags())
+	log.SetOutput(os.Stdout)
+	log.SetFlags(0)
+	orig := os.Args
+	defer func() { os.Args = orig }()
+
+	rec := new(Z.RecordingExiter)
+	defer Z.SetExiter(rec)()
+	fmt.Println(rec.Last())
+
+	x := &Z.Cmd{
+		Name: `foo`,
+		Call: func(_ *Z.Cmd, args ...string) error {
+			if len(args) > 0 {
+				return errors.New("failed")
+			}
+			return nil
+		},
+	}
+	os.Args = []string{"foo"}
+	x.Run()
+	os.Args = []string{"foo", "bar"}
+	x.Run()
+	fmt.Println(rec.Codes(), rec.Last())
+
+	// Output:
+	// -1
+	// failed
+	// [0 1] 1
+}
+
+func ExampleSetExiter() {
+	rec := new(Z.RecordingExiter)
+	restore := Z.SetExiter(rec)
+	fmt.Println(Z.DefaultExiter == Z.Exiter(rec))
+	restore()
+	fmt.Println(Z.DefaultExiter == Z.Exiter(Z.OSExiter{}))
+
+	// Output:
+	// true
+	// true
+}
@@ -0,0 +1,88 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// DocFuncs may be assigned additional text/template functions to be
+// made available to all documentation strings (see Fill). Tree
+// composers should add to it at init() time. Functions with the same
+// name as those provided by Fill itself (pathto) override them.
+var DocFuncs = template.FuncMap{}
+
+// DocData is the data passed to the text/template when filling in
+// documentation strings (see Fill).
+type DocData struct {
+	ExeName string // ExeName of the running executable
+	Name    string // Name of the Cmd
+	Path    string // full invocation (ex: foo db migrate)
+	Version string // Version of the Cmd (or of its Root if empty)
+}
+
+// Fill executes the string as a text/template with DocData for the Cmd
+// and the DocFuncs returning the result. It is used at render time for
+// the Description and each Other Body (see GetDescription and GetOther)
+// so that documentation can refer to the actual executable name and
+// command path no matter which tree the Cmd has been composed into.
+// In addition to DocFuncs the following function is always available:
+//
+//     pathto "db.migrate" - full invocation of dotted path from Root
+//
+// Malformed templates (or those that fail to execute) are logged as
+// a warning and the string is returned unchanged. Strings without any
+// template actions are returned as is.
+func (x *Cmd) Fill(in string) string {
+	if !strings.Contains(in, "{{") {
+		return in
+	}
+	funcs := template.FuncMap{
+		"pathto": func(path string) (string, error) {
+			cur := x.Root()
+			names := []string{cur.Name}
+			for _, n := range strings.Split(path, ".") {
+				if n == "" {
+					continue
+				}
+				if cur = cur.Resolve(n); cur == nil {
+					return "", fmt.Errorf("pathto: command not found: %q", path)
+				}
+				names = append(names, cur.Name)
+			}
+			return strings.Join(names, " "), nil
+		},
+	}
+	for k, v := range DocFuncs {
+		funcs[k] = v
+	}
+	t, err := template.New(x.Name).Funcs(funcs).Parse(in)
+	if err != nil {
+		logAt(LevelWarn, "", err.Error())
+		return in
+	}
+	var out strings.Builder
+	if err := t.Execute(&out, x.DocData()); err != nil {
+		logAt(LevelWarn, "", err.Error())
+		return in
+	}
+	return out.String()
+}
+
+// DocData returns the data used to Fill the documentation of the Cmd.
+func (x *Cmd) DocData() DocData {
+	path := append([]string{x.Root().Name}, x.Path()...)
+	version := x.Version
+	if version == "" {
+		version = x.Root().Version
+	}
+	return DocData{
+		ExeName: ExeName,
+		Name:    x.Name,
+		Path:    strings.Join(path, " "),
+		Version: version,
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func ExampleCmd_Fill() {
+	Z.DocFuncs["upper"] = strings.ToUpper
+	defer delete(Z.DocFuncs, "upper")
+
+	migrate := &Z.Cmd{
+		Name: `migrate`,
+		Description: `
+			Run {{.Path}} ({{.Version}}) after {{pathto "db.init"}}.
+			Also see {{upper .Name}}.
+			`,
+		Other: []Z.Section{{`Broken`, `Unclosed {{.Name`}},
+	}
+	x := &Z.Cmd{
+		Name:    `foo`,
+		Version: `v1.0.0`,
+		Commands: []*Z.Cmd{
+			&Z.Cmd{
+				Name:     `db`,
+				Commands: []*Z.Cmd{migrate, &Z.Cmd{Name: `init`}},
+			},
+		},
+	}
+	x.Seek([]string{"db", "migrate"})
+
+	defer log.SetOutput(os.Stderr)
+	defer log.SetFlags(log.Flags())
+	log.SetOutput(os.Stdout)
+	log.SetFlags(0)
+
+	fmt.Println(strings.TrimSpace(migrate.GetDescription()))
+	fmt.Println(migrate.GetOther()[0].GetBody())
+
+	// Output:
+	// Run foo db migrate (v1.0.0) after foo db init.
+	// 			Also see MIGRATE.
+	// warning: template: migrate:1: unclosed action
+	// Unclosed {{.Name
+}
@@ -0,0 +1,277 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rwxrob/bonzai/comp"
+)
+
+// Flag describes one of the optional Flags of a Cmd. Flags are entirely
+// opt-in. Trees without any Flags are parsed exactly as before.
+type Flag struct {
+	Name    string `json:"name"`              // long form (ex: output for --output)
+	Short   string `json:"short,omitempty"`   // single letter (ex: o for -o)
+	Default string `json:"default,omitempty"` // returned by Cmd.Flag if not set
+	Value   bool   `json:"value,omitempty"`   // takes a value (otherwise bool)
+	Summary string `json:"summary,omitempty"`
+}
+
+// Usage returns the flag in usage notation (ex: -o, --output OUTPUT).
+func (f Flag) Usage() string {
+	u := "--" + f.Name
+	if f.Short != "" {
+		u = "-" + f.Short + ", " + u
+	}
+	if f.Value {
+		u += " " + strings.ToUpper(f.Name)
+	}
+	return u
+}
+
+// Flag returns the value of the named flag from the Flags of the Cmd or
+// the nearest of its Callers that declares it. If the flag was not
+// passed its Default is returned. Flags without a Value are "true" when
+// passed. An empty string is returned if no such flag is declared.
+// Flags are extracted from the arguments by Run before seeking the
+// command to call. They may appear anywhere after the command that
+// declares them (or any of its subcommands) but before the first
+// positional argument. A double dash (--) ends the flags. Both the
+// --name=value and --name value forms (and the same for -s short
+// forms) are supported. Unrecognized flags are left alone.
+func (x *Cmd) Flag(name string) string {
+	for c := x; c != nil; c = c.Caller {
+		for _, f := range c.Flags {
+			if f.Name != name {
+				continue
+			}
+			if v, has := c._flags[name]; has {
+				return v
+			}
+			return f.Default
+		}
+	}
+	return ""
+}
+
+// FlagBool returns the Flag parsed as a bool (see strconv.ParseBool)
+// or false if it cannot be parsed.
+func (x *Cmd) FlagBool(name string) bool {
+	b, _ := strconv.ParseBool(x.Flag(name))
+	return b
+}
+
+// FlagInt returns the Flag parsed as an int or 0 if it cannot be
+// parsed.
+func (x *Cmd) FlagInt(name string) int {
+	i, _ := strconv.Atoi(x.Flag(name))
+	return i
+}
+
+// UsageFlags returns a single string with the Usage of each of the
+// Flags (one per line) aligned and followed by its Summary and Default
+// (if any) similar to UsageCmdTitles. The BuiltinFlags are included
+// (last) if BuiltinsInUsage is set as are the DryRunFlag and FormatFlag
+// if supported (see SupportsDryRun and Formats). An empty string is
+// returned if there are no Flags.
+func (x *Cmd) UsageFlags() string {
+	flags := x.docFlags()
+	if BuiltinsInUsage && !x.NoBuiltinFlags {
+		flags = append(append([]Flag{}, flags...), BuiltinFlags...)
+	}
+	var longest int
+	for _, f := range flags {
+		if n := Width(f.Usage()); n > longest {
+			longest = n
+		}
+	}
+	var buf string
+	for _, f := range flags {
+		u := f.Usage()
+		pad := strings.Repeat(" ", longest-Width(u))
+		summary := f.Summary
+		if f.Default != "" {
+			summary = strings.TrimSpace(summary + " (default: " + f.Default + ")")
+		}
+		u = Styled(os.Stdout, Style.Param, u)
+		if summary == "" {
+			buf += u + "\n"
+			continue
+		}
+		buf += u + pad + " - " + Hanging(summary, Columns, longest+3) + "\n"
+	}
+	return buf
+}
+
+// lookupFlag returns the flag matching the argument (--name, -s,
+// --name=value, or -s=value) from the Flags of the commands in scope
+// (last first) along with the Cmd declaring it and any value included
+// in the argument itself.
+func lookupFlag(scope []*Cmd, arg string) (*Flag, *Cmd, string, bool) {
+	name, val, hasval := strings.Cut(arg, "=")
+	for i := len(scope) - 1; i >= 0; i-- {
+		c := scope[i]
+		for n, f := range c.Flags {
+			if name == "--"+f.Name || (f.Short != "" && name == "-"+f.Short) {
+				return &c.Flags[n], c, val, hasval
+			}
+		}
+	}
+	return nil, nil, "", false
+}
+
+// runFlags are the BuiltinFlags, DryRunFlag, and FormatFlag passed to
+// a single Run (or Shell line). The leaf Cmd carries them along with
+// its args (see withFlags) and those of the last one called by Run or
+// Shell are kept for the functions without a Cmd of their own (see
+// setLast).
+type runFlags struct {
+	verbose int
+	quiet   bool
+	dryrun  bool
+	format  string   // passed with FormatFlag (see Format)
+	print   string   // Format of the Cmd (see setLast)
+	args    []string // passed to Call (see Once)
+}
+
+// passed returns the flags passed to the run the Cmd was prepared for
+// (see prepare) or none at all if it was not.
+func (x *Cmd) passed() runFlags {
+	if x == nil || x._run == nil {
+		return runFlags{}
+	}
+	return *x._run
+}
+
+var lastmu sync.Mutex
+var last runFlags
+
+// setLast keeps the flags (and Format) of the Cmd about to be called by
+// Run or Shell for Exec, Pipe, Spinner, Progress, and Table (unless it
+// has a Cmd), which have no Cmd of their own (see lastFlags). RunE
+// never changes them since it may be called concurrently.
+func setLast(x *Cmd) {
+	f := x.passed()
+	f.print = x.Format()
+	lastmu.Lock()
+	last = f
+	lastmu.Unlock()
+}
+
+// lastFlags returns the flags kept by setLast.
+func lastFlags() runFlags {
+	lastmu.Lock()
+	defer lastmu.Unlock()
+	return last
+}
+
+// extractFlags returns the args less any Flags declared by x or the
+// commands that would be sought from the args (see Seek and Flag),
+// which are saved to the declaring Cmd as they are found. Every Cmd
+// along the way has any previously saved flags cleared. The
+// BuiltinFlags are also extracted (unless NoBuiltinFlags) as are the
+// DryRunFlag and FormatFlag and returned separately (see prepare).
+func (x *Cmd) extractFlags(args []string) ([]string, runFlags, error) {
+	x._flags = nil
+	var flags runFlags
+	scope := []*Cmd{x}
+	cur := x
+	out := []string{}
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if strings.HasPrefix(a, "-") && len(a) > 1 {
+			if a == "--" && hasFlags(scope) {
+				return append(out, args[i+1:]...), flags, nil
+			}
+			if f, c, val, hasval := lookupFlag(scope, a); f != nil {
+				switch {
+				case f.Value && !hasval:
+					if i+1 >= len(args) {
+						return nil, flags, errors.New(Msg(`flag-needs-value`, a))
+					}
+					i++
+					val = args[i]
+				case !f.Value && !hasval:
+					val = "true"
+				}
+				if c._flags == nil {
+					c._flags = map[string]string{}
+				}
+				c._flags[f.Name] = val
+				continue
+			}
+			if !cur.NoBuiltinFlags {
+				if builtinFlag(&flags, a) {
+					continue
+				}
+				if a == "--"+DryRunFlag.Name {
+					flags.dryrun = true
+					continue
+				}
+				n, found, err := formatFlag(&flags, args, i)
+				if err != nil {
+					return nil, flags, err
+				}
+				if found {
+					i = n
+					continue
+				}
+			}
+		}
+		next := cur.Resolve(a)
+		if next == nil {
+			return append(out, args[i:]...), flags, nil
+		}
+		next._flags = nil
+		scope = append(scope, next)
+		cur = next
+		out = append(out, a)
+	}
+	return out, flags, nil
+}
+
+func hasFlags(scope []*Cmd) bool {
+	for _, c := range scope {
+		if len(c.Flags) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// flagCandidates returns the long form of every flag available to x
+// (including those of its Callers, the BuiltinFlags, and the
+// DryRunFlag and FormatFlag if supported) beginning with the prefix.
+func (x *Cmd) flagCandidates(pre string) []comp.Candidate {
+	var cands []comp.Candidate
+	scope := []*Cmd{}
+	for c := x; c != nil; c = c.Caller {
+		scope = append(scope, c)
+	}
+	if !x.NoBuiltinFlags {
+		scope = append(scope, &Cmd{Flags: BuiltinFlags})
+	}
+	if x.dryRunOK() {
+		scope = append(scope, &Cmd{Flags: []Flag{DryRunFlag}})
+	}
+	if formats, _ := x.formats(); len(formats) > 0 {
+		scope = append(scope, &Cmd{Flags: []Flag{FormatFlag}})
+	}
+	for _, c := range scope {
+		for _, f := range c.Flags {
+			if long := "--" + f.Name; strings.HasPrefix(long, pre) {
+				cands = append(cands, comp.Candidate{
+					Value:       long,
+					Description: f.Summary,
+				})
+			}
+		}
+	}
+	return cands
+}
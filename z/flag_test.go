@@ -0,0 +1,81 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"fmt"
+	"os"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func flagTree() *Z.Cmd {
+	return &Z.Cmd{
+		Name: `foo`,
+		Flags: []Z.Flag{
+			{Name: `verbose`, Short: `v`, Summary: `say more`},
+		},
+		Commands: []*Z.Cmd{
+			&Z.Cmd{
+				Name: `get`,
+				Flags: []Z.Flag{
+					{Name: `output`, Short: `o`, Value: true, Default: `text`,
+						Summary: `output format`},
+					{Name: `count`, Value: true, Default: `1`},
+				},
+				Call: func(x *Z.Cmd, args ...string) error {
+					fmt.Println(x.FlagBool("verbose"), x.Flag("output"),
+						x.FlagInt("count"), args)
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func ExampleCmd_Flag() {
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	orig := os.Args
+	defer func() { os.Args = orig }()
+	x := flagTree()
+
+	os.Args = []string{"foo", "get", "a", "-v"}
+	x.Run()
+
+	os.Args = []string{"foo", "-v", "get", "--output=json", "--count", "3", "a"}
+	x.Run()
+
+	os.Args = []string{"foo", "get", "-o", "yaml", "--", "--count", "-x"}
+	x.Run()
+
+	// Output:
+	// false text 1 [a -v]
+	// true json 3 [a]
+	// false yaml 1 [--count -x]
+}
+
+func ExampleCmd_UsageFlags() {
+	x := flagTree()
+	fmt.Print(x.Commands[0].UsageFlags())
+	// Output:
+	// -o, --output OUTPUT - output format (default: text)
+	// --count COUNT       - (default: 1)
+}
+
+func ExampleCmd_Run_flags_completion() {
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	orig := os.Getenv("COMP_LINE")
+	defer os.Setenv("COMP_LINE", orig)
+	x := flagTree()
+
+	os.Setenv("COMP_LINE", "foo -v get --o")
+	x.Run()
+
+	os.Setenv("COMP_LINE", "foo --verbose g")
+	x.Run()
+
+	// Output:
+	// --output
+	// get
+}
@@ -0,0 +1,131 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// FormatFlag is recognized by every Cmd with Formats (of its own or of
+// its Callers) and is extracted from the arguments by Run just like the
+// BuiltinFlags (unless NoBuiltinFlags or the Cmd declares a flag of its
+// own with the same name) selecting the Format used by Print. Passing
+// it to any other Cmd (or with a value that is not one of the Formats)
+// is an error. It is included in the Flags listed in usage and
+// documentation (see UsageFlags) along with the Formats and the
+// Formats are completed as its value.
+var FormatFlag = Flag{Name: "format", Value: true, Summary: "output format"}
+
+// Formatters contains the functions used by Print to render a value in
+// each output format (see Formats). The "text" and "json" formats are
+// registered by default. Add others (ex: yaml) as needed.
+var Formatters = map[string]func(w io.Writer, v any) error{
+	"text": func(w io.Writer, v any) error {
+		_, err := fmt.Fprintln(w, v)
+		return err
+	},
+	"json": func(w io.Writer, v any) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	},
+}
+
+// Print writes the value to standard output using the Formatters for
+// the Format of the Cmd falling back to text if there is none or no
+// formatter for it.
+func (x *Cmd) Print(v any) error {
+	f, has := Formatters[x.Format()]
+	if !has {
+		f = Formatters["text"]
+	}
+	return f(os.Stdout, v)
+}
+
+// formats returns the Formats of the Cmd or of the nearest of its
+// Callers that declares any along with its FormatAuto.
+func (x *Cmd) formats() ([]string, bool) {
+	for c := x; c != nil; c = c.Caller {
+		if len(c.Formats) > 0 {
+			return c.Formats, c.FormatAuto
+		}
+	}
+	return nil, false
+}
+
+// Format returns the output format selected with the FormatFlag or, if
+// not passed, the first of the Formats (of the Cmd or the nearest of
+// its Callers declaring any) unless FormatAuto is set, json is one of
+// them, and the Terminal is not interactive, in which case json is
+// returned. An empty string is returned if there are no Formats.
+func (x *Cmd) Format() string {
+	formats, auto := x.formats()
+	switch {
+	case len(formats) == 0:
+		return ""
+	case x.passed().format != "":
+		return x.passed().format
+	case auto && !Term.IsInteractive() && contains(formats, "json"):
+		return "json"
+	}
+	return formats[0]
+}
+
+// checkFormat returns an error if the FormatFlag was passed to a Cmd
+// without Formats or with a value that is not one of them.
+func (x *Cmd) checkFormat(f runFlags) error {
+	formats, _ := x.formats()
+	if format := f.format; format != "" {
+		if len(formats) == 0 {
+			return errors.New(Msg(`flag-unsupported`, x.logPath(), FormatFlag.Name))
+		}
+		if !contains(formats, format) {
+			return errors.New(Msg(`format-unsupported`,
+				format, x.logPath(), strings.Join(formats, ", ")))
+		}
+	}
+	return nil
+}
+
+// formatFlag consumes the FormatFlag from the args at i (with its value
+// from the following argument if not included) into the flags returning
+// the new index and false if it is not the FormatFlag.
+func formatFlag(f *runFlags, args []string, i int) (int, bool, error) {
+	name, val, hasval := strings.Cut(args[i], "=")
+	if name != "--"+FormatFlag.Name {
+		return i, false, nil
+	}
+	if !hasval {
+		if i+1 >= len(args) {
+			return i, true, errors.New(Msg(`flag-needs-value`, args[i]))
+		}
+		i++
+		val = args[i]
+	}
+	f.format = val
+	return i, true, nil
+}
+
+// formatCandidates returns the Formats of x beginning with the prefix
+// (after the FormatFlag itself if included, ex: --format=j).
+func (x *Cmd) formatCandidates(pre string) []string {
+	formats, _ := x.formats()
+	var cands []string
+	flag := ""
+	if strings.HasPrefix(pre, "--"+FormatFlag.Name+"=") {
+		flag = "--" + FormatFlag.Name + "="
+		pre = pre[len(flag):]
+	}
+	for _, f := range formats {
+		if strings.HasPrefix(f, pre) {
+			cands = append(cands, flag+f)
+		}
+	}
+	return cands
+}
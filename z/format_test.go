@@ -0,0 +1,156 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"testing"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func ExamplePrint() {
+	defer logErrs()()
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	defer log.SetOutput(os.Stderr)
+	defer log.SetFlags(log.Flags())
+	log.SetOutput(os.Stdout)
+	log.SetFlags(0)
+	orig := os.Args
+	defer func() { os.Args = orig }()
+
+	x := &Z.Cmd{
+		Name:    `foo`,
+		Formats: []string{`text`, `json`},
+		Commands: []*Z.Cmd{
+			&Z.Cmd{
+				Name: `list`,
+				Call: func(x *Z.Cmd, _ ...string) error {
+					fmt.Println("format:", x.Format())
+					return x.Print(map[string]int{"a": 1, "b": 2})
+				},
+			},
+			&Z.Cmd{
+				Name:    `raw`,
+				Formats: []string{`csv`},
+				Call:    func(*Z.Cmd, ...string) error { return nil },
+			},
+		},
+	}
+
+	os.Args = []string{"foo", "list"}
+	x.Run()
+
+	os.Args = []string{"foo", "list", "--format", "json"}
+	x.Run()
+
+	os.Args = []string{"foo", "--format=yaml", "list"}
+	x.Run()
+
+	os.Args = []string{"foo", "raw", "--format=json"}
+	x.Run()
+
+	os.Args = []string{"foo", "raw"}
+	x.Run()
+
+	// Output:
+	// format: text
+	// map[a:1 b:2]
+	// format: json
+	// {
+	//   "a": 1,
+	//   "b": 2
+	// }
+	// unsupported format "yaml" for list (one of: text, json)
+	// unsupported format "json" for raw (one of: csv)
+}
+
+func ExampleCmd_Format() {
+	defer func(t Z.Terminal) { Z.Term = t }(Z.Term)
+	x := &Z.Cmd{
+		Name:       `foo`,
+		Formats:    []string{`text`, `json`},
+		FormatAuto: true,
+	}
+	Z.Term = stubTerm{}
+	fmt.Println(x.Format())
+	Z.Term = pipeTerm{}
+	fmt.Println(x.Format())
+	fmt.Print(x.UsageFlags())
+	// Output:
+	// text
+	// json
+	// --format FORMAT - output format (text, json) (default: text)
+}
+
+type pipeTerm struct{ stubTerm }
+
+func (pipeTerm) IsInteractive() bool { return false }
+
+func ExampleCmd_Run_format_completion() {
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	defer os.Unsetenv("COMP_LINE")
+	x := &Z.Cmd{
+		Name: `foo`,
+		Commands: []*Z.Cmd{
+			&Z.Cmd{
+				Name:    `list`,
+				Formats: []string{`text`, `json`, `yaml`},
+				Call:    func(*Z.Cmd, ...string) error { return nil },
+			},
+		},
+	}
+
+	os.Setenv("COMP_LINE", "foo list --f")
+	x.Run()
+
+	os.Setenv("COMP_LINE", "foo list --format ")
+	x.Run()
+
+	os.Setenv("COMP_LINE", "foo list --format=j")
+	x.Run()
+
+	// Output:
+	// --format
+	// json
+	// text
+	// yaml
+	// --format=json
+}
+
+func TestCmd_RunE_format_parallel(t *testing.T) {
+	call := func(x *Z.Cmd, args ...string) error {
+		if x.Format() != args[0] {
+			t.Errorf("%v: format %q (want %q)", x.Name, x.Format(), args[0])
+		}
+		tbl := &Z.Table{Cmd: x}
+		tbl.AddRow(x.Name, x.Format())
+		return tbl.Write(new(bytes.Buffer))
+	}
+	x := &Z.Cmd{
+		Name:    `foo`,
+		Formats: []string{`json`, `text`},
+		Commands: []*Z.Cmd{
+			{Name: `list`, Call: call},
+			{Name: `raw`, Formats: []string{`text`}, Call: call},
+		},
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		for _, args := range [][]string{{`list`, `json`}, {`raw`, `text`}} {
+			wg.Add(1)
+			go func(args []string) {
+				defer wg.Done()
+				if err := x.RunE(args...); err != nil {
+					t.Error(err)
+				}
+			}(args)
+		}
+	}
+	wg.Wait()
+}
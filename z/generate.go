@@ -0,0 +1,69 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import "fmt"
+
+// runs counts the calls to Run so that generated commands are only
+// generated once per Run (see AllCommands).
+var runs int
+
+// AllCommands returns the Commands followed by those generated by the
+// CommandsFunc (if any), which is called at most once per Run. The
+// Caller of each generated Cmd is set to x. Should the CommandsFunc
+// panic an ExitError is produced naming the Cmd and only the static
+// Commands are returned. The combined slice is only built again when
+// either part changes and is shared (and must not be changed).
+func (x *Cmd) AllCommands() []*Cmd {
+	if x.CommandsFunc == nil {
+		return x.Commands
+	}
+	treemu.Lock()
+	gen, genrun, run := x._gen, x._genrun, runs
+	treemu.Unlock()
+	if gen == nil || genrun != run {
+		gen = x.generate()
+		treemu.Lock()
+		x._gen, x._genrun = gen, run
+		treemu.Unlock()
+	}
+	if len(gen) == 0 {
+		return x.Commands
+	}
+	n := len(x.Commands)
+	treemu.Lock()
+	all := x._all
+	treemu.Unlock()
+	if len(all) == n+len(gen) && sameCmds(all[:n], x.Commands) &&
+		sameCmds(all[n:], gen) {
+		return all
+	}
+	all = make([]*Cmd, 0, n+len(gen))
+	all = append(all, x.Commands...)
+	all = append(all, gen...)
+	treemu.Lock()
+	x._all = all
+	treemu.Unlock()
+	return all
+}
+
+func (x *Cmd) generate() (gen []*Cmd) {
+	defer func() {
+		if r := recover(); r != nil {
+			gen = []*Cmd{}
+			ExitError(fmt.Errorf("failed to generate commands for %q: %v",
+				x.Name, r))
+		}
+	}()
+	for _, c := range x.CommandsFunc(x) {
+		if c == nil {
+			continue
+		}
+		gen = append(gen, bind(c, x))
+	}
+	if gen == nil {
+		gen = []*Cmd{}
+	}
+	return gen
+}
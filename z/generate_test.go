@@ -0,0 +1,102 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/rwxrob/bonzai/comp"
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func ExampleCmd_AllCommands() {
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+
+	envs := []string{"prod", "dev"}
+	calls := 0
+	x := &Z.Cmd{
+		Name:     `deploy`,
+		Commands: []*Z.Cmd{&Z.Cmd{Name: `list`, Summary: `list envs`}},
+		CommandsFunc: func(x *Z.Cmd) []*Z.Cmd {
+			calls++
+			var cmds []*Z.Cmd
+			for _, e := range envs {
+				e := e
+				cmds = append(cmds, &Z.Cmd{
+					Name:    e,
+					Summary: `deploy to ` + e,
+					Call: func(c *Z.Cmd, _ ...string) error {
+						fmt.Println("deploying to", e, "from", c.Caller.Name)
+						return nil
+					},
+				})
+			}
+			return cmds
+		},
+	}
+
+	fmt.Print(x.UsageCmdTitles())
+	fmt.Println(comp.Standard(x, "d"))
+
+	orig := os.Args
+	defer func() { os.Args = orig }()
+	os.Args = []string{"deploy", "dev"}
+	x.Run()
+	fmt.Println(calls)
+
+	// Output:
+	// list - list envs
+	// prod - deploy to prod
+	// dev  - deploy to dev
+	// [dev]
+	// deploying to dev from deploy
+	// 2
+}
+
+func ExampleCmd_AllCommands_panic() {
+	defer logErrs()()
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	defer log.SetOutput(os.Stderr)
+	defer log.SetFlags(log.Flags())
+	log.SetOutput(os.Stdout)
+	log.SetFlags(0)
+
+	x := &Z.Cmd{
+		Name:         `deploy`,
+		CommandsFunc: func(x *Z.Cmd) []*Z.Cmd { panic("bad config") },
+	}
+	fmt.Println(len(x.AllCommands()))
+
+	// Output:
+	// failed to generate commands for "deploy": bad config
+	// 0
+}
+
+func ExampleCmd_Resolve_generated() {
+	envs := []string{"prod", "dev"}
+	x := &Z.Cmd{
+		Name: `deploy`,
+		Call: func(_ *Z.Cmd, _ ...string) error { return nil },
+		CommandsFunc: func(x *Z.Cmd) []*Z.Cmd {
+			var cmds []*Z.Cmd
+			for _, e := range envs {
+				cmds = append(cmds, &Z.Cmd{Name: e})
+			}
+			return cmds
+		},
+	}
+
+	Z.TestRun(x)
+	fmt.Println(x.Resolve(`dev`) != nil, x.Resolve(`qa`) != nil)
+
+	envs = []string{"prod", "qa"}
+	Z.TestRun(x)
+	fmt.Println(x.Resolve(`dev`) != nil, x.Resolve(`qa`) != nil)
+
+	// Output:
+	// true false
+	// false true
+}
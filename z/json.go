@@ -0,0 +1,227 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// cmdJSON is the stable JSON schema of a Cmd (see MarshalJSON).
+type cmdJSON struct {
+	Name          string              `json:"name"`
+	Aliases       []string            `json:"aliases,omitempty"`
+	Summary       string              `json:"summary,omitempty"`
+	Group         string              `json:"group,omitempty"`
+	Usage         string              `json:"usage,omitempty"`
+	Version       string              `json:"version,omitempty"`
+	Copyright     string              `json:"copyright,omitempty"`
+	License       string              `json:"license,omitempty"`
+	Description   string              `json:"description,omitempty"`
+	Site          string              `json:"site,omitempty"`
+	Source        string              `json:"source,omitempty"`
+	Issues        string              `json:"issues,omitempty"`
+	Default       string              `json:"default,omitempty"`
+	Params        []string            `json:"params,omitempty"`
+	Flags         []Flag              `json:"flags,omitempty"`
+	EnvVars       []EnvVar            `json:"envvars,omitempty"`
+	ConfKeys      []ConfKey           `json:"confkeys,omitempty"`
+	Repeatable    []string            `json:"repeatable,omitempty"`
+	DepParams     map[string]string   `json:"depparams,omitempty"`
+	Excl          [][]string          `json:"excl,omitempty"`
+	Needs         map[string][]string `json:"needs,omitempty"`
+	MinArgs       int                 `json:"minargs,omitempty"`
+	MinParm       int                 `json:"minparm,omitempty"`
+	MaxParm       int                 `json:"maxparm,omitempty"`
+	StrictParams  bool                `json:"strictparams,omitempty"`
+	ReqConf       bool                `json:"reqconf,omitempty"`
+	ReqVars       bool                `json:"reqvars,omitempty"`
+	Require       []string            `json:"require,omitempty"`
+	WorkDirMarker string              `json:"workdirmarker,omitempty"`
+	WorkDirChdir  bool                `json:"workdirchdir,omitempty"`
+	DryRun        bool                `json:"supportsdryrun,omitempty"`
+	Formats       []string            `json:"formats,omitempty"`
+	FormatAuto    bool                `json:"formatauto,omitempty"`
+	Lock          bool                `json:"lock,omitempty"`
+	LockName      string              `json:"lockname,omitempty"`
+	LockWait      string              `json:"lockwait,omitempty"`
+	Timeout       string              `json:"timeout,omitempty"`
+	Retries       int                 `json:"retries,omitempty"`
+	ArgFiles      bool                `json:"expandargfiles,omitempty"`
+	IgnoreCase    bool                `json:"ignorecase,omitempty"`
+	PrefixMatch   bool                `json:"prefixmatch,omitempty"`
+	Hidden        []string            `json:"hidden,omitempty"`
+	Hide          bool                `json:"hide,omitempty"`
+	Deprecated    string              `json:"deprecated,omitempty"`
+	Other         []Section           `json:"other,omitempty"`
+	Examples      []Example           `json:"examples,omitempty"`
+	Tags          map[string]string   `json:"tags,omitempty"`
+	Call          bool                `json:"call,omitempty"`
+	Commands      []*Cmd              `json:"commands,omitempty"`
+}
+
+// MarshalJSON fulfills the json.Marshaler interface with a stable
+// schema of all the documentation, completion, and behavioral fields of
+// the Cmd and all of its Commands, including those generated by any
+// CommandsFunc (see AllCommands), recursively in the same order as the
+// Cmd struct. Durations are strings (ex: "1m30s"). Functions cannot be
+// marshaled so "call" is true only when the Cmd has a Call Method
+// (distinguishing leaves from branches). Caller is never included.
+func (x *Cmd) MarshalJSON() ([]byte, error) {
+	return json.Marshal(cmdJSON{
+		Name:          x.Name,
+		Aliases:       x.Aliases,
+		Summary:       x.Summary,
+		Group:         x.Group,
+		Usage:         x.Usage,
+		Version:       x.Version,
+		Copyright:     x.Copyright,
+		License:       x.License,
+		Description:   x.Description,
+		Site:          x.Site,
+		Source:        x.Source,
+		Issues:        x.Issues,
+		Default:       x.Default,
+		Params:        x.Params,
+		Flags:         x.Flags,
+		EnvVars:       x.EnvVars,
+		ConfKeys:      x.ConfKeys,
+		Repeatable:    x.Repeatable,
+		DepParams:     x.DepParams,
+		Excl:          x.Excl,
+		Needs:         x.Needs,
+		MinArgs:       x.MinArgs,
+		MinParm:       x.MinParm,
+		MaxParm:       x.MaxParm,
+		StrictParams:  x.StrictParams,
+		ReqConf:       x.ReqConf,
+		ReqVars:       x.ReqVars,
+		Require:       x.Require,
+		WorkDirMarker: x.WorkDirMarker,
+		WorkDirChdir:  x.WorkDirChdir,
+		DryRun:        x.SupportsDryRun,
+		Formats:       x.Formats,
+		FormatAuto:    x.FormatAuto,
+		Lock:          x.Lock,
+		LockName:      x.LockName,
+		LockWait:      durationJSON(x.LockWait),
+		Timeout:       durationJSON(x.Timeout),
+		Retries:       x.Retries,
+		ArgFiles:      x.ExpandArgFiles,
+		IgnoreCase:    x.IgnoreCase,
+		PrefixMatch:   x.PrefixMatch,
+		Hidden:        x.Hidden,
+		Hide:          x.Hide,
+		Deprecated:    x.Deprecated,
+		Other:         x.Other,
+		Examples:      x.Examples,
+		Tags:          x.Tags,
+		Call:          x.Callable(),
+		Commands:      x.AllCommands(),
+	})
+}
+
+// durationJSON returns the duration as a string (see time.Duration)
+// or an empty string (omitted) if zero.
+func durationJSON(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return d.String()
+}
+
+// durationFromJSON parses a string from durationJSON.
+func durationFromJSON(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// UnmarshalJSON fulfills the json.Unmarshaler interface for the same
+// schema as MarshalJSON. The Caller of every unmarshaled Command is set
+// to its parent. Since a Call Method cannot be unmarshaled, whether one
+// was there is remembered instead (see Callable). Generated Commands
+// become ordinary Commands since a CommandsFunc cannot be unmarshaled
+// either.
+func (x *Cmd) UnmarshalJSON(buf []byte) error {
+	var j cmdJSON
+	if err := json.Unmarshal(buf, &j); err != nil {
+		return err
+	}
+	lockwait, err := durationFromJSON(j.LockWait)
+	if err != nil {
+		return err
+	}
+	timeout, err := durationFromJSON(j.Timeout)
+	if err != nil {
+		return err
+	}
+	x.Name = j.Name
+	x.Aliases = j.Aliases
+	x.Summary = j.Summary
+	x.Group = j.Group
+	x.Usage = j.Usage
+	x.Version = j.Version
+	x.Copyright = j.Copyright
+	x.License = j.License
+	x.Description = j.Description
+	x.Site = j.Site
+	x.Source = j.Source
+	x.Issues = j.Issues
+	x.Default = j.Default
+	x.Params = j.Params
+	x.Flags = j.Flags
+	x.EnvVars = j.EnvVars
+	x.ConfKeys = j.ConfKeys
+	x.Repeatable = j.Repeatable
+	x.DepParams = j.DepParams
+	x.Excl = j.Excl
+	x.Needs = j.Needs
+	x.MinArgs = j.MinArgs
+	x.MinParm = j.MinParm
+	x.MaxParm = j.MaxParm
+	x.StrictParams = j.StrictParams
+	x.ReqConf = j.ReqConf
+	x.ReqVars = j.ReqVars
+	x.Require = j.Require
+	x.WorkDirMarker = j.WorkDirMarker
+	x.WorkDirChdir = j.WorkDirChdir
+	x.SupportsDryRun = j.DryRun
+	x.Formats = j.Formats
+	x.FormatAuto = j.FormatAuto
+	x.Lock = j.Lock
+	x.LockName = j.LockName
+	x.LockWait = lockwait
+	x.Timeout = timeout
+	x.Retries = j.Retries
+	x.ExpandArgFiles = j.ArgFiles
+	x.IgnoreCase = j.IgnoreCase
+	x.PrefixMatch = j.PrefixMatch
+	x.Hidden = j.Hidden
+	x.Hide = j.Hide
+	x.Deprecated = j.Deprecated
+	x.Other = j.Other
+	x.Examples = j.Examples
+	x.Tags = j.Tags
+	x._call = j.Call
+	x.Commands = j.Commands
+	for _, c := range x.Commands {
+		if c != nil {
+			c.Caller = x
+		}
+	}
+	return nil
+}
+
+// Callable returns true if the Cmd has a Call Method or was unmarshaled
+// from JSON that indicated that it had one.
+func (x *Cmd) Callable() bool { return x.Call != nil || x._call }
+
+// TreeJSON returns the entire command tree as indented JSON (see
+// MarshalJSON) so that external tools (documentation sites, completion
+// daemons, etc.) can use it without linking to the Go code.
+func TreeJSON(x *Cmd) ([]byte, error) {
+	return json.MarshalIndent(x, "", "  ")
+}
@@ -0,0 +1,117 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func ExampleTreeJSON() {
+	x := &Z.Cmd{
+		Name:    `foo`,
+		Summary: `foo the things`,
+		Other:   []Z.Section{{`Notes`, `Some notes.`}},
+		Commands: []*Z.Cmd{
+			&Z.Cmd{
+				Name:    `bar`,
+				Aliases: []string{"b"},
+				Params:  []string{"p1", "p2"},
+				MaxParm: 1,
+				Call:    func(_ *Z.Cmd, _ ...string) error { return nil },
+			},
+		},
+	}
+	x.Commands[0].Caller = x // never marshaled
+
+	buf, err := Z.TreeJSON(x)
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(string(buf))
+
+	// Output:
+	// {
+	//   "name": "foo",
+	//   "summary": "foo the things",
+	//   "other": [
+	//     {
+	//       "title": "Notes",
+	//       "body": "Some notes."
+	//     }
+	//   ],
+	//   "commands": [
+	//     {
+	//       "name": "bar",
+	//       "aliases": [
+	//         "b"
+	//       ],
+	//       "params": [
+	//         "p1",
+	//         "p2"
+	//       ],
+	//       "maxparm": 1,
+	//       "call": true
+	//     }
+	//   ]
+	// }
+}
+
+func ExampleCmd_UnmarshalJSON() {
+	in := `{"name":"foo","commands":[{"name":"bar","call":true},{"name":"baz"}]}`
+	x := new(Z.Cmd)
+	if err := json.Unmarshal([]byte(in), x); err != nil {
+		fmt.Println(err)
+	}
+	bar, baz := x.Commands[0], x.Commands[1]
+	fmt.Println(bar.Name, bar.Callable(), bar.Caller.Name)
+	fmt.Println(baz.Name, baz.Callable(), baz.Caller.Name)
+	// Output:
+	// bar true foo
+	// baz false foo
+}
+
+func TestCmd_MarshalJSON_roundtrip(t *testing.T) {
+	x := &Z.Cmd{
+		Name:           `foo`,
+		LockWait:       3 * time.Second,
+		Timeout:        90 * time.Second,
+		Retries:        2,
+		ExpandArgFiles: true,
+		IgnoreCase:     true,
+		PrefixMatch:    true,
+		Commands:       []*Z.Cmd{{Name: `bar`}},
+		CommandsFunc: func(_ *Z.Cmd) []*Z.Cmd {
+			return []*Z.Cmd{{Name: `gen`, Retries: 1}}
+		},
+	}
+	buf, err := json.Marshal(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	y := new(Z.Cmd)
+	if err := json.Unmarshal(buf, y); err != nil {
+		t.Fatal(err)
+	}
+	if y.LockWait != x.LockWait || y.Timeout != x.Timeout ||
+		y.Retries != x.Retries || !y.ExpandArgFiles || !y.IgnoreCase ||
+		!y.PrefixMatch {
+		t.Errorf("behavioral fields lost: %s", buf)
+	}
+	if len(y.Commands) != 2 || y.Commands[1].Name != `gen` ||
+		y.Commands[1].Retries != 1 || y.Commands[1].Caller != y {
+		t.Errorf("generated commands lost: %s", buf)
+	}
+	again, err := json.Marshal(y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != string(buf) {
+		t.Errorf("got:\n%s\nwant:\n%s", again, buf)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rwxrob/bonzai"
+)
+
+// KV returns the key=value arguments matching any of the key=value
+// Params (see bonzai.KVParam) as a map. Other arguments are ignored. If
+// a key is passed more than once the last value wins.
+func (x *Cmd) KV(args []string) map[string]string {
+	kv := map[string]string{}
+	for _, a := range args {
+		if k, v, ok := strings.Cut(a, "="); ok && x.kvParam(k) != nil {
+			kv[k] = v
+		}
+	}
+	return kv
+}
+
+// kvParam returns the allowed values of the key=value param with the
+// given key (an empty, non-nil slice for any value) or nil if there is
+// no such param.
+func (x *Cmd) kvParam(key string) []string {
+	for _, p := range x.Params {
+		if k, vals, ok := bonzai.KVParam(p); ok && k == key {
+			if vals == nil {
+				vals = []string{}
+			}
+			return vals
+		}
+	}
+	return nil
+}
+
+// checkKV returns a UsageError (with the reason first) for the first of
+// the args that is a key=value param with a missing or disallowed
+// value.
+func (x *Cmd) checkKV(args []string) error {
+	for _, a := range args {
+		k, v, ok := strings.Cut(a, "=")
+		if !ok {
+			if vals := x.kvParam(a); vals != nil {
+				ok, v = true, ""
+			}
+		}
+		vals := x.kvParam(k)
+		if !ok || vals == nil {
+			continue
+		}
+		var reason string
+		switch {
+		case v == "":
+			reason = fmt.Sprintf("missing value for %q", k)
+		case len(vals) > 0 && !contains(vals, v):
+			reason = fmt.Sprintf("invalid value for %q: %q", k, v)
+		default:
+			continue
+		}
+		return fmt.Errorf("%v\n%v", reason, x.UsageError())
+	}
+	return nil
+}
+
+// keysWithPrefix returns the sorted keys of the map beginning with the
+// prefix (all of them if empty).
+func keysWithPrefix[T any](m map[string]T, pre string) []string {
+	keys := []string{}
+	for k := range m {
+		if strings.HasPrefix(k, pre) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(list []string, s string) bool {
+	for _, i := range list {
+		if i == s {
+			return true
+		}
+	}
+	return false
+}
+
+// usageParams returns the Params with any key=value params in usage
+// notation (ex: env=<value>, env=(prod|dev)).
+func usageParams(params []string) []string {
+	var list []string
+	for _, p := range params {
+		if k, vals, ok := bonzai.KVParam(p); ok {
+			switch len(vals) {
+			case 0:
+				p = k + "=<value>"
+			case 1:
+				p = k + "=" + vals[0]
+			default:
+				p = k + "=(" + strings.Join(vals, "|") + ")"
+			}
+		}
+		list = append(list, p)
+	}
+	return list
+}
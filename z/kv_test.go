@@ -0,0 +1,52 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func ExampleCmd_KV() {
+	defer logErrs()()
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	defer log.SetOutput(os.Stderr)
+	defer log.SetFlags(log.Flags())
+	log.SetOutput(os.Stdout)
+	log.SetFlags(0)
+
+	x := &Z.Cmd{
+		Name:    `deploy`,
+		Params:  []string{"env=prod|dev", "limit=", "force"},
+		MaxParm: 3,
+		Call: func(x *Z.Cmd, args ...string) error {
+			fmt.Println(x.KV(args))
+			return nil
+		},
+	}
+	fmt.Println(x.UsageError())
+
+	orig := os.Args
+	defer func() { os.Args = orig }()
+
+	os.Args = []string{"deploy", "env=dev", "limit=100", "force"}
+	x.Run()
+
+	os.Args = []string{"deploy", "env=qa"}
+	x.Run()
+
+	os.Args = []string{"deploy", "limit="}
+	x.Run()
+
+	// Output:
+	// usage: deploy [(env=(prod|dev)|limit=<value>|force){1,3}]
+	// map[env:dev limit:100]
+	// invalid value for "env": "qa"
+	// usage: deploy [(env=(prod|dev)|limit=<value>|force){1,3}]
+	// missing value for "limit"
+	// usage: deploy [(env=(prod|dev)|limit=<value>|force){1,3}]
+}
@@ -0,0 +1,189 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Lang is the language (ex: en, es, pt_BR) of every user-visible
+// message looked up with Msg. It is set at init time from the first of
+// LC_ALL, LC_MESSAGES, or LANG that is not empty (see LangFromEnv) and
+// may be assigned at any time. Languages that have not been registered
+// (see AddLang) fall back to the base language (es for es_MX) and then
+// to English.
+var Lang = LangFromEnv()
+
+// Langs contains the messages of every registered language keyed by
+// message ID (see Msg). English ("en") must always be complete since
+// every lookup falls back to it. Use AddLang to register more.
+var Langs = map[string]map[string]string{
+
+	`en`: {
+		`usage`:                `usage`,
+		`example`:              `example`,
+		`not-command`:          `{ERROR: not a bonzai.Command}`,
+		`no-call-no-commands`:  `{ERROR: neither Call nor Commands defined}`,
+		`params-without-call`:  `{ERROR: Params without Call: %v}`,
+		`name-empty`:           `{ERROR: Name is empty}`,
+		`unimplemented`:        `%q has not yet been implemented`,
+		`missing-config`:       `missing config: %v`,
+		`missing-config-conf`:  `missing config: %v (Z.Conf must be assigned)`,
+		`requires-conf`:        `cmd %q requires a configurer (Z.Conf must be assigned)`,
+		`requires-vars`:        `cmd %q requires vars (Z.Vars must be assigned)`,
+		`requires-path`:        `%v requires (not found in PATH): %v`,
+		`requires-workdir`:     `%v must be run within a directory containing %v (not found in %v or above)`,
+		`already-running`:      `%v already running (pid %v)`,
+		`already-done`:         `already done: %v (%v ago)`,
+		`timed-out`:            `%v timed out after %v`,
+		`ambiguous-command`:    `ambiguous command %q (%v)`,
+		`default-needs-call`:   `default commands require Call function`,
+		`flag-needs-value`:     `flag %v requires a value`,
+		`flag-unsupported`:     `%v does not support --%v`,
+		`format-unsupported`:   `unsupported format %q for %v (one of: %v)`,
+		`is-deprecated`:        `%q is %v: %v`,
+		`deprecated`:           `deprecated`,
+		`ungrouped`:            `Other Commands`,
+		`missing-arg`:          `missing argument`,
+		`arg-error`:            `%v: argument %v`,
+		`not-integer`:          `not an integer`,
+		`not-boolean`:          `not a boolean`,
+		`not-duration`:         `not a duration`,
+		`must-be-one-of`:       `must be one of: %v`,
+		`file-not-found`:       `file not found`,
+		`cannot-read-argfile`:  `cannot read argument file: %v`,
+		`unsupported-shell`:    `unsupported completion shell: %q`,
+		`invalid-timeout`:      `%v: invalid timeout`,
+		`aliases-not-list`:     `%v: %v: must be a list of strings`,
+		`aliases-need-conf`:    `aliases require a configurer (Z.Conf must be assigned)`,
+		`alias-would-shadow`:   `alias would shadow command: %q`,
+		`alias-not-found`:      `no such alias in configuration: %q`,
+		`alias-loop`:           `alias loop: %v`,
+		`alias-too-deep`:       `alias depth exceeded (%v): %v`,
+		`alias-shadows`:        `alias shadows command: %q`,
+		`alias-case`:           `aliases differ only by case: %q, %q`,
+		`multicall-unmapped`:   `unmapped multicall command: %v (not one of: %v)`,
+		`multicall-missing`:    `multicall command missing`,
+		`multicall-first`:      `first value must be *Cmd or func() *Cmd (not %T)`,
+		`multicall-not-string`: `only string arguments allowed`,
+	},
+
+	`es`: {
+		`usage`:                `uso`,
+		`example`:              `ejemplo`,
+		`not-command`:          `{ERROR: no es un bonzai.Command}`,
+		`no-call-no-commands`:  `{ERROR: no hay ni Call ni Commands}`,
+		`params-without-call`:  `{ERROR: Params sin Call: %v}`,
+		`name-empty`:           `{ERROR: Name está vacío}`,
+		`unimplemented`:        `%q todavía no ha sido implementado`,
+		`missing-config`:       `falta configuración: %v`,
+		`missing-config-conf`:  `falta configuración: %v (hay que asignar Z.Conf)`,
+		`requires-conf`:        `el comando %q requiere un configurador (hay que asignar Z.Conf)`,
+		`requires-vars`:        `el comando %q requiere variables (hay que asignar Z.Vars)`,
+		`requires-path`:        `%v requiere (no encontrado en PATH): %v`,
+		`requires-workdir`:     `%v debe ejecutarse dentro de un directorio que contenga %v (no encontrado en %v ni arriba)`,
+		`already-running`:      `%v ya se está ejecutando (pid %v)`,
+		`already-done`:         `ya hecho: %v (hace %v)`,
+		`timed-out`:            `%v agotó el tiempo tras %v`,
+		`ambiguous-command`:    `comando ambiguo %q (%v)`,
+		`default-needs-call`:   `los comandos por defecto requieren una función Call`,
+		`flag-needs-value`:     `la opción %v requiere un valor`,
+		`flag-unsupported`:     `%v no admite --%v`,
+		`format-unsupported`:   `formato %q no admitido por %v (uno de: %v)`,
+		`is-deprecated`:        `%q está %v: %v`,
+		`deprecated`:           `obsoleto`,
+		`ungrouped`:            `Otros comandos`,
+		`missing-arg`:          `falta el argumento`,
+		`arg-error`:            `%v: argumento %v`,
+		`not-integer`:          `no es un entero`,
+		`not-boolean`:          `no es un booleano`,
+		`not-duration`:         `no es una duración`,
+		`must-be-one-of`:       `debe ser uno de: %v`,
+		`file-not-found`:       `archivo no encontrado`,
+		`cannot-read-argfile`:  `no se puede leer el archivo de argumentos: %v`,
+		`unsupported-shell`:    `shell de completado no admitido: %q`,
+		`invalid-timeout`:      `%v: tiempo límite no válido`,
+		`aliases-not-list`:     `%v: %v: debe ser una lista de strings`,
+		`aliases-need-conf`:    `los alias requieren un configurador (hay que asignar Z.Conf)`,
+		`alias-would-shadow`:   `el alias ocultaría el comando: %q`,
+		`alias-not-found`:      `no existe el alias en la configuración: %q`,
+		`alias-loop`:           `bucle de alias: %v`,
+		`alias-too-deep`:       `profundidad de alias excedida (%v): %v`,
+		`alias-shadows`:        `el alias oculta el comando: %q`,
+		`alias-case`:           `los alias sólo difieren en mayúsculas: %q, %q`,
+		`multicall-unmapped`:   `comando multicall no asignado: %v (no es uno de: %v)`,
+		`multicall-missing`:    `falta el comando multicall`,
+		`multicall-first`:      `el primer valor debe ser *Cmd o func() *Cmd (no %T)`,
+		`multicall-not-string`: `sólo se permiten argumentos de tipo string`,
+	},
+}
+
+// AddLang registers the messages (by ID) for the language adding to (or
+// replacing) any already registered for it. Messages not included fall
+// back as described for Lang. Since the messages are fmt format strings
+// explicit argument indexes (ex: %[2]v) may be used when the word order
+// of the language differs from English.
+func AddLang(lang string, msgs map[string]string) {
+	m, has := Langs[lang]
+	if !has {
+		m = map[string]string{}
+		Langs[lang] = m
+	}
+	for id, msg := range msgs {
+		m[id] = msg
+	}
+}
+
+// Msg returns the message with the given ID for the current Lang
+// formatted with the args (see fmt.Sprintf). If the ID is not found in
+// any language the ID itself is used as the format.
+func Msg(id string, args ...any) string {
+	format := id
+	for _, l := range langChain(Lang) {
+		if m, has := Langs[l][id]; has {
+			format = m
+			break
+		}
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// langChain returns the languages to search for the lang in order (ex:
+// es_MX, es, en).
+func langChain(lang string) []string {
+	var chain []string
+	if lang != "" {
+		chain = append(chain, lang)
+	}
+	if base, _, found := strings.Cut(lang, "_"); found && base != "" {
+		chain = append(chain, base)
+	}
+	return append(chain, `en`)
+}
+
+// LangFromEnv returns the language from the first of the LC_ALL,
+// LC_MESSAGES, and LANG environment variables that is set with any
+// encoding or modifier removed (ex: es_MX.UTF-8 becomes es_MX). The C
+// and POSIX locales (and no locale at all) are "en".
+func LangFromEnv() string {
+	for _, name := range []string{`LC_ALL`, `LC_MESSAGES`, `LANG`} {
+		v := os.Getenv(name)
+		if v == "" {
+			continue
+		}
+		if i := strings.IndexAny(v, ".@"); i >= 0 {
+			v = v[:i]
+		}
+		if v == "" || v == "C" || v == "POSIX" {
+			return `en`
+		}
+		return v
+	}
+	return `en`
+}
@@ -0,0 +1,82 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"fmt"
+	"os"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+// The Examples print English messages no matter the locale of the
+// environment running the tests (see LangFromEnv).
+func init() { Z.Lang = `en` }
+
+func ExampleMsg() {
+	defer func(l string) { Z.Lang = l }(Z.Lang)
+
+	Z.Lang = `en`
+	fmt.Println(Z.Msg(`unimplemented`, `foo`))
+	Z.Lang = `es_MX`
+	fmt.Println(Z.Msg(`unimplemented`, `foo`))
+	Z.Lang = `xx`
+	fmt.Println(Z.Msg(`unimplemented`, `foo`))
+	fmt.Println(Z.Msg(`not a message id`))
+
+	// Output:
+	// "foo" has not yet been implemented
+	// "foo" todavía no ha sido implementado
+	// "foo" has not yet been implemented
+	// not a message id
+}
+
+func ExampleAddLang() {
+	defer func(l string) { Z.Lang = l }(Z.Lang)
+	Z.AddLang(`yo`, map[string]string{
+		`requires-path`: `not found in PATH, %[2]v is; requires it, %[1]v does`,
+	})
+	Z.Lang = `yo`
+	fmt.Println(Z.Msg(`requires-path`, `foo`, `git`))
+	fmt.Println(Z.Msg(`usage`))
+	// Output:
+	// not found in PATH, git is; requires it, foo does
+	// usage
+}
+
+func ExampleLangFromEnv() {
+	for _, name := range []string{`LC_ALL`, `LC_MESSAGES`, `LANG`} {
+		defer os.Setenv(name, os.Getenv(name))
+		os.Unsetenv(name)
+	}
+	fmt.Println(Z.LangFromEnv())
+	os.Setenv(`LANG`, `C.UTF-8`)
+	fmt.Println(Z.LangFromEnv())
+	os.Setenv(`LANG`, `es_MX.UTF-8`)
+	fmt.Println(Z.LangFromEnv())
+	os.Setenv(`LC_ALL`, `pt_BR@euro`)
+	fmt.Println(Z.LangFromEnv())
+	// Output:
+	// en
+	// en
+	// es_MX
+	// pt_BR
+}
+
+func ExampleCmd_UsageError_lang() {
+	defer func(l string) { Z.Lang = l }(Z.Lang)
+	Z.Lang = `es`
+	x := &Z.Cmd{
+		Name:     `foo`,
+		Params:   []string{`a`, `b`},
+		Call:     func(_ *Z.Cmd, _ ...string) error { return nil },
+		Examples: []Z.Example{{Cmd: `a`}},
+	}
+	fmt.Println(x.UsageError())
+	fmt.Println(Z.InferredUsage(&Z.Cmd{Name: `bar`}))
+	// Output:
+	// uso: foo [a|b]...
+	// ejemplo: foo a
+	// {ERROR: no hay ni Call ni Commands}
+}
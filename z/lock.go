@@ -0,0 +1,128 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LockDir is the directory containing the lock files of every Cmd that
+// sets Lock or LockName. If empty, ExeName within os.UserCacheDir is
+// used.
+var LockDir string
+
+// LockPoll is how often a contended lock is tried again while waiting
+// for it (see LockWait).
+var LockPoll = 100 * time.Millisecond
+
+// lockDir returns the LockDir or the default if empty.
+func lockDir() (string, error) {
+	if LockDir != "" {
+		return LockDir, nil
+	}
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cache, ExeName), nil
+}
+
+// lockFile returns the path of the lock file for the Cmd (named for
+// LockName or the PathString).
+func (x *Cmd) lockFile() (string, error) {
+	dir, err := lockDir()
+	if err != nil {
+		return "", err
+	}
+	name := x.LockName
+	if name == "" {
+		name = x.PathString()
+	}
+	if name == "" {
+		name = x.Name
+	}
+	return filepath.Join(dir, name+".lock"), nil
+}
+
+// lock acquires the OS-level file lock (flock on UNIX, LockFileEx on
+// Windows) for the Cmd if it sets Lock or LockName returning
+// a function to release it that may be called more than once and is
+// also called by Exit, ExitError, and TrapPanic (see AtExit) if not
+// called before. If the lock is held (by another process or from
+// another goroutine) it is tried again every LockPoll until the
+// LockWait has passed (forever if less than 0) and then an error
+// naming the process ID of the holder is returned. The lock file is
+// never removed.
+func (x *Cmd) lock() (func(), error) {
+	if !x.Lock && x.LockName == "" {
+		return func() {}, nil
+	}
+	path, err := x.lockFile()
+	if err != nil {
+		return nil, err
+	}
+	release, pid, err := lockPath(path, x.LockWait)
+	if err != nil {
+		return nil, err
+	}
+	if release == nil {
+		return nil, errors.New(Msg(`already-running`, x.logPath(), pid))
+	}
+	return release, nil
+}
+
+// lockPath acquires the file lock at path (creating it and any missing
+// directories) trying again every LockPoll until the wait has passed
+// (forever if less than 0). If the lock is still held the release
+// function is nil and the process ID written by the holder is returned
+// instead. Otherwise, the release function is also registered with
+// AtExit until called.
+func lockPath(path string, wait time.Duration) (func(), string, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, "", err
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, "", err
+	}
+	deadline := time.Now().Add(wait)
+	for {
+		locked, err := tryLock(f)
+		if err != nil {
+			f.Close()
+			return nil, "", err
+		}
+		if locked {
+			break
+		}
+		if wait >= 0 && !time.Now().Before(deadline) {
+			pid := "?"
+			if buf, err := os.ReadFile(path); err == nil && len(buf) > 0 {
+				pid = strings.TrimSpace(string(buf))
+			}
+			f.Close()
+			return nil, pid, nil
+		}
+		time.Sleep(LockPoll)
+	}
+	f.Truncate(0)
+	f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	var once sync.Once
+	var unregister func()
+	release := func() {
+		once.Do(func() {
+			unregister()
+			unlock(f)
+			f.Close()
+		})
+	}
+	unregister = atExit(release)
+	return release, "", nil
+}
@@ -0,0 +1,19 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !windows
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!windows
+
+package Z
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+func tryLock(f *os.File) (bool, error) {
+	return false, fmt.Errorf("file locking not supported on %v", runtime.GOOS)
+}
+
+func unlock(f *os.File) error { return nil }
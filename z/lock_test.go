@@ -0,0 +1,153 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func ExampleCmd_Run_lock() {
+	defer logErrs()()
+	rec := new(Z.RecordingExiter)
+	defer Z.SetExiter(rec)()
+	defer log.SetOutput(os.Stderr)
+	defer log.SetFlags(log.Flags())
+	log.SetOutput(os.Stdout)
+	log.SetFlags(0)
+	orig := os.Args
+	defer func() { os.Args = orig }()
+	tmp, _ := os.MkdirTemp("", "bonzai-lock")
+	defer os.RemoveAll(tmp)
+	defer func(d string) { Z.LockDir = d }(Z.LockDir)
+	Z.LockDir = tmp
+
+	x := &Z.Cmd{Name: `foo`}
+	x.Commands = []*Z.Cmd{
+		&Z.Cmd{
+			Name: `sync`,
+			Lock: true,
+			Call: func(_ *Z.Cmd, _ ...string) error {
+				err := x.RunE("sync")
+				pid := strconv.Itoa(os.Getpid())
+				fmt.Println(strings.Replace(err.Error(), pid, "PID", 1))
+				return nil
+			},
+		},
+	}
+
+	os.Args = []string{"foo", "sync"}
+	x.Run()
+	fmt.Println(x.RunE("sync"))
+	_, err := os.Stat(tmp + "/sync.lock")
+	fmt.Println(err, rec.Codes())
+
+	// Output:
+	// sync already running (pid PID)
+	// sync already running (pid PID)
+	// <nil>
+	// <nil> [0]
+}
+
+func TestCmd_LockWait(t *testing.T) {
+	defer func(d string) { Z.LockDir = d }(Z.LockDir)
+	Z.LockDir = t.TempDir()
+	defer func(p time.Duration) { Z.LockPoll = p }(Z.LockPoll)
+	Z.LockPoll = 5 * time.Millisecond
+
+	started := make(chan bool)
+	hold := func(_ *Z.Cmd, _ ...string) error {
+		started <- true
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	}
+	var ran bool
+	x := &Z.Cmd{
+		Name: `foo`,
+		Commands: []*Z.Cmd{
+			&Z.Cmd{Name: `a`, LockName: `db`, Call: hold},
+			&Z.Cmd{
+				Name:     `b`,
+				LockName: `db`,
+				LockWait: 2 * time.Second,
+				Call:     func(*Z.Cmd, ...string) error { ran = true; return nil },
+			},
+			&Z.Cmd{
+				Name:     `c`,
+				LockName: `db`,
+				LockWait: 10 * time.Millisecond,
+				Call:     func(*Z.Cmd, ...string) error { return nil },
+			},
+			&Z.Cmd{
+				Name:     `d`,
+				LockName: `db`,
+				LockWait: -1,
+				Call:     func(*Z.Cmd, ...string) error { return nil },
+			},
+		},
+	}
+
+	done := make(chan error)
+	go func() { done <- x.RunE("a") }()
+	<-started
+	if err := x.RunE("c"); err == nil || !strings.Contains(err.Error(), "already running") {
+		t.Errorf("expected already running, got %v", err)
+	}
+	if err := x.RunE("b"); err != nil || !ran {
+		t.Errorf("expected b to wait and run, got %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Error(err)
+	}
+
+	go func() { done <- x.RunE("a") }()
+	<-started
+	if err := x.RunE("d"); err != nil {
+		t.Errorf("expected d to wait forever and run, got %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCmd_Lock_timeout(t *testing.T) {
+	defer func(d string) { Z.LockDir = d }(Z.LockDir)
+	Z.LockDir = t.TempDir()
+
+	finish := make(chan bool)
+	returned := make(chan bool)
+	x := &Z.Cmd{
+		Name:    `foo`,
+		Lock:    true,
+		Timeout: 10 * time.Millisecond,
+		Call: func(*Z.Cmd, ...string) error {
+			<-finish
+			returned <- true
+			return nil
+		},
+	}
+
+	var terr *Z.TimeoutError
+	if err := x.RunE(); !errors.As(err, &terr) {
+		t.Fatalf("expected TimeoutError, got %v", err)
+	}
+	if err := x.RunE(); err == nil || !strings.Contains(err.Error(), "already running") {
+		t.Errorf("abandoned Call should still hold lock, got %v", err)
+	}
+	finish <- true
+	<-returned
+	x.Call = func(*Z.Cmd, ...string) error { return nil }
+	x.LockWait = time.Second
+	if err := x.RunE(); err != nil {
+		t.Errorf("lock should be released once Call returns, got %v", err)
+	}
+}
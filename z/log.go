@@ -0,0 +1,136 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// Level is the severity of a log message (see LogLevel).
+type Level int
+
+const (
+	LevelDebug Level = iota - 1
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// String returns the lowercase name of the Level (ex: warn).
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	}
+	return fmt.Sprintf("level(%d)", int(l))
+}
+
+// ParseLevel returns the Level for its name (see Level.String) ignoring
+// case. The name "warning" is also accepted.
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	}
+	return LevelInfo, fmt.Errorf("invalid log level: %q", name)
+}
+
+// LogLevel is the lowest Level of messages that are logged. All logging
+// by Bonzai itself (including that of ExitError and the warnings about
+// deprecated commands, templates, etc.) as well as that of the Cmd
+// logging methods (Log, Debug, Info, Warn, Error) is filtered by it.
+// It is initialized from the LOG_LEVEL environment variable (see
+// ParseLevel) and defaults to LevelInfo.
+var LogLevel = LevelInfo
+
+// LogJSON causes all messages to be logged as single lines of JSON
+// (with time, level, cmd, and msg keys) written to the log.Writer
+// instead of the usual human-friendly format.
+var LogJSON bool
+
+func init() {
+	if v := os.Getenv("LOG_LEVEL"); v != "" {
+		l, err := ParseLevel(v)
+		if err != nil {
+			log.Print(err)
+			return
+		}
+		LogLevel = l
+	}
+}
+
+// logAt logs the message if its Level is at or above the LogLevel. The
+// human format is the message prefixed with the path (if any) and
+// "debug: " or "warning: " for those levels (see log.Print).
+func logAt(level Level, path, msg string) {
+	if level < LogLevel {
+		return
+	}
+	if LogJSON {
+		buf, _ := json.Marshal(struct {
+			Time  string `json:"time"`
+			Level string `json:"level"`
+			Cmd   string `json:"cmd,omitempty"`
+			Msg   string `json:"msg"`
+		}{time.Now().Format(time.RFC3339), level.String(), path, msg})
+		fmt.Fprintln(log.Writer(), string(buf))
+		return
+	}
+	switch level {
+	case LevelDebug:
+		msg = "debug: " + msg
+	case LevelWarn:
+		msg = "warning: " + msg
+	}
+	if path != "" {
+		msg = path + ": " + msg
+	}
+	log.Print(msg)
+}
+
+// logPath returns the PathString of the Cmd or its Name if it is the
+// root.
+func (x *Cmd) logPath() string {
+	if p := x.PathString(); p != "" {
+		return p
+	}
+	return x.Name
+}
+
+// Debug logs at LevelDebug prefixed with the PathString (see LogLevel).
+func (x *Cmd) Debug(format string, a ...any) {
+	logAt(LevelDebug, x.logPath(), fmt.Sprintf(format, a...))
+}
+
+// Info logs at LevelInfo prefixed with the PathString (see LogLevel).
+func (x *Cmd) Info(format string, a ...any) {
+	logAt(LevelInfo, x.logPath(), fmt.Sprintf(format, a...))
+}
+
+// Warn logs at LevelWarn prefixed with the PathString (see LogLevel).
+func (x *Cmd) Warn(format string, a ...any) {
+	logAt(LevelWarn, x.logPath(), fmt.Sprintf(format, a...))
+}
+
+// Error logs at LevelError prefixed with the PathString (see LogLevel).
+func (x *Cmd) Error(format string, a ...any) {
+	logAt(LevelError, x.logPath(), fmt.Sprintf(format, a...))
+}
@@ -0,0 +1,78 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func ExampleCmd_Warn() {
+	defer log.SetOutput(os.Stderr)
+	defer log.SetFlags(log.Flags())
+	log.SetOutput(os.Stdout)
+	log.SetFlags(0)
+	defer func(l Z.Level) { Z.LogLevel = l }(Z.LogLevel)
+
+	x := &Z.Cmd{Name: `foo`}
+	bar := x.Add(`bar`)
+	bar.Caller = x
+
+	x.Log("just like log.Printf")
+	x.Info("root")
+	bar.Debug("not shown")
+	bar.Warn("careful: %v", 42)
+
+	Z.LogLevel = Z.LevelDebug
+	bar.Debug("shown now")
+
+	Z.LogLevel = Z.LevelError
+	bar.Warn("not shown")
+	bar.Error("failed")
+
+	// Output:
+	// just like log.Printf
+	// foo: root
+	// bar: warning: careful: 42
+	// bar: debug: shown now
+	// bar: failed
+}
+
+func ExampleLogJSON() {
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	defer log.SetOutput(os.Stderr)
+	defer func() { Z.LogJSON = false }()
+	buf := new(bytes.Buffer)
+	log.SetOutput(buf)
+	Z.LogJSON = true
+
+	x := &Z.Cmd{Name: `foo`}
+	x.Warn("careful")
+	Z.ExitError(errors.New("failed"))
+
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		m := map[string]string{}
+		dec.Decode(&m)
+		fmt.Println(m["level"], m["cmd"], m["msg"], m["time"] != "")
+	}
+
+	// Output:
+	// warn foo careful true
+	// error  failed true
+}
+
+func ExampleParseLevel() {
+	fmt.Println(Z.ParseLevel("WARNING"))
+	fmt.Println(Z.ParseLevel("loud"))
+	// Output:
+	// warn <nil>
+	// info invalid log level: "loud"
+}
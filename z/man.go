@@ -0,0 +1,194 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ToMan renders a single Cmd as a troff document using the man macros
+// for the given manual section (usually 1). The page is named after the
+// full invocation path joined with dashes (ex: foo-db) and contains the
+// following sections (when not empty):
+//
+//     NAME          - from Title
+//     SYNOPSIS      - the path followed by the usage (see UsageFunc)
+//     DESCRIPTION   - the Description re-flowed (see Blocks)
+//     PARAMS        - each constrained Param (see Excl and Needs)
+//     FLAGS         - each of Flags with its Summary and Default
+//     ENVIRONMENT   - each of EnvVars with its Summary and Default
+//     CONFIGURATION - each of ConfKeys (full path) with its Summary
+//     EXAMPLES      - each of Examples (see Invocation) with its Note
+//     COMMANDS      - the Commands and their Summary (less Hidden)
+//     <OTHER>       - each of Other as a top-level section (uppercase)
+//     COPYRIGHT     - from Copyright and License (see Legal)
+//
+// Paragraph blocks are joined into single lines so that roff can fill
+// them. Lists and Verbatim blocks are left unfilled. Backslashes,
+// dashes, and leading dots or single quotes are escaped. Hidden
+// commands are included only if DocHidden is true.
+func ToMan(x *Cmd, section int) string {
+	var out strings.Builder
+
+	var path []string
+	for _, c := range x.Callers() {
+		path = append(path, c.Name)
+	}
+	path = append(path, x.Name)
+	page := strings.Join(path, "-")
+
+	version := x.Version
+	if version == "" {
+		version = x.Root().Version
+	}
+	fmt.Fprintf(&out, ".TH \"%v\" \"%v\" \"\" \"%v\" \"\"\n",
+		roffEsc(strings.ToUpper(page)), section,
+		strings.TrimSpace(roffEsc(path[0]+" "+version)))
+
+	out.WriteString(".SH NAME\n")
+	name := roffEsc(page)
+	if x.Summary != "" {
+		name += ` \- ` + roffEsc(x.Summary)
+	}
+	out.WriteString(name + "\n")
+
+	out.WriteString(".SH SYNOPSIS\n")
+	fmt.Fprintf(&out, ".B %v\n", roffEsc(strings.Join(path, " ")))
+	if usage := usageOf(x); usage != "" {
+		out.WriteString(roffLine(usage) + "\n")
+	}
+
+	if x.Description != "" {
+		out.WriteString(".SH DESCRIPTION\n")
+		out.WriteString(roffBlocks(x.Fill(x.Description)))
+	}
+
+	if x.constrained() {
+		out.WriteString(".SH PARAMS\n")
+		for i, p := range usageParams(x.Params) {
+			fmt.Fprintf(&out, ".TP\n.B %v\n", roffEsc(p))
+			if notes := x.paramNotes(x.Params[i]); len(notes) > 0 {
+				out.WriteString(roffLine(strings.Join(notes, "; ")) + "\n")
+			}
+		}
+	}
+
+	if flags := x.docFlags(); len(flags) > 0 {
+		out.WriteString(".SH FLAGS\n")
+		for _, f := range flags {
+			fmt.Fprintf(&out, ".TP\n.B %v\n", roffEsc(f.Usage()))
+			summary := f.Summary
+			if f.Default != "" {
+				summary = strings.TrimSpace(summary + " (default: " + f.Default + ")")
+			}
+			if summary != "" {
+				out.WriteString(roffLine(summary) + "\n")
+			}
+		}
+	}
+
+	if len(x.EnvVars) > 0 {
+		out.WriteString(".SH ENVIRONMENT\n")
+		for _, e := range x.EnvVars {
+			fmt.Fprintf(&out, ".TP\n.B %v\n", roffEsc(e.Name))
+			if u := docSummary(e.Summary, e.Default, e.Required); u != "" {
+				out.WriteString(roffLine(u) + "\n")
+			}
+		}
+	}
+
+	if len(x.ConfKeys) > 0 {
+		out.WriteString(".SH CONFIGURATION\n")
+		for _, k := range x.ConfKeys {
+			fmt.Fprintf(&out, ".TP\n.B %v\n", roffEsc(x.pathKey(k.Key)))
+			if u := docSummary(k.Summary, k.Default, k.Required); u != "" {
+				out.WriteString(roffLine(u) + "\n")
+			}
+		}
+	}
+
+	if len(x.Examples) > 0 {
+		out.WriteString(".SH EXAMPLES\n")
+		for _, e := range x.Examples {
+			fmt.Fprintf(&out, ".TP\n.B %v\n", roffEsc(x.Invocation(e.Cmd)))
+			if e.Note != "" {
+				out.WriteString(roffLine(e.Note) + "\n")
+			}
+		}
+	}
+
+	if subs := docCommands(x); len(subs) > 0 {
+		out.WriteString(".SH COMMANDS\n")
+		for _, c := range subs {
+			fmt.Fprintf(&out, ".TP\n.B %v\n", roffEsc(c.Name))
+			if c.Summary != "" {
+				out.WriteString(roffLine(c.Summary) + "\n")
+			}
+		}
+	}
+
+	for _, s := range x.Other {
+		fmt.Fprintf(&out, ".SH %v\n", roffEsc(strings.ToUpper(s.Title)))
+		out.WriteString(roffBlocks(x.Fill(s.Body)))
+	}
+
+	if x.Copyright != "" || x.License != "" {
+		out.WriteString(".SH COPYRIGHT\n")
+		if x.Copyright != "" {
+			out.WriteString(roffLine(x.Copyright) + "\n")
+		}
+		if x.License != "" {
+			if x.Copyright != "" {
+				out.WriteString(".br\n")
+			}
+			out.WriteString(roffLine("License "+x.License) + "\n")
+		}
+	}
+
+	return out.String()
+}
+
+// roffEsc escapes the characters that are special to roff within
+// a line (backslash and dash).
+func roffEsc(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\e`)
+	return strings.ReplaceAll(s, `-`, `\-`)
+}
+
+// roffLine escapes a full line of text (see roffEsc) protecting any
+// leading dot or single quote from being interpreted as a request.
+func roffLine(s string) string {
+	s = roffEsc(s)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
+
+// roffBlocks converts the BonzaiMark blocks of text (see Blocks) into
+// roff paragraphs.
+func roffBlocks(text string) string {
+	var out strings.Builder
+	for _, b := range Blocks(text) {
+		out.WriteString(".PP\n")
+		switch b.T {
+		case Paragraph:
+			out.WriteString(roffLine(strings.TrimSpace(b.String())) + "\n")
+		default:
+			if b.T == Verbatim {
+				out.WriteString(".RS 4\n")
+			}
+			out.WriteString(".nf\n")
+			for _, l := range Lines(b.String()) {
+				out.WriteString(roffLine(l) + "\n")
+			}
+			out.WriteString(".fi\n")
+			if b.T == Verbatim {
+				out.WriteString(".RE\n")
+			}
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,117 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"fmt"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func ExampleToMan() {
+	x := docTree()
+	x.Version = `v1.0.0`
+	x.Copyright = `Copyright 2022 Some One`
+	x.License = `Apache-2.0`
+	x.Examples = []Z.Example{{`db migrate up`, `Migrate up.`}}
+	x.Description = `
+		The *foo* command does all the things
+		with the --things.
+
+		    foo db migrate up
+		    .dot \n
+
+		See the db command.
+		`
+	fmt.Print(Z.ToMan(x, 1))
+
+	// Output:
+	// .TH "FOO" "1" "" "foo v1.0.0" ""
+	// .SH NAME
+	// foo \- foo the things
+	// .SH SYNOPSIS
+	// .B foo
+	// (d|db)
+	// .SH DESCRIPTION
+	// .PP
+	// The *foo* command does all the things with the \-\-things.
+	// .PP
+	// .RS 4
+	// .nf
+	// foo db migrate up
+	// \&.dot \en
+	// .fi
+	// .RE
+	// .PP
+	// See the db command.
+	// .SH EXAMPLES
+	// .TP
+	// .B foo db migrate up
+	// Migrate up.
+	// .SH COMMANDS
+	// .TP
+	// .B db
+	// database | things
+	// .SH NOTES
+	// .PP
+	// Notes go here.
+	// .SH BUGS
+	// .PP
+	// No bugs | ever.
+	// .SH COPYRIGHT
+	// Copyright 2022 Some One
+	// .br
+	// License Apache\-2.0
+}
+
+func ExampleToMan_sub() {
+	x := docTree()
+	db, _ := x.Seek([]string{"db"})
+	fmt.Print(Z.ToMan(db, 1))
+
+	// Output:
+	// .TH "FOO\-DB" "1" "" "foo" ""
+	// .SH NAME
+	// foo\-db \- database | things
+	// .SH SYNOPSIS
+	// .B foo db
+	// migrate
+	// .SH COMMANDS
+	// .TP
+	// .B migrate
+	// migrate the schema
+}
+
+func ExampleToMan_params() {
+	x := &Z.Cmd{
+		Name:   `export`,
+		Params: []string{"json", "yaml", "delete", "force", "to="},
+		Excl:   [][]string{{"json", "yaml"}},
+		Needs:  map[string][]string{"force": {"delete"}},
+		Call:   func(*Z.Cmd, ...string) error { return nil },
+	}
+	fmt.Print(Z.ToMan(x, 1))
+
+	// Output:
+	// .TH "EXPORT" "1" "" "export" ""
+	// .SH NAME
+	// export
+	// .SH SYNOPSIS
+	// .B export
+	// [json|yaml|delete|force|to=<value>]...
+	// .SH PARAMS
+	// .TP
+	// .B json
+	// not with yaml
+	// .TP
+	// .B yaml
+	// not with json
+	// .TP
+	// .B delete
+	// .TP
+	// .B force
+	// requires delete
+	// .TP
+	// .B to=<value>
+}
@@ -0,0 +1,251 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MapConf is an in-memory bonzai.Configurer backed by a map of full
+// dotted paths (without the leading dot, ex: db.migrate.url) to values
+// that also implements bonzai.ConfigurerWriter. It is the blessed way
+// to unit test commands with ReqConf (or that use Q, QE, QInt, etc.)
+// without depending on a real configuration implementation:
+//
+//     conf := Z.NewMapConf("db.migrate.url", "db://test")
+//     Z.Conf = conf
+//     defer func() { Z.Conf = nil }()
+//     // ... run the command ...
+//     fmt.Println(conf.WasQueried("db.migrate.url"))
+//
+// Queries follow the same conventions as the yq queries of real
+// configurers for simple paths: an optional leading dot, an exact key
+// returns its value, a path that is the prefix of other keys returns
+// the whole subtree (as JSON, which is also YAML), a single dot
+// returns everything, and anything else returns "null". A trailing
+// "| @json" returns the value as JSON. Values that are valid JSON
+// arrays or objects (ex: ["a","b"]) are embedded as such in subtrees,
+// all other values are strings. Every query path is recorded in order
+// (see Queries and WasQueried).
+type MapConf struct {
+	Map     map[string]string
+	queries []string
+	mu      sync.Mutex
+}
+
+// NewMapConf returns a new MapConf seeded with the keys and values
+// passed in pairs (ex: "db.port", "5432", "env", "dev"). A key without
+// a value is set to an empty string.
+func NewMapConf(kv ...string) *MapConf {
+	c := &MapConf{Map: map[string]string{}}
+	for i := 0; i < len(kv); i += 2 {
+		var val string
+		if i+1 < len(kv) {
+			val = kv[i+1]
+		}
+		c.Map[kv[i]] = val
+	}
+	return c
+}
+
+// Set fulfills the bonzai.ConfigurerWriter interface by replacing the
+// full dotted key (with optional leading dot) and every key under it
+// with the value. JSON objects are flattened into keys (see OverWrite).
+func (c *MapConf) Set(key, val string) error {
+	key = strings.TrimPrefix(key, ".")
+	if key == "" {
+		return fmt.Errorf("invalid config key: %q", key)
+	}
+	m := map[string]string{key: val}
+	if t := strings.TrimSpace(val); strings.HasPrefix(t, "{") {
+		var v any
+		if err := json.Unmarshal([]byte(t), &v); err == nil {
+			m = map[string]string{}
+			flatten(m, key, v)
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Map == nil {
+		c.Map = map[string]string{}
+	}
+	for k := range c.Map {
+		if k == key || strings.HasPrefix(k, key+".") {
+			delete(c.Map, k)
+		}
+	}
+	for k, v := range m {
+		c.Map[k] = v
+	}
+	return nil
+}
+
+// Queries returns the paths of every query so far in order.
+func (c *MapConf) Queries() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string{}, c.queries...)
+}
+
+// WasQueried returns true if the full dotted key has been queried.
+func (c *MapConf) WasQueried(key string) bool {
+	for _, q := range c.Queries() {
+		if q == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Init fulfills the bonzai.Configurer interface by removing all keys
+// and queries.
+func (c *MapConf) Init() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Map = map[string]string{}
+	c.queries = nil
+	return nil
+}
+
+// Data fulfills the bonzai.Configurer interface with everything as
+// JSON.
+func (c *MapConf) Data() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tree("")
+}
+
+// Print fulfills the bonzai.Configurer interface.
+func (c *MapConf) Print() { fmt.Println(c.Data()) }
+
+// Edit fulfills the bonzai.Configurer (and bonzai.ConfigurerWriter)
+// interface but does nothing.
+func (c *MapConf) Edit() error { return nil }
+
+// OverWrite fulfills the bonzai.Configurer interface by replacing all
+// keys with those of with (marshaled as JSON) flattened into full
+// dotted paths. Strings are kept as is and all other values (arrays,
+// empty objects, numbers, booleans) as JSON. Nulls are dropped.
+func (c *MapConf) OverWrite(with any) error {
+	buf, err := json.Marshal(with)
+	if err != nil {
+		return err
+	}
+	var v any
+	if err := json.Unmarshal(buf, &v); err != nil {
+		return err
+	}
+	m := map[string]string{}
+	if err := flatten(m, "", v); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Map = m
+	return nil
+}
+
+func flatten(m map[string]string, key string, v any) error {
+	switch v := v.(type) {
+	case nil:
+	case string:
+		m[key] = v
+	case map[string]any:
+		if len(v) == 0 && key != "" {
+			m[key] = "{}"
+		}
+		for k, sub := range v {
+			if key != "" {
+				k = key + "." + k
+			}
+			if err := flatten(m, k, sub); err != nil {
+				return err
+			}
+		}
+	default:
+		if key == "" {
+			return fmt.Errorf("cannot overwrite configuration with %T", v)
+		}
+		buf, _ := json.Marshal(v)
+		m[key] = string(buf)
+	}
+	return nil
+}
+
+// Query fulfills the bonzai.Configurer interface (see MapConf).
+func (c *MapConf) Query(q string) string {
+	q = strings.TrimSpace(q)
+	asJSON := strings.HasSuffix(q, "| @json")
+	q = strings.TrimSuffix(q, "| @json")
+	q = strings.TrimPrefix(strings.TrimSpace(q), ".")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.queries = append(c.queries, q)
+	if v, has := c.Map[q]; has && q != "" {
+		if asJSON {
+			return string(jsonLeaf(v))
+		}
+		return v
+	}
+	return c.tree(q)
+}
+
+// QueryPrint fulfills the bonzai.Configurer interface.
+func (c *MapConf) QueryPrint(q string) { fmt.Println(c.Query(q)) }
+
+// tree returns the subtree of all keys under the path as JSON (or
+// everything if path is empty) or "null" if there are none.
+func (c *MapConf) tree(path string) string {
+	prefix := path
+	if prefix != "" {
+		prefix += "."
+	}
+	keys := make([]string, 0, len(c.Map))
+	for k := range c.Map {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		if path == "" {
+			return "{}"
+		}
+		return "null"
+	}
+	sort.Strings(keys)
+	root := map[string]any{}
+	for _, k := range keys {
+		parts := strings.Split(strings.TrimPrefix(k, prefix), ".")
+		node := root
+		for _, p := range parts[:len(parts)-1] {
+			sub, ok := node[p].(map[string]any)
+			if !ok {
+				sub = map[string]any{}
+				node[p] = sub
+			}
+			node = sub
+		}
+		last := parts[len(parts)-1]
+		if _, isTree := node[last].(map[string]any); !isTree {
+			node[last] = jsonLeaf(c.Map[k])
+		}
+	}
+	buf, _ := json.Marshal(root)
+	return string(buf)
+}
+
+// jsonLeaf returns the value as is if it is a JSON array or object or
+// as a JSON string otherwise.
+func jsonLeaf(v string) json.RawMessage {
+	t := strings.TrimSpace(v)
+	if (strings.HasPrefix(t, "[") || strings.HasPrefix(t, "{")) && json.Valid([]byte(t)) {
+		return json.RawMessage(t)
+	}
+	buf, _ := json.Marshal(v)
+	return buf
+}
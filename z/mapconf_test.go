@@ -0,0 +1,80 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"fmt"
+	"os"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func ExampleMapConf() {
+	conf := Z.NewMapConf(
+		"db.migrate.url", "db://test",
+		"db.migrate.steps", "3",
+		"db.hosts", `["a","b"]`,
+		"env", "dev",
+	)
+	fmt.Println(conf.Query(".db.migrate.url"))
+	fmt.Println(conf.Query(".env | @json"))
+	fmt.Println(conf.Query(".db"))
+	fmt.Println(conf.Query(".nope"))
+	fmt.Println(conf.Query("."))
+	fmt.Println(conf.Queries())
+	fmt.Println(conf.WasQueried("db.migrate.url"), conf.WasQueried("db.migrate"))
+
+	// Output:
+	// db://test
+	// "dev"
+	// {"hosts":["a","b"],"migrate":{"steps":"3","url":"db://test"}}
+	// null
+	// {"db":{"hosts":["a","b"],"migrate":{"steps":"3","url":"db://test"}},"env":"dev"}
+	// [db.migrate.url env db nope ]
+	// true false
+}
+
+func ExampleMapConf_OverWrite() {
+	conf := Z.NewMapConf("old", "gone")
+	conf.OverWrite(map[string]any{
+		"db":   map[string]any{"port": 5432, "tls": true, "url": "db://x"},
+		"tags": []string{"a"},
+		"none": nil,
+		"opts": map[string]any{},
+	})
+	fmt.Println(conf.Map)
+	fmt.Println(conf.Data())
+
+	// Output:
+	// map[db.port:5432 db.tls:true db.url:db://x opts:{} tags:["a"]]
+	// {"db":{"port":"5432","tls":"true","url":"db://x"},"opts":{},"tags":["a"]}
+}
+
+func ExampleCmd_Run_reqconf() {
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	conf := Z.NewMapConf("greet.name", "Mr. Rob")
+	Z.Conf = conf
+	defer func() { Z.Conf = nil }()
+
+	x := &Z.Cmd{
+		Name: `foo`,
+		Commands: []*Z.Cmd{{
+			Name:    `greet`,
+			ReqConf: true,
+			Call: func(x *Z.Cmd, _ ...string) error {
+				fmt.Println("hello", x.Q("name"))
+				return nil
+			},
+		}},
+	}
+	orig := os.Args
+	defer func() { os.Args = orig }()
+	os.Args = []string{"foo", "greet"}
+	x.Run()
+	fmt.Println(conf.WasQueried("greet.name"))
+
+	// Output:
+	// hello Mr. Rob
+	// true
+}
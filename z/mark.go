@@ -4,11 +4,9 @@ import (
 	"fmt"
 	"regexp"
 	"strconv"
+	"strings"
 	"unicode"
-
-	"github.com/rwxrob/scan"
-	"github.com/rwxrob/term"
-	"github.com/rwxrob/to"
+	"unicode/utf8"
 )
 
 // IndentBy is the number of spaces to indent in Indent. Default is 7.
@@ -18,13 +16,91 @@ var IndentBy = 7
 
 // Columns is the number of bytes (not runes) at which Wrap will wrap.
 // By default detects the terminal width (if possible) otherwise keeps
-// 80 standard (see rwxrob/term.WinSize). Bonzai command tree creator
-// can change this for every composite command imported their
-// application in this one place.
-var Columns = int(term.WinSize.Col)
+// 80 standard (see Term). Bonzai command tree creator can change this
+// for every composite command imported their application in this one
+// place. Tests and output piped to files should set it explicitly.
+var Columns = columns()
+
+func columns() int {
+	if _, cols := Term.Size(); Term.IsInteractive() && cols > 0 {
+		return cols
+	}
+	return 80
+}
+
+// Lines returns the string converted into a slice of lines. A final
+// line ending does not add an empty line and carriage returns before
+// line endings are dropped.
+func Lines(in string) []string {
+	lines := strings.Split(in, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
+
+// dedent discards any initial lines with nothing but white space and
+// then removes as many bytes as the first line has leading white space
+// runes from every line that is at least that long. It is up to the
+// content creator to ensure that all lines have the same indentation.
+func dedent(in string) string {
+	lines := Lines(in)
+	var n int
+	for n < len(lines) && strings.TrimSpace(lines[n]) == "" {
+		n++
+	}
+	if n == len(lines) {
+		return ""
+	}
+	starts := n
+	indent := len([]rune(lines[n])) - len([]rune(strings.TrimLeftFunc(lines[n], unicode.IsSpace)))
+	for ; n < len(lines); n++ {
+		if len(lines[n]) >= indent {
+			lines[n] = lines[n][indent:]
+		}
+	}
+	return strings.Join(lines[starts:], "\n")
+}
+
+// wrapped compresses all white space in the input to single spaces
+// and wraps the words at the given width (in bytes) never breaking
+// a word even if it is longer than the width. Any width less than
+// 1 does no wrapping at all.
+func wrapped(in string, width int) string {
+	words := strings.Fields(in)
+	if width < 1 {
+		return strings.Join(words, " ")
+	}
+	var buf strings.Builder
+	var cur int
+	for i, word := range words {
+		switch {
+		case i == 0:
+		case cur+len(word) > width:
+			buf.WriteByte('\n')
+			cur = 0
+		default:
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(word)
+		cur += len(word) + 1
+	}
+	return buf.String()
+}
 
-// Lines returns the string converted into a slice of lines.
-func Lines(in string) []string { return to.Lines(in) }
+// indented indents every line by the number of spaces ending each with
+// a line return (dropping carriage returns, see Lines).
+func indented(in string, indent int) string {
+	var buf strings.Builder
+	pre := strings.Repeat(" ", indent)
+	for _, line := range Lines(in) {
+		buf.WriteString(pre + line + "\n")
+	}
+	return buf.String()
+}
 
 const (
 	Paragraph = iota + 1
@@ -70,7 +146,7 @@ func Blocks(in string) []*Block {
 
 	var blocks []*Block
 	verbpre := regexp.MustCompile(` {4,}`)
-	s := scan.R{Buf: []byte(to.Dedented(in))}
+	s := scanner{Buf: []byte(dedent(in))}
 
 MAIN:
 	for s.Scan() {
@@ -180,8 +256,10 @@ MAIN:
 //     ***BoldItalic***
 //     <under> (keeping brackets)
 //
-// See Mark for block formatting and rwxrob/term for terminal rendering.
+// See Mark for block formatting and Term (Attrs) for terminal
+// rendering.
 func Emph(buf string) string {
+	attrs := Term.Attrs()
 	var nbuf []rune
 	var opentok, closetok bool
 	var otok, ctok string
@@ -192,7 +270,7 @@ func Emph(buf string) string {
 
 		if r == '<' {
 			nbuf = append(nbuf, '<')
-			nbuf = append(nbuf, []rune(term.Under)...)
+			nbuf = append(nbuf, []rune(attrs.Under)...)
 			for {
 				i++
 				r = rune(buf[i])
@@ -202,7 +280,7 @@ func Emph(buf string) string {
 				}
 				nbuf = append(nbuf, r)
 			}
-			nbuf = append(nbuf, []rune(term.Reset)...)
+			nbuf = append(nbuf, []rune(attrs.Reset)...)
 			nbuf = append(nbuf, '>')
 			i--
 			continue
@@ -215,11 +293,11 @@ func Emph(buf string) string {
 				if !unicode.IsSpace(r) {
 					switch otok {
 					case "*":
-						tokval = term.Italic
+						tokval = attrs.Italic
 					case "**":
-						tokval = term.Bold
+						tokval = attrs.Bold
 					case "***":
-						tokval = term.BoldItalic
+						tokval = attrs.BoldItalic
 					}
 				} else {
 					tokval = otok
@@ -230,7 +308,7 @@ func Emph(buf string) string {
 			}
 
 			if closetok {
-				nbuf = append(nbuf, []rune(term.Reset)...) // practical, not perfect
+				nbuf = append(nbuf, []rune(attrs.Reset)...) // practical, not perfect
 				ctok = ""
 				closetok = false
 			}
@@ -261,22 +339,146 @@ func Emph(buf string) string {
 
 	// for tokens at the end of a block
 	if closetok {
-		nbuf = append(nbuf, []rune(term.Reset)...)
+		nbuf = append(nbuf, []rune(attrs.Reset)...)
 	}
 
 	return string(nbuf)
 }
 
 // Wrap wraps to Columns width.
-func Wrap(in string) string { w, _ := to.Wrapped(in, Columns); return w }
+func Wrap(in string) string { return wrapped(in, Columns) }
 
 // Indent indents the number of spaces set by IndentBy.
-func Indent(in string) string { return to.Indented(in, IndentBy) }
+func Indent(in string) string { return indented(in, IndentBy) }
 
 // InWrap combines both Wrap and Indent.
 func InWrap(in string) string {
-	w, _ := to.Wrapped(in, Columns-IndentBy)
-	return to.Indented(w, IndentBy)
+	return indented(wrapped(in, Columns-IndentBy), IndentBy)
+}
+
+// Hanging wraps the words of the input to the given width assuming the
+// first line begins at the indent column (for example, after a name
+// column) and indents every following line by indent spaces to align
+// with it. The first line is never indented. Width is measured in
+// terminal cells (see Width) rather than bytes. If there is no room to
+// wrap (width less than or equal to indent) the words are simply
+// joined with single spaces.
+func Hanging(in string, width, indent int) string {
+	words := strings.Fields(in)
+	if len(words) == 0 {
+		return ""
+	}
+	room := width - indent
+	if room <= 0 {
+		return strings.Join(words, " ")
+	}
+	pad := "\n" + strings.Repeat(" ", indent)
+	out := words[0]
+	cur := Width(words[0])
+	for _, w := range words[1:] {
+		n := Width(w)
+		if cur+1+n > room {
+			out += pad + w
+			cur = n
+			continue
+		}
+		out += " " + w
+		cur += 1 + n
+	}
+	return out
+}
+
+// Width returns the number of terminal cells (columns) needed to
+// display the string, counting East Asian wide runes (CJK, emoji) as
+// two cells and combining marks, control characters, and ANSI escape
+// sequences (see Style) as none.
+func Width(in string) int {
+	var n int
+	var esc bool
+	for _, r := range in {
+		switch {
+		case esc:
+			esc = inEsc(r)
+		case r == 0x1B:
+			esc = true
+		default:
+			n += runeWidth(r)
+		}
+	}
+	return n
+}
+
+// inEsc returns true if the rune following the start of an ANSI escape
+// sequence (or any of it so far) does not end it.
+func inEsc(r rune) bool {
+	return r == '[' || (r >= 0x30 && r <= 0x3F) || (r >= 0x20 && r <= 0x2F)
+}
+
+// runeWidth returns the Width of the rune alone (outside of any escape
+// sequence).
+func runeWidth(r rune) int {
+	switch {
+	case unicode.Is(unicode.Mn, r) || unicode.IsControl(r):
+		return 0
+	case isWide(r):
+		return 2
+	}
+	return 1
+}
+
+// TruncateDisplay returns the string cut to fit within the width (see
+// Width) ending with an ellipsis (…) if anything was removed. Wide
+// runes are never split and combining marks stay with the rune they
+// follow. ANSI escape sequences (see Style) take no room and are never
+// cut, and any after the cut are kept (following the ellipsis) so that
+// styles are still reset. An empty string is returned if the width is
+// zero or less.
+func TruncateDisplay(s string, width int) string {
+	if Width(s) <= width {
+		return s
+	}
+	if width <= 0 {
+		return ""
+	}
+	var out strings.Builder
+	var n int
+	var esc, cut bool
+	for _, r := range s {
+		switch {
+		case esc:
+			esc = inEsc(r)
+			out.WriteRune(r)
+			continue
+		case r == 0x1B:
+			esc = true
+			out.WriteRune(r)
+			continue
+		case cut:
+			continue
+		}
+		if rn := runeWidth(r); n+rn > width-1 {
+			out.WriteString("…")
+			cut = true
+			continue
+		} else {
+			n += rn
+		}
+		out.WriteRune(r)
+	}
+	return out.String()
+}
+
+func isWide(r rune) bool {
+	return r >= 0x1100 && (r <= 0x115F ||
+		(r >= 0x2E80 && r <= 0xA4CF && r != 0x303F) ||
+		(r >= 0xAC00 && r <= 0xD7A3) ||
+		(r >= 0xF900 && r <= 0xFAFF) ||
+		(r >= 0xFE30 && r <= 0xFE4F) ||
+		(r >= 0xFF00 && r <= 0xFF60) ||
+		(r >= 0xFFE0 && r <= 0xFFE6) ||
+		(r >= 0x1F300 && r <= 0x1F64F) ||
+		(r >= 0x1F900 && r <= 0x1F9FF) ||
+		(r >= 0x20000 && r <= 0x3FFFD))
 }
 
 // Mark parses the input as a string of BonzaiMark, multiple blocks with
@@ -304,7 +506,7 @@ func Mark(in string) string {
 		case Numbered:
 			out += Emph(Indent(string(block.V))) + "\n"
 		case Verbatim:
-			out += to.Indented(Indent(string(block.V)), 4) + "\n"
+			out += indented(Indent(string(block.V)), 4) + "\n"
 		default:
 			panic("unknown block type: " + strconv.Itoa(block.T))
 		}
@@ -383,3 +585,43 @@ func PrintInWrapf(a string, f ...any) {
 func PrintMarkf(a string, f ...any) {
 	fmt.Print(Mark(fmt.Sprintf(a, f...)))
 }
+
+// scanner is the minimal rune scanner used by Blocks.
+type scanner struct {
+	Buf  []byte // being scanned
+	Pos  int    // of the next rune in Buf
+	Rune rune   // last scanned
+}
+
+// Scan decodes the next rune into Rune advancing Pos past it and
+// returns false if there are no more.
+func (s *scanner) Scan() bool {
+	if s.Pos >= len(s.Buf) {
+		return false
+	}
+	r, l := utf8.DecodeRune(s.Buf[s.Pos:])
+	s.Rune = r
+	s.Pos += l
+	return true
+}
+
+// Peek returns true if the Buf at Pos begins with the string.
+func (s *scanner) Peek(a string) bool {
+	if s.Pos > len(s.Buf) {
+		return false
+	}
+	return strings.HasPrefix(string(s.Buf[s.Pos:]), a)
+}
+
+// Match returns the length of the match of the regular expression at
+// Pos or -1 if it does not match there.
+func (s *scanner) Match(re *regexp.Regexp) int {
+	if s.Pos < 0 || s.Pos > len(s.Buf) {
+		return -1
+	}
+	loc := re.FindIndex(s.Buf[s.Pos:])
+	if loc == nil || loc[0] != 0 {
+		return -1
+	}
+	return loc[1]
+}
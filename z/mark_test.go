@@ -4,17 +4,23 @@ import (
 	"fmt"
 
 	Z "github.com/rwxrob/bonzai/z"
-	"github.com/rwxrob/term"
 )
 
-func init() {
-	term.Italic = `<italic>`
-	term.Bold = `<bold>`
-	term.BoldItalic = `<bolditalic>`
-	term.Under = `<under>`
-	term.Reset = `<reset>`
+// tagTerm renders emphasis as visible tags (see Emph).
+type tagTerm struct{ Z.Terminal }
+
+func (tagTerm) Attrs() Z.TermAttrs {
+	return Z.TermAttrs{
+		Italic:     `<italic>`,
+		Bold:       `<bold>`,
+		BoldItalic: `<bolditalic>`,
+		Under:      `<under>`,
+		Reset:      `<reset>`,
+	}
 }
 
+func init() { Z.Term = tagTerm{Z.Term} }
+
 func ExampleLines() {
 	fmt.Printf("%q\n", Z.Lines("line one\nline two"))
 	// Output:
@@ -122,14 +128,8 @@ func ExampleBlocks_verbatim() {
 
 func ExampleEmph_basics() {
 
-	// Emph observes the rwxrob/term escapes
-	// (see package documentation for more)
-
-	term.Italic = `<italic>`
-	term.Bold = `<bold>`
-	term.BoldItalic = `<bolditalic>`
-	term.Under = `<under>`
-	term.Reset = `<reset>`
+	// Emph observes the escapes from Z.Term
+	// (see tagTerm in init)
 
 	fmt.Println(Z.Emph("*ITALIC*"))
 	fmt.Println(Z.Emph("**BOLD**"))
@@ -355,14 +355,8 @@ func ExampleMarkf() {
 
 func ExamplePrintEmph_basics() {
 
-	// Emph observes the rwxrob/term escapes
-	// (see package documentation for more)
-
-	term.Italic = `<italic>`
-	term.Bold = `<bold>`
-	term.BoldItalic = `<bolditalic>`
-	term.Under = `<under>`
-	term.Reset = `<reset>`
+	// Emph observes the escapes from Z.Term
+	// (see tagTerm in init)
 
 	Z.PrintEmph("*ITALIC*\n")
 	Z.PrintEmph("**BOLD**\n")
@@ -565,3 +559,42 @@ func ExamplePrintMarkf() {
 	// ----------------------
 
 }
+
+func ExampleHanging() {
+	fmt.Println("name - " + Z.Hanging(`one two three four five six`, 20, 7))
+	fmt.Println(Z.Hanging(`no room to wrap`, 5, 7))
+	// Output:
+	// name - one two three
+	//        four five six
+	// no room to wrap
+}
+
+func ExampleWidth() {
+	fmt.Println(Z.Width("foo"))
+	fmt.Println(Z.Width("日本語"))
+	fmt.Println(Z.Width("e\u0301"))
+	fmt.Println(Z.Width("\033[1mbold\033[0m"))
+	// Output:
+	// 3
+	// 6
+	// 1
+	// 4
+}
+
+func ExampleTruncateDisplay() {
+	fmt.Println(Z.TruncateDisplay("short", 10))
+	fmt.Println(Z.TruncateDisplay("summary too long", 10))
+	fmt.Println(Z.TruncateDisplay("日本語のテキスト", 7))
+	fmt.Printf("%+q\n", Z.TruncateDisplay("cafe\u0301 au lait", 5))
+	fmt.Printf("%q\n", Z.TruncateDisplay("\033[1mbold\033[0m and plain", 6))
+	fmt.Printf("%q\n", Z.TruncateDisplay("\033[1mbold and long\033[0m", 6))
+	fmt.Printf("%q\n", Z.TruncateDisplay("abc", 0))
+	// Output:
+	// short
+	// summary t…
+	// 日本語…
+	// "cafe\u0301\u2026"
+	// "\x1b[1mbold\x1b[0m …"
+	// "\x1b[1mbold …\x1b[0m"
+	// ""
+}
@@ -0,0 +1,245 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DocHidden includes Hidden commands in all generated documentation
+// (see ToMarkdown). By default, they are left out.
+var DocHidden bool
+
+// DocTags includes the Tags of each command (sorted by key) in the
+// generated Markdown (see ToMarkdown). By default, they are left out.
+var DocTags bool
+
+// ToMarkdown renders the entire command tree as a single Markdown
+// document suitable for publishing to a static site. Each command has a
+// heading (using Title, one level deeper for each level of the tree,
+// never more than six) with an anchor built from its full invocation
+// path (ex: foo-db-migrate) followed by a link to its parent, its usage
+// line, Description, Params (only if constrained by Excl or Needs),
+// Flags, EnvVars, ConfKeys, Examples (as a list of full invocations
+// with their notes), Other sections (in declared order), and a table of
+// its Commands (linked to their own sections) with their Summary, one
+// table for each Group (see Groups). Hidden commands are excluded
+// unless DocHidden is true. An error is returned if the tree contains a
+// cycle (see Walk).
+func ToMarkdown(x *Cmd) (string, error) {
+	var out strings.Builder
+	err := docWalk(x, func(c *Cmd, path []string) {
+		level := len(path)
+		if level > 6 {
+			level = 6
+		}
+		fmt.Fprintf(&out, "<a id=%q></a>\n", mdAnchor(path))
+		fmt.Fprintf(&out, "%v %v\n\n", strings.Repeat("#", level), c.Title())
+		if len(path) > 1 {
+			parent := path[:len(path)-1]
+			fmt.Fprintf(&out, "Parent: [%v](#%v)\n\n",
+				strings.Join(parent, " "), mdAnchor(parent))
+		}
+		usage := strings.TrimSpace(strings.Join(path, " ") + " " + usageOf(c))
+		fmt.Fprintf(&out, "**Usage:** `%v`\n\n", usage)
+		if DocTags && len(c.Tags) > 0 {
+			keys := keysWithPrefix(c.Tags, "")
+			var tags []string
+			for _, k := range keys {
+				tags = append(tags, fmt.Sprintf("`%v=%v`", k, c.Tags[k]))
+			}
+			fmt.Fprintf(&out, "**Tags:** %v\n\n", strings.Join(tags, " "))
+		}
+		if c.Description != "" {
+			out.WriteString(strings.TrimSpace(dedent(c.Fill(c.Description))) + "\n\n")
+		}
+		sublevel := strings.Repeat("#", level+1)
+		if level == 6 {
+			sublevel = "######"
+		}
+		if c.constrained() {
+			fmt.Fprintf(&out, "%v Params\n\n", sublevel)
+			for i, p := range usageParams(c.Params) {
+				fmt.Fprintf(&out, "* `%v`", p)
+				if notes := c.paramNotes(c.Params[i]); len(notes) > 0 {
+					out.WriteString(" - " + strings.Join(notes, "; "))
+				}
+				out.WriteString("\n")
+			}
+			out.WriteString("\n")
+		}
+		if flags := c.docFlags(); len(flags) > 0 {
+			fmt.Fprintf(&out, "%v Flags\n\n", sublevel)
+			for _, f := range flags {
+				fmt.Fprintf(&out, "* `%v`", f.Usage())
+				if f.Summary != "" {
+					out.WriteString(" - " + f.Summary)
+				}
+				if f.Default != "" {
+					fmt.Fprintf(&out, " (default: `%v`)", f.Default)
+				}
+				out.WriteString("\n")
+			}
+			out.WriteString("\n")
+		}
+		if len(c.EnvVars) > 0 {
+			fmt.Fprintf(&out, "%v Environment\n\n", sublevel)
+			for _, e := range c.EnvVars {
+				fmt.Fprintf(&out, "* `%v`", e.Name)
+				if u := docSummary(e.Summary, e.Default, e.Required); u != "" {
+					out.WriteString(" - " + u)
+				}
+				out.WriteString("\n")
+			}
+			out.WriteString("\n")
+		}
+		if len(c.ConfKeys) > 0 {
+			fmt.Fprintf(&out, "%v Configuration\n\n", sublevel)
+			for _, k := range c.ConfKeys {
+				fmt.Fprintf(&out, "* `%v`", c.pathKey(k.Key))
+				if u := docSummary(k.Summary, k.Default, k.Required); u != "" {
+					out.WriteString(" - " + u)
+				}
+				out.WriteString("\n")
+			}
+			out.WriteString("\n")
+		}
+		if len(c.Examples) > 0 {
+			fmt.Fprintf(&out, "%v Examples\n\n", sublevel)
+			for _, e := range c.Examples {
+				fmt.Fprintf(&out, "* `%v`", c.Invocation(e.Cmd))
+				if e.Note != "" {
+					out.WriteString(" - " + e.Note)
+				}
+				out.WriteString("\n")
+			}
+			out.WriteString("\n")
+		}
+		for _, s := range c.Other {
+			fmt.Fprintf(&out, "%v %v\n\n", sublevel, s.Title)
+			if s.Body != "" {
+				out.WriteString(strings.TrimSpace(dedent(c.Fill(s.Body))) + "\n\n")
+			}
+		}
+		subs := docCommands(c)
+		if len(subs) == 0 {
+			return
+		}
+		groups := groupCmds(subs)
+		for _, g := range groups {
+			if len(groups) > 1 || g.Name != "" {
+				name := g.Name
+				if name == "" {
+					name = ungroupedText()
+				}
+				fmt.Fprintf(&out, "**%v**\n\n", name)
+			}
+			out.WriteString("| Command | Summary |\n|---|---|\n")
+			for _, s := range g.Commands {
+				fmt.Fprintf(&out, "| [%v](#%v) | %v |\n", s.Name,
+					mdAnchor(append(path[:len(path):len(path)], s.Name)),
+					strings.ReplaceAll(s.Summary, "|", `\|`))
+			}
+			out.WriteString("\n")
+		}
+	})
+	return out.String(), err
+}
+
+func mdAnchor(path []string) string {
+	return strings.ToLower(strings.Join(path, "-"))
+}
+
+// usageOf returns the usage line of the Cmd (without the "usage:" or
+// name prefix) from its Usage, its own UsageFunc, or the package
+// UsageFunc, in that order.
+func usageOf(x *Cmd) string {
+	if x.Usage != "" {
+		return x.Usage
+	}
+	usage := x.UsageFunc
+	if usage == nil {
+		usage = UsageFunc
+	}
+	return usage(x)
+}
+
+// docCommands returns the Commands of x that should be documented
+// (see DocHidden).
+func docCommands(x *Cmd) []*Cmd {
+	var list []*Cmd
+	for _, c := range x.AllCommands() {
+		if c.Name == "" || (!DocHidden && x.IsHidden(c.Name)) {
+			continue
+		}
+		list = append(list, c)
+	}
+	return list
+}
+
+// docWalk calls fn for every command to be documented (see
+// docCommands) depth-first passing the full invocation path including
+// the Name of the root. As with Seek, each is bound to its Caller along
+// the way (see bind). Cycles are errors (see Walk).
+func docWalk(x *Cmd, fn func(c *Cmd, path []string)) error {
+	return docwalk(x, []string{x.Name}, map[*Cmd]bool{x: true}, fn)
+}
+
+// docwalk marks the unbound Commands in seen since bind may return
+// a copy.
+func docwalk(
+	x *Cmd, path []string, seen map[*Cmd]bool, fn func(*Cmd, []string),
+) error {
+	fn(x, path)
+	for _, c := range docCommands(x) {
+		p := append(path[:len(path):len(path)], c.Name)
+		if seen[c] {
+			return fmt.Errorf("cycle: %q contains itself", strings.Join(p, " "))
+		}
+		seen[c] = true
+		err := docwalk(bind(c, x), p, seen, fn)
+		delete(seen, c)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DocsCmd is an optional builtin branch command (see Builtins) for
+// generating documentation for the entire command tree from the root.
+var DocsCmd = &Cmd{
+	Name:     `docs`,
+	Summary:  `generate documentation for the command tree`,
+	Commands: []*Cmd{docsMarkdownCmd, docsManCmd, WebCmd},
+}
+
+var docsMarkdownCmd = &Cmd{
+	Name:    `markdown`,
+	Aliases: []string{"md"},
+	Summary: `print documentation as Markdown`,
+	Call: func(x *Cmd, _ ...string) error {
+		out, err := ToMarkdown(x.Root())
+		if err != nil {
+			return err
+		}
+		Page(out)
+		return nil
+	},
+}
+
+var docsManCmd = &Cmd{
+	Name:    `man`,
+	Summary: `print a man page (roff) for a command path`,
+	Usage:   `[COMMAND ...]`,
+	Call: func(x *Cmd, args ...string) error {
+		cmd, rest := x.Root().Seek(args)
+		if len(rest) > 0 {
+			return fmt.Errorf("command not found: %v", strings.Join(args, " "))
+		}
+		Page(ToMan(cmd, 1))
+		return nil
+	},
+}
@@ -0,0 +1,135 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"fmt"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+// docTree adds what is documented to the shared tree (see fooTree).
+func docTree() *Z.Cmd {
+	x := fooTree()
+	x.Summary = `foo the things`
+	x.Description = `
+		The *foo* command does all the things
+		with the things.
+		`
+	x.Other = []Z.Section{
+		{`Notes`, `Notes go here.`},
+		{`Bugs`, `No bugs | ever.`},
+	}
+	migrate := x.Commands[0].Commands[0]
+	migrate.MinParm = 1
+	migrate.MaxParm = 1
+	return x
+}
+
+func ExampleToMarkdown() {
+	out, err := Z.ToMarkdown(docTree())
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Print(out)
+
+	// Output:
+	// <a id="foo"></a>
+	// # foo - foo the things
+	//
+	// **Usage:** `foo (d|db)`
+	//
+	// The *foo* command does all the things
+	// with the things.
+	//
+	// ## Notes
+	//
+	// Notes go here.
+	//
+	// ## Bugs
+	//
+	// No bugs | ever.
+	//
+	// | Command | Summary |
+	// |---|---|
+	// | [db](#foo-db) | database \| things |
+	//
+	// <a id="foo-db"></a>
+	// ## db - database | things
+	//
+	// Parent: [foo](#foo)
+	//
+	// **Usage:** `foo db migrate`
+	//
+	// | Command | Summary |
+	// |---|---|
+	// | [migrate](#foo-db-migrate) | migrate the schema |
+	//
+	// <a id="foo-db-migrate"></a>
+	// ### migrate - migrate the schema
+	//
+	// Parent: [foo db](#foo-db)
+	//
+	// **Usage:** `foo db migrate (up|down)`
+}
+
+func ExampleToMarkdown_hidden() {
+	Z.DocHidden = true
+	defer func() { Z.DocHidden = false }()
+	x := docTree()
+	x.Commands = x.Commands[1:]
+	out, _ := Z.ToMarkdown(x)
+	fmt.Print(out)
+
+	// Output:
+	// <a id="foo"></a>
+	// # foo - foo the things
+	//
+	// **Usage:** `foo`
+	//
+	// The *foo* command does all the things
+	// with the things.
+	//
+	// ## Notes
+	//
+	// Notes go here.
+	//
+	// ## Bugs
+	//
+	// No bugs | ever.
+	//
+	// | Command | Summary |
+	// |---|---|
+	// | [secret](#foo-secret) | shh |
+	//
+	// <a id="foo-secret"></a>
+	// ## secret - shh
+	//
+	// Parent: [foo](#foo)
+	//
+	// **Usage:** `foo secret`
+	//
+}
+
+func ExampleToMarkdown_cycle() {
+	call := func(_ *Z.Cmd, _ ...string) error { return nil }
+	shared := &Z.Cmd{Name: `shared`, Call: call}
+	loop := &Z.Cmd{Name: `loop`}
+	loop.Commands = []*Z.Cmd{loop}
+	x := &Z.Cmd{
+		Name: `foo`,
+		Commands: []*Z.Cmd{
+			{Name: `one`, Commands: []*Z.Cmd{shared}},
+			{Name: `two`, Commands: []*Z.Cmd{shared}},
+		},
+	}
+	_, err := Z.ToMarkdown(x)
+	fmt.Println(err)
+	x.Commands = append(x.Commands, loop)
+	_, err = Z.ToMarkdown(x)
+	fmt.Println(err)
+	// Output:
+	// <nil>
+	// cycle: "foo loop loop" contains itself
+}
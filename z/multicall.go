@@ -0,0 +1,164 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+)
+
+// MulticallDryRun causes InstallMulticall and UninstallMulticall to
+// only print what they would do (one line per file) without changing
+// anything.
+var MulticallDryRun bool
+
+// multicallNames returns the sorted names of Commands (less the
+// reserved fallback).
+func multicallNames() []string {
+	var names []string
+	for k := range Commands {
+		if k != "" {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// multicallPath returns the path within dir for the multicall name
+// (with .exe added on Windows).
+func multicallPath(dir, name string) string {
+	path := filepath.Join(dir, name)
+	if runtime.GOOS == "windows" {
+		path += ".exe"
+	}
+	return path
+}
+
+// InstallMulticall creates a symbolic link (or a hard link if symbolic
+// links cannot be created, or a copy on Windows) to the ExePath in the
+// dir for every name in Commands (see Run). Existing symbolic links are
+// updated, but any other existing file is an error and nothing is
+// overwritten. See MulticallDryRun.
+func InstallMulticall(dir string) error {
+	for _, name := range multicallNames() {
+		path := multicallPath(dir, name)
+		if MulticallDryRun {
+			fmt.Printf("%v -> %v\n", path, ExePath)
+			continue
+		}
+		fi, err := os.Lstat(path)
+		switch {
+		case err == nil && fi.Mode()&fs.ModeSymlink == 0:
+			return fmt.Errorf("refusing to overwrite: %v", path)
+		case err == nil:
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+		case !errors.Is(err, fs.ErrNotExist):
+			return err
+		}
+		if runtime.GOOS == "windows" {
+			err = copyFile(ExePath, path)
+		} else if err = os.Symlink(ExePath, path); err != nil {
+			err = os.Link(ExePath, path)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UninstallMulticall removes the files created by InstallMulticall
+// from dir skipping any that do not exist. Anything other than
+// a symbolic link (or a regular file on Windows) is an error. See
+// MulticallDryRun.
+func UninstallMulticall(dir string) error {
+	for _, name := range multicallNames() {
+		path := multicallPath(dir, name)
+		fi, err := os.Lstat(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if fi.Mode()&fs.ModeSymlink == 0 &&
+			!(runtime.GOOS == "windows" && fi.Mode().IsRegular()) {
+			return fmt.Errorf("refusing to remove: %v", path)
+		}
+		if MulticallDryRun {
+			fmt.Printf("rm %v\n", path)
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFile(from, to string) error {
+	in, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(to, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0755)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// MulticallCmd is an optional hidden builtin branch command (see
+// Builtins) with install and uninstall subcommands calling
+// InstallMulticall and UninstallMulticall for the directory passed as
+// the only argument. The --dry-run (-n) flag sets MulticallDryRun.
+var MulticallCmd = &Cmd{
+	Name:    `multicall`,
+	Summary: `install or uninstall multicall links`,
+	Hide:    true,
+	Flags: []Flag{
+		{Name: `dry-run`, Short: `n`, Summary: `only print what would be done`},
+	},
+	Commands: []*Cmd{
+		&Cmd{
+			Name:    `install`,
+			Summary: `link every multicall name in DIR to this executable`,
+			Usage:   `DIR`,
+			Call: func(x *Cmd, args ...string) error {
+				return multicallDo(x, args, InstallMulticall)
+			},
+		},
+		&Cmd{
+			Name:    `uninstall`,
+			Summary: `remove the multicall links from DIR`,
+			Usage:   `DIR`,
+			Call: func(x *Cmd, args ...string) error {
+				return multicallDo(x, args, UninstallMulticall)
+			},
+		},
+	},
+}
+
+func multicallDo(x *Cmd, args []string, do func(string) error) error {
+	if len(args) != 1 {
+		return x.UsageError()
+	}
+	defer func(d bool) { MulticallDryRun = d }(MulticallDryRun)
+	MulticallDryRun = MulticallDryRun || x.FlagBool("dry-run")
+	return do(args[0])
+}
@@ -0,0 +1,70 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func ExampleInstallMulticall() {
+	defer func(p string) { Z.ExePath = p }(Z.ExePath)
+	defer func(c map[string][]any) { Z.Commands = c }(Z.Commands)
+	dir, _ := os.MkdirTemp("", "multicall")
+	defer os.RemoveAll(dir)
+
+	Z.ExePath = filepath.Join(dir, "mybox")
+	os.WriteFile(Z.ExePath, []byte("binary"), 0755)
+	bin := filepath.Join(dir, "bin")
+	os.Mkdir(bin, 0755)
+
+	x := &Z.Cmd{Name: `mybox`}
+	Z.Commands = map[string][]any{"": {x}, "foo": {x}, "bar": {x, "b"}}
+
+	fmt.Println(Z.InstallMulticall(bin))
+	fmt.Println(Z.InstallMulticall(bin)) // updates
+	for _, name := range []string{"bar", "foo"} {
+		to, _ := os.Readlink(filepath.Join(bin, name))
+		fmt.Println(name, to == Z.ExePath)
+	}
+
+	fmt.Println(Z.UninstallMulticall(bin))
+	_, err := os.Lstat(filepath.Join(bin, "foo"))
+	fmt.Println(os.IsNotExist(err))
+
+	os.WriteFile(filepath.Join(bin, "foo"), []byte("mine"), 0644)
+	err = Z.InstallMulticall(bin)
+	fmt.Println(err != nil)
+
+	// Output:
+	// <nil>
+	// <nil>
+	// bar true
+	// foo true
+	// <nil>
+	// true
+	// true
+}
+
+func ExampleMulticallDryRun() {
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	defer func(p string) { Z.ExePath = p }(Z.ExePath)
+	defer func(c map[string][]any) { Z.Commands = c }(Z.Commands)
+	orig := os.Args
+	defer func() { os.Args = orig }()
+
+	Z.ExePath = "/usr/local/bin/mybox"
+	x := &Z.Cmd{Name: `mybox`}
+	Z.Commands = map[string][]any{"foo": {x}, "bar": {x, "b"}}
+
+	os.Args = []string{"multicall", "install", "-n", "bin"}
+	Z.MulticallCmd.Run()
+
+	// Output:
+	// bin/bar -> /usr/local/bin/mybox
+	// bin/foo -> /usr/local/bin/mybox
+}
@@ -0,0 +1,76 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"time"
+)
+
+// OnceTTL is how long an operation completed with Once remains done
+// (fresh). Zero (or less) means forever.
+var OnceTTL = 24 * time.Hour
+
+// OnceForce causes Once to always call its function as if the operation
+// had never been done. The same is true for any Cmd that declares
+// a bool Flag named "force" (or has a Caller that does) when it is
+// passed (ex: --force).
+var OnceForce bool
+
+// OnceKey returns a key for Once that is a short hash of the args (ex:
+// those passed to Call) so that the same operation with different
+// arguments is tracked separately.
+func OnceKey(args ...string) string {
+	h := sha256.New()
+	for _, a := range args {
+		h.Write([]byte(a))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// Once calls fn unless the operation identified by the key has already
+// been completed (fn returned nil) within the OnceTTL, in which case
+// "already done" is logged at LevelInfo (see Info) and nil is returned
+// without calling fn. Completion is recorded as the time in Vars with
+// the key prefixed by "once." and namespaced by the PathString (see
+// Set). An empty key is the OnceKey of the args the Cmd was called with
+// by Run, RunE, or Shell so that the same operation with different
+// arguments is tracked separately by default. Concurrent calls with the
+// same key (from any process) wait for each other using a file lock in
+// the LockDir so that only the first calls fn. Returns ReqVarsError if
+// Z.Vars is not defined. See OnceForce to bypass.
+func (x *Cmd) Once(key string, fn func() error) error {
+	if Vars == nil {
+		return x.ReqVarsError()
+	}
+	if key == "" {
+		key = OnceKey(x.passed().args...)
+	}
+	vkey := "once." + key
+	dir, err := lockDir()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, "once-"+OnceKey(x.pathKey(vkey))+".lock")
+	release, _, err := lockPath(path, -1)
+	if err != nil {
+		return err
+	}
+	defer release()
+	if !OnceForce && !x.FlagBool("force") {
+		if t, err := time.Parse(time.RFC3339Nano, x.Get(vkey)); err == nil {
+			if age := time.Since(t); OnceTTL <= 0 || age < OnceTTL {
+				x.Info(Msg(`already-done`, key, age.Round(time.Second)))
+				return nil
+			}
+		}
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+	return x.Set(vkey, time.Now().Format(time.RFC3339Nano))
+}
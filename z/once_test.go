@@ -0,0 +1,182 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+// onceTmp assigns a Vars and LockDir within a new temporary directory
+// returning a function to restore them and remove it.
+func onceTmp() (*Z.VarsFile, func()) {
+	dir, _ := os.MkdirTemp("", "bonzai-once")
+	origVars, origLock := Z.Vars, Z.LockDir
+	v := &Z.VarsFile{File: filepath.Join(dir, "vars")}
+	Z.Vars, Z.LockDir = v, dir
+	return v, func() {
+		Z.Vars, Z.LockDir = origVars, origLock
+		os.RemoveAll(dir)
+	}
+}
+
+func ExampleCmd_Once() {
+	defer log.SetOutput(os.Stderr)
+	defer log.SetFlags(log.Flags())
+	log.SetOutput(os.Stdout)
+	log.SetFlags(0)
+	v, done := onceTmp()
+	defer done()
+
+	x := &Z.Cmd{Name: `foo`, Commands: []*Z.Cmd{{Name: `sync`}}}
+	sync, _ := x.Seek([]string{"sync"})
+
+	fn := func() error {
+		fmt.Println("syncing")
+		return nil
+	}
+	fmt.Println(Z.OnceKey("a", "b") == Z.OnceKey("a", "b"),
+		Z.OnceKey("a", "b") == Z.OnceKey("ab"))
+
+	sync.Once(`daily`, fn)
+	sync.Once(`daily`, fn)
+	sync.Once(`weekly`, fn)
+
+	Z.OnceForce = true
+	sync.Once(`daily`, fn)
+	Z.OnceForce = false
+
+	defer func(ttl time.Duration) { Z.OnceTTL = ttl }(Z.OnceTTL)
+	Z.OnceTTL = time.Nanosecond
+	sync.Once(`weekly`, fn)
+
+	fmt.Println(sync.Once(`failing`, func() error {
+		return errors.New("failed")
+	}))
+	fmt.Printf("%q\n", sync.Get("once.failing"))
+	fmt.Println(v.Get("sync.once.daily") != "")
+
+	// Output:
+	// true false
+	// syncing
+	// sync: already done: daily (0s ago)
+	// syncing
+	// syncing
+	// syncing
+	// failed
+	// ""
+	// true
+}
+
+func ExampleCmd_Once_args() {
+	defer log.SetOutput(os.Stderr)
+	defer log.SetFlags(log.Flags())
+	log.SetOutput(os.Stdout)
+	log.SetFlags(0)
+	_, done := onceTmp()
+	defer done()
+
+	x := &Z.Cmd{
+		Name: `foo`,
+		Commands: []*Z.Cmd{{
+			Name: `pull`,
+			Call: func(x *Z.Cmd, args ...string) error {
+				return x.Once(``, func() error {
+					fmt.Println("pulling", args)
+					return nil
+				})
+			},
+		}},
+	}
+
+	x.RunE("pull", "a")
+	x.RunE("pull", "b")
+	x.RunE("pull", "a")
+	x.RunE("pull", "b", "c")
+
+	// Output:
+	// pulling [a]
+	// pulling [b]
+	// pull: already done: ffe9aaeaa2a2d504 (0s ago)
+	// pulling [b c]
+}
+
+func ExampleCmd_Once_force() {
+	defer logErrs()()
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	defer log.SetOutput(os.Stderr)
+	defer log.SetFlags(log.Flags())
+	log.SetOutput(os.Stdout)
+	log.SetFlags(0)
+	orig := os.Args
+	defer func() { os.Args = orig }()
+	_, done := onceTmp()
+	defer done()
+
+	x := &Z.Cmd{
+		Name:  `foo`,
+		Flags: []Z.Flag{{Name: `force`}},
+		Call: func(x *Z.Cmd, args ...string) error {
+			return x.Once(Z.OnceKey(args...), func() error {
+				fmt.Println("deploying", args)
+				return nil
+			})
+		},
+	}
+
+	os.Args = []string{"foo", "v1"}
+	x.Run()
+	x.Run()
+	os.Args = []string{"foo", "v2"}
+	x.Run()
+	os.Args = []string{"foo", "--force", "v1"}
+	x.Run()
+
+	// Output:
+	// deploying [v1]
+	// foo: already done: 74da98fdf740b7ca (0s ago)
+	// deploying [v2]
+	// deploying [v1]
+}
+
+func TestCmd_Once_concurrent(t *testing.T) {
+	_, done := onceTmp()
+	defer done()
+	defer log.SetOutput(os.Stderr)
+	log.SetOutput(new(lockedBuffer))
+	defer func(d time.Duration) { Z.LockPoll = d }(Z.LockPoll)
+	Z.LockPoll = time.Millisecond
+
+	x := &Z.Cmd{Name: `foo`}
+	var mu sync.Mutex
+	var calls int
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := x.Once(`job`, func() error {
+				mu.Lock()
+				calls++
+				mu.Unlock()
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if calls != 1 {
+		t.Errorf("want 1 call, got %v", calls)
+	}
+}
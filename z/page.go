@@ -0,0 +1,74 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// DefaultPager is the pager command line used by Page when the PAGER
+// environment variable is not set.
+var DefaultPager = `less -FRX`
+
+// NoPager disables the pager for Page entirely (as does setting the
+// NO_PAGER environment variable to anything).
+var NoPager bool
+
+// ForcePager makes Page always use the pager even when standard output
+// is not a terminal or the text would fit on the screen. This is
+// mostly useful for testing with a fake pager (see Page).
+var ForcePager bool
+
+// Page prints the text to standard output through the pager set by the
+// PAGER environment variable (or DefaultPager) when standard output is
+// a terminal and the text has more lines than the terminal has rows.
+// Otherwise, or when disabled (see NoPager), the text is printed
+// directly. If the pager cannot be found or fails to start the text is
+// also printed directly so that nothing is ever lost or left hanging.
+func Page(text string) {
+	if !paging(text) {
+		fmt.Print(text)
+		return
+	}
+	pager := os.Getenv("PAGER")
+	if pager == "" {
+		pager = DefaultPager
+	}
+	args := strings.Fields(pager)
+	if len(args) == 0 {
+		fmt.Print(text)
+		return
+	}
+	path, err := exec.LookPath(args[0])
+	if err != nil {
+		fmt.Print(text)
+		return
+	}
+	cmd := exec.Command(path, args[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		fmt.Print(text)
+		return
+	}
+	cmd.Wait()
+}
+
+func paging(text string) bool {
+	switch {
+	case NoPager || os.Getenv("NO_PAGER") != "":
+		return false
+	case ForcePager:
+		return true
+	}
+	rows, _ := Term.Size()
+	if !Term.IsInteractive() || rows == 0 {
+		return false
+	}
+	return strings.Count(text, "\n") >= rows
+}
@@ -0,0 +1,62 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func ExamplePage_missing() {
+	Z.ForcePager = true
+	defer func() { Z.ForcePager = false }()
+	orig := os.Getenv("PAGER")
+	defer os.Setenv("PAGER", orig)
+	os.Setenv("PAGER", "bonzai-no-such-pager -X")
+
+	Z.Page("printed directly\n")
+
+	// Output:
+	// printed directly
+}
+
+func ExamplePage_noPager() {
+	Z.ForcePager, Z.NoPager = true, true
+	defer func() { Z.ForcePager, Z.NoPager = false, false }()
+
+	Z.Page("printed directly\n")
+
+	// Output:
+	// printed directly
+}
+
+func TestPage(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake pager requires sh")
+	}
+	dir := t.TempDir()
+	rec := filepath.Join(dir, "stdin")
+	pager := filepath.Join(dir, "pager")
+	script := "#!/bin/sh\ncat > " + rec + "\n"
+	if err := os.WriteFile(pager, []byte(script), 0700); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PAGER", pager)
+	Z.ForcePager = true
+	defer func() { Z.ForcePager = false }()
+
+	Z.Page("some\nlong\ntext\n")
+
+	got, err := os.ReadFile(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "some\nlong\ntext\n" {
+		t.Errorf("pager got %q", got)
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rwxrob/bonzai"
+)
+
+// IsParam returns true if the argument is one of the Params or
+// a key=value argument for one of the key=value Params (see
+// bonzai.KVParam).
+func (x *Cmd) IsParam(arg string) bool {
+	if contains(x.Params, arg) {
+		return true
+	}
+	k, _, _ := strings.Cut(arg, "=")
+	return x.kvParam(k) != nil
+}
+
+// ParamCount returns the number of the args that are Params (see
+// IsParam). Every use of a Repeatable param is counted.
+func (x *Cmd) ParamCount(args []string) int {
+	var n int
+	for _, a := range args {
+		if x.IsParam(a) {
+			n++
+		}
+	}
+	return n
+}
+
+// checkParams returns a UsageError (with the reason first followed by
+// the valid Params) if there are fewer Params in the args than MinParm
+// or more than MaxParm (if greater than 0). Other args are passed
+// through as positional arguments unless StrictParams is set in which
+// case the first of them is the error instead. The constraints of Excl
+// and Needs are then checked (see checkConstraints).
+func (x *Cmd) checkParams(args []string) error {
+	if len(x.Params) == 0 && !x.StrictParams {
+		return nil
+	}
+	used := map[string]bool{}
+	var n int
+	for _, a := range args {
+		switch {
+		case x.IsParam(a):
+			used[bonzai.ParamWord(a)] = true
+			n++
+		case x.StrictParams:
+			return x.paramsError(fmt.Sprintf("unknown param %q", a))
+		}
+	}
+	switch {
+	case n < x.MinParm:
+		return x.paramsError(
+			fmt.Sprintf("requires at least %v params (got %v)", x.MinParm, n))
+	case x.MaxParm > 0 && n > x.MaxParm:
+		return x.paramsError(
+			fmt.Sprintf("allows at most %v params (got %v)", x.MaxParm, n))
+	}
+	return x.checkConstraints(used)
+}
+
+// checkConstraints returns a UsageError (with the reason first) naming
+// the first two words used from any group of Excl or the first word
+// used without one of the words it Needs (by sorted key).
+func (x *Cmd) checkConstraints(used map[string]bool) error {
+	for _, group := range x.Excl {
+		var found []string
+		for _, w := range group {
+			if used[w] {
+				found = append(found, w)
+			}
+		}
+		if len(found) > 1 {
+			return fmt.Errorf("%q and %q cannot be used together\n%v",
+				found[0], found[1], x.UsageError())
+		}
+	}
+	for _, w := range keysWithPrefix(x.Needs, "") {
+		if !used[w] {
+			continue
+		}
+		for _, n := range x.Needs[w] {
+			if !used[n] {
+				return fmt.Errorf("%q requires %q\n%v", w, n, x.UsageError())
+			}
+		}
+	}
+	return nil
+}
+
+func (x *Cmd) paramsError(reason string) error {
+	if len(x.Params) > 0 {
+		reason += " (one of: " + strings.Join(usageParams(x.Params), ", ") + ")"
+	}
+	return fmt.Errorf("%v\n%v", reason, x.UsageError())
+}
+
+// constrained returns true if there are any Params and any Excl or
+// Needs constraints on them.
+func (x *Cmd) constrained() bool {
+	return len(x.Params) > 0 && (len(x.Excl) > 0 || len(x.Needs) > 0)
+}
+
+// unknownConstraints returns the words of Excl and Needs (in order,
+// keys of Needs sorted) that are not the word of any of the Params.
+func (x *Cmd) unknownConstraints() []string {
+	words := map[string]bool{}
+	for _, p := range x.Params {
+		words[bonzai.ParamWord(p)] = true
+	}
+	var list []string
+	check := func(ws ...string) {
+		for _, w := range ws {
+			if !words[w] && !contains(list, w) {
+				list = append(list, w)
+			}
+		}
+	}
+	for _, group := range x.Excl {
+		check(group...)
+	}
+	for _, k := range keysWithPrefix(x.Needs, "") {
+		check(k)
+		check(x.Needs[k]...)
+	}
+	return list
+}
+
+// paramNotes returns a note for each of the constraints of Excl and
+// Needs of the param (see bonzai.ParamWord) for documentation (ex:
+// "not with yaml", "requires delete").
+func (x *Cmd) paramNotes(p string) []string {
+	w := bonzai.ParamWord(p)
+	var notes, excl []string
+	for _, group := range x.Excl {
+		if !contains(group, w) {
+			continue
+		}
+		for _, o := range group {
+			if o != w && !contains(excl, o) {
+				excl = append(excl, o)
+			}
+		}
+	}
+	if len(excl) > 0 {
+		notes = append(notes, "not with "+strings.Join(excl, ", "))
+	}
+	if needs := x.Needs[w]; len(needs) > 0 {
+		notes = append(notes, "requires "+strings.Join(needs, ", "))
+	}
+	return notes
+}
@@ -0,0 +1,120 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func ExampleCmd_ParamCount() {
+	x := &Z.Cmd{
+		Name:   `deploy`,
+		Params: []string{"env=prod|dev", "force", "quiet"},
+	}
+	args := []string{"app", "force", "env=dev", "v2", "quiet"}
+	fmt.Println(x.ParamCount(args))
+	fmt.Println(x.IsParam("env=qa"), x.IsParam("app"))
+	// Output:
+	// 3
+	// true false
+}
+
+func ExampleCmd_Run_params() {
+	defer logErrs()()
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	defer log.SetOutput(os.Stderr)
+	defer log.SetFlags(log.Flags())
+	log.SetOutput(os.Stdout)
+	log.SetFlags(0)
+
+	x := &Z.Cmd{
+		Name:    `deploy`,
+		Params:  []string{"env=prod|dev", "force", "quiet"},
+		MinParm: 1,
+		MaxParm: 2,
+		Call: func(x *Z.Cmd, args ...string) error {
+			fmt.Println(args)
+			return nil
+		},
+	}
+
+	orig := os.Args
+	defer func() { os.Args = orig }()
+
+	// params interleaved with positional args
+	os.Args = []string{"deploy", "app", "force", "v2", "env=dev"}
+	x.Run()
+
+	os.Args = []string{"deploy", "app", "v2"}
+	x.Run()
+
+	os.Args = []string{"deploy", "force", "app", "quiet", "env=prod"}
+	x.Run()
+
+	x.StrictParams = true
+
+	os.Args = []string{"deploy", "force", "app"}
+	x.Run()
+
+	os.Args = []string{"deploy", "quiet", "force"}
+	x.Run()
+
+	// Output:
+	// [app force v2 env=dev]
+	// requires at least 1 params (got 0) (one of: env=(prod|dev), force, quiet)
+	// usage: deploy (env=(prod|dev)|force|quiet){1,2}
+	// allows at most 2 params (got 3) (one of: env=(prod|dev), force, quiet)
+	// usage: deploy (env=(prod|dev)|force|quiet){1,2}
+	// unknown param "app" (one of: env=(prod|dev), force, quiet)
+	// usage: deploy (env=(prod|dev)|force|quiet){1,2}
+	// [quiet force]
+}
+
+func ExampleCmd_Run_constraints() {
+	defer logErrs()()
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	defer log.SetOutput(os.Stderr)
+	defer log.SetFlags(log.Flags())
+	log.SetOutput(os.Stdout)
+	log.SetFlags(0)
+
+	x := &Z.Cmd{
+		Name:   `export`,
+		Params: []string{"json", "yaml", "delete", "force", "to="},
+		Excl:   [][]string{{"json", "yaml", "to"}},
+		Needs:  map[string][]string{"force": {"delete"}},
+		Call: func(x *Z.Cmd, args ...string) error {
+			fmt.Println(args)
+			return nil
+		},
+	}
+
+	orig := os.Args
+	defer func() { os.Args = orig }()
+
+	os.Args = []string{"export", "file", "json", "delete", "force"}
+	x.Run()
+
+	os.Args = []string{"export", "yaml", "file", "json"}
+	x.Run()
+
+	os.Args = []string{"export", "to=csv", "yaml"}
+	x.Run()
+
+	os.Args = []string{"export", "force", "file"}
+	x.Run()
+
+	// Output:
+	// [file json delete force]
+	// "json" and "yaml" cannot be used together
+	// usage: export [json|yaml|delete|force|to=<value>]...
+	// "yaml" and "to" cannot be used together
+	// usage: export [json|yaml|delete|force|to=<value>]...
+	// "force" requires "delete"
+	// usage: export [json|yaml|delete|force|to=<value>]...
+}
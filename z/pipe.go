@@ -0,0 +1,174 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// StageError is the error of a single stage of a pipeline (see Pipe).
+// Err is usually an *exec.ExitError (with the exit status).
+type StageError struct {
+	Stage int      // position in the pipeline (from 1)
+	Args  []string // of the stage
+	Err   error
+}
+
+// Error fulfills the error interface (ex: "stage 2 (grep): exit status
+// 1").
+func (e *StageError) Error() string {
+	if len(e.Args) == 0 {
+		return fmt.Sprintf("stage %v: %v", e.Stage, e.Err)
+	}
+	return fmt.Sprintf("stage %v (%v): %v", e.Stage, e.Args[0], e.Err)
+}
+
+// Unwrap returns the Err.
+func (e *StageError) Unwrap() error { return e.Err }
+
+// PipeError contains the StageError of every stage of a pipeline that
+// failed (in order).
+type PipeError []*StageError
+
+// Error fulfills the error interface with the Error of each stage that
+// failed (one per line).
+func (e PipeError) Error() string {
+	msgs := make([]string, len(e))
+	for i, s := range e {
+		msgs[i] = s.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the first StageError (so that errors.As works for it
+// and for its Err).
+func (e PipeError) Unwrap() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e[0]
+}
+
+// Pipe runs each of the stages (executable and arguments as with Exec)
+// concurrently connecting the standard output of each to the standard
+// input of the next (like a shell pipeline) so that data streams
+// between them. The standard input of the first and standard output of
+// the last are those of the calling program as is the standard error
+// of all of them. A PipeError is returned if any stage fails (not only
+// the last, like "set -o pipefail" in bash). During a dry run (see
+// DryRun) the pipeline is printed instead (see DryRunLine).
+func Pipe(stages ...[]string) error {
+	return PipeContext(context.Background(), stages...)
+}
+
+// PipeContext is the same as Pipe but kills every stage still running
+// if the context is done before they complete.
+func PipeContext(ctx context.Context, stages ...[]string) error {
+	if lastFlags().dryrun && len(stages) > 0 {
+		fmt.Println(pipeLine(stages))
+		return nil
+	}
+	return pipe(ctx, stages, os.Stdout)
+}
+
+// PipeOut is the same as Pipe but returns the standard output of the
+// last stage (with leading and trailing white space trimmed) instead
+// (see Out). During a dry run an empty string is returned.
+func PipeOut(stages ...[]string) (string, error) {
+	return PipeOutContext(context.Background(), stages...)
+}
+
+// PipeOutContext is the same as PipeOut but kills every stage still
+// running if the context is done before they complete.
+func PipeOutContext(ctx context.Context, stages ...[]string) (string, error) {
+	if lastFlags().dryrun && len(stages) > 0 {
+		fmt.Println(pipeLine(stages))
+		return "", nil
+	}
+	var out bytes.Buffer
+	err := pipe(ctx, stages, &out)
+	return strings.TrimSpace(out.String()), err
+}
+
+// pipeLine returns the stages as a single shell pipeline (see
+// DryRunLine).
+func pipeLine(stages [][]string) string {
+	lines := make([]string, len(stages))
+	for i, s := range stages {
+		lines[i] = DryRunLine(s...)
+	}
+	return strings.Join(lines, " | ")
+}
+
+// pipe starts every stage (after connecting them with os.Pipe) and
+// waits for all of them. If any fails to start those already started
+// are killed.
+func pipe(ctx context.Context, stages [][]string, stdout io.Writer) error {
+	if len(stages) == 0 {
+		return fmt.Errorf("missing pipeline stages")
+	}
+	var errs PipeError
+	cmds := make([]*exec.Cmd, len(stages))
+	for i, s := range stages {
+		cmd, err := command(ctx, s)
+		if err != nil {
+			errs = append(errs, &StageError{i + 1, s, err})
+			continue
+		}
+		cmd.Stderr = os.Stderr
+		cmds[i] = cmd
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	var files []*os.File
+	closeAll := func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}
+	cmds[0].Stdin = os.Stdin
+	for i := 0; i < len(cmds)-1; i++ {
+		r, w, err := os.Pipe()
+		if err != nil {
+			closeAll()
+			return err
+		}
+		files = append(files, r, w)
+		cmds[i].Stdout = w
+		cmds[i+1].Stdin = r
+	}
+	cmds[len(cmds)-1].Stdout = stdout
+
+	started := 0
+	for i, cmd := range cmds {
+		if err := cmd.Start(); err != nil {
+			errs = append(errs, &StageError{i + 1, stages[i], err})
+			break
+		}
+		started++
+	}
+	// the stages have their own copies now (and must see EOF)
+	closeAll()
+	failed := len(errs) > 0
+	for i := 0; i < started; i++ {
+		if failed {
+			cmds[i].Process.Kill()
+		}
+		if err := cmds[i].Wait(); err != nil && !failed {
+			errs = append(errs, &StageError{i + 1, stages[i], err})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func needSh(t *testing.T) {
+	if !InPath("sh", "grep", "cat") {
+		t.Skip("requires sh, grep, and cat")
+	}
+}
+
+func TestPipeOut(t *testing.T) {
+	needSh(t)
+	out, err := PipeOut(
+		[]string{"sh", "-c", "printf 'one\\ntwo\\nthree\\n'"},
+		[]string{"grep", "t"},
+		[]string{"sh", "-c", "tr a-z A-Z"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "TWO\nTHREE" {
+		t.Errorf("unexpected output: %q", out)
+	}
+}
+
+func TestPipeOut_stream(t *testing.T) {
+	needSh(t)
+	// far more than fits in any pipe buffer so both must run at once
+	out, err := PipeOut(
+		[]string{"sh", "-c", "i=0; while [ $i -lt 20000 ]; do echo line $i; i=$((i+1)); done"},
+		[]string{"grep", "-c", "line"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "20000" {
+		t.Errorf("unexpected output: %q", out)
+	}
+}
+
+func TestPipe_stageError(t *testing.T) {
+	needSh(t)
+	_, err := PipeOut(
+		[]string{"sh", "-c", "echo data; exit 3"},
+		[]string{"cat"},
+		[]string{"grep", "nomatch"},
+	)
+	var perr PipeError
+	if !errors.As(err, &perr) || len(perr) != 2 {
+		t.Fatalf("want PipeError for two stages: %#v", err)
+	}
+	want := "stage 1 (sh): exit status 3\nstage 3 (grep): exit status 1"
+	if err.Error() != want {
+		t.Errorf("unexpected error:\n%v", err)
+	}
+	var serr *StageError
+	var xerr *exec.ExitError
+	if !errors.As(err, &serr) || serr.Stage != 1 ||
+		!errors.As(err, &xerr) || xerr.ExitCode() != 3 {
+		t.Errorf("first stage error not unwrapped: %v", err)
+	}
+}
+
+func TestPipe_missing(t *testing.T) {
+	err := Pipe([]string{"go", "version"}, []string{"__inoexist"})
+	var serr *StageError
+	if !errors.As(err, &serr) || serr.Stage != 2 {
+		t.Errorf("should have failed for stage 2: %v", err)
+	}
+	if err := Pipe(); err == nil {
+		t.Error("should have failed without stages")
+	}
+}
+
+func TestPipeContext(t *testing.T) {
+	needSh(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := PipeOutContext(ctx, []string{"sh", "-c", "exec sleep 10"}, []string{"cat"})
+	if err == nil {
+		t.Error("should have been killed")
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Error("took too long to cancel")
+	}
+}
+
+func TestPipe_dryRun(t *testing.T) {
+	defer setLast(nil)
+	setLast(withFlags(&Cmd{}, runFlags{dryrun: true}))
+	r, w, _ := os.Pipe()
+	defer func(o *os.File) { os.Stdout = o }(os.Stdout)
+	os.Stdout = w
+	out, err := PipeOut([]string{"__inoexist", "a b"}, []string{"grep", "x"})
+	w.Close()
+	printed, _ := io.ReadAll(r)
+	if out != "" || err != nil {
+		t.Errorf("should not have run: %q %v", out, err)
+	}
+	if string(printed) != "__inoexist 'a b' | grep x\n" {
+		t.Errorf("unexpected dry run: %q", printed)
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProgressOut is where Spinner and Progress write (standard error by
+// default) so that standard output is left clean for data.
+var ProgressOut io.Writer = os.Stderr
+
+// SpinnerFrames are drawn in turn by every Spinner.
+var SpinnerFrames = []string{`|`, `/`, `-`, `\`}
+
+// ProgressWidth is the number of cells used for the bar of a Progress
+// drawn on the terminal.
+var ProgressWidth = 30
+
+// SpinnerInterval is how often a Spinner is redrawn on the terminal
+// (and the most often a Progress is). Zero or less stops the animation
+// and redraws a Progress on every update.
+var SpinnerInterval = 100 * time.Millisecond
+
+// ProgressLogInterval is how often a Spinner or Progress writes a plain
+// line instead when not drawing on a terminal (see Spinner). Zero or
+// less writes one for every update.
+var ProgressLogInterval = 10 * time.Second
+
+const (
+	clearLine  = "\r\033[K"
+	hideCursor = "\033[?25l"
+	showCursor = "\033[?25h"
+)
+
+// liveProgress returns true if Spinner and Progress should draw (with
+// carriage returns) on the terminal rather than write plain lines.
+func liveProgress() bool {
+	return Term.IsInteractive() && os.Getenv("CI") == ""
+}
+
+// Spin is a Spinner. It is safe for concurrent use.
+type Spin struct {
+	mu      sync.Mutex
+	label   string
+	frame   int
+	live    bool
+	started time.Time
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// Spinner returns a new Spin with the label (see Start). When the
+// Terminal is interactive (and the CI environment variable is not set)
+// the spinner is drawn (followed by the label) over and over on the
+// same line of ProgressOut with the cursor hidden until stopped at
+// which point the line is cleared and the cursor restored. Otherwise,
+// the label is written as a plain line when started and updated and
+// again (with the time elapsed) every ProgressLogInterval. Nothing at
+// all is written if the quiet flag was passed (see BuiltinFlags).
+// A running Spin is always stopped by Exit, ExitError, and TrapPanic
+// (see AtExit).
+func Spinner(label string) *Spin { return &Spin{label: label} }
+
+// Start starts the Spin unless already started.
+func (s *Spin) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil || lastFlags().quiet {
+		return
+	}
+	s.live = liveProgress()
+	s.started = time.Now()
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	AtExit(s.Stop)
+	if s.live {
+		fmt.Fprint(ProgressOut, hideCursor)
+	}
+	s.draw(false)
+	go s.loop(s.live, s.stop, s.done)
+}
+
+func (s *Spin) loop(live bool, stop, done chan struct{}) {
+	defer close(done)
+	every := ProgressLogInterval
+	if live {
+		every = SpinnerInterval
+	}
+	if every <= 0 {
+		<-stop
+		return
+	}
+	tick := time.NewTicker(every)
+	defer tick.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-tick.C:
+			s.mu.Lock()
+			s.frame++
+			s.draw(true)
+			s.mu.Unlock()
+		}
+	}
+}
+
+// draw must be called with the lock held.
+func (s *Spin) draw(tick bool) {
+	switch {
+	case s.live:
+		fmt.Fprintf(ProgressOut, "%v%v %v", clearLine,
+			SpinnerFrames[s.frame%len(SpinnerFrames)], s.label)
+	case tick:
+		fmt.Fprintf(ProgressOut, "%v (%v)\n", s.label,
+			time.Since(s.started).Round(time.Second))
+	default:
+		fmt.Fprintln(ProgressOut, s.label)
+	}
+}
+
+// Update changes the label of the Spin (drawing it at once if started).
+func (s *Spin) Update(label string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.label = label
+	if s.stop != nil {
+		s.draw(false)
+	}
+}
+
+// Stop stops the Spin (if started) clearing its line and restoring the
+// cursor on the terminal. It may be called more than once.
+func (s *Spin) Stop() {
+	s.mu.Lock()
+	stop, done, live := s.stop, s.done, s.live
+	s.stop = nil
+	s.mu.Unlock()
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+	if live {
+		fmt.Fprint(ProgressOut, clearLine+showCursor)
+	}
+}
+
+// Bar is a Progress. It is safe for concurrent use.
+type Bar struct {
+	mu    sync.Mutex
+	total int
+	n     int
+	live  bool
+	off   bool
+	last  time.Time
+}
+
+// Progress returns a new Bar counting up to the total (see Incr and
+// Done) and writes its initial state. As with Spinner, the bar is drawn
+// over and over on the same line (at most every SpinnerInterval) when
+// the Terminal is interactive and a plain line is written at most every
+// ProgressLogInterval otherwise (and always when Done). Nothing at all
+// is written if the quiet flag was passed (see BuiltinFlags). Done is
+// always called by Exit, ExitError, and TrapPanic (see AtExit).
+func Progress(total int) *Bar {
+	b := &Bar{total: total, live: liveProgress(), off: lastFlags().quiet}
+	if b.off {
+		return b
+	}
+	AtExit(b.Done)
+	if b.live {
+		fmt.Fprint(ProgressOut, hideCursor)
+	}
+	b.draw()
+	return b
+}
+
+// Incr adds one to the count of the Bar.
+func (b *Bar) Incr() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.off {
+		return
+	}
+	b.n++
+	every := ProgressLogInterval
+	if b.live {
+		every = SpinnerInterval
+	}
+	if b.n >= b.total || every <= 0 || time.Since(b.last) >= every {
+		b.draw()
+	}
+}
+
+// draw must be called with the lock held.
+func (b *Bar) draw() {
+	b.last = time.Now()
+	var pct int
+	if b.total > 0 {
+		pct = b.n * 100 / b.total
+	}
+	if pct > 100 {
+		pct = 100
+	}
+	if !b.live {
+		fmt.Fprintf(ProgressOut, "%v/%v (%v%%)\n", b.n, b.total, pct)
+		return
+	}
+	full := ProgressWidth * pct / 100
+	fmt.Fprintf(ProgressOut, "%v[%v%v] %3v%% %v/%v", clearLine,
+		strings.Repeat("#", full), strings.Repeat(".", ProgressWidth-full),
+		pct, b.n, b.total)
+}
+
+// Done finishes the Bar writing its final state (unless already
+// written) and, on the terminal, ending its line and restoring the
+// cursor. It may be called more than once.
+func (b *Bar) Done() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.off {
+		return
+	}
+	b.off = true
+	if b.live {
+		b.draw()
+		fmt.Fprint(ProgressOut, "\n"+showCursor)
+		return
+	}
+	if b.n < b.total {
+		b.draw()
+	}
+}
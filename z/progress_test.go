@@ -0,0 +1,147 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+// progressTo sets everything needed for predictable Spinner and
+// Progress output to the buffer returning a function to restore it all.
+func progressTo(buf io.Writer, term Z.Terminal) func() {
+	out, term0 := Z.ProgressOut, Z.Term
+	spin, logi := Z.SpinnerInterval, Z.ProgressLogInterval
+	Z.ProgressOut, Z.Term = buf, term
+	Z.SpinnerInterval, Z.ProgressLogInterval = 0, 0
+	ci, hasci := os.LookupEnv("CI")
+	os.Unsetenv("CI")
+	return func() {
+		Z.ProgressOut, Z.Term = out, term0
+		Z.SpinnerInterval, Z.ProgressLogInterval = spin, logi
+		if hasci {
+			os.Setenv("CI", ci)
+		}
+	}
+}
+
+func ExampleSpinner() {
+	buf := new(bytes.Buffer)
+	defer progressTo(buf, stubTerm{})()
+
+	s := Z.Spinner("migrating")
+	s.Start()
+	s.Update("migrating users")
+	s.Stop()
+	s.Stop()
+	fmt.Printf("%q\n", buf.String())
+
+	buf.Reset()
+	Z.Term = pipeTerm{}
+	s.Start()
+	s.Update("migrating users")
+	s.Stop()
+	fmt.Printf("%q\n", buf.String())
+
+	// Output:
+	// "\x1b[?25l\r\x1b[K| migrating\r\x1b[K| migrating users\r\x1b[K\x1b[?25h"
+	// "migrating users\nmigrating users\n"
+}
+
+func ExampleProgress() {
+	buf := new(bytes.Buffer)
+	defer progressTo(buf, stubTerm{})()
+	defer func(w int) { Z.ProgressWidth = w }(Z.ProgressWidth)
+	Z.ProgressWidth = 10
+
+	p := Z.Progress(4)
+	p.Incr()
+	p.Incr()
+	p.Done()
+	for _, line := range strings.Split(buf.String(), "\r\x1b[K") {
+		fmt.Printf("%q\n", line)
+	}
+
+	buf.Reset()
+	Z.Term = pipeTerm{}
+	p = Z.Progress(2)
+	p.Incr()
+	p.Incr()
+	p.Done()
+	fmt.Print(buf.String())
+
+	// Output:
+	// "\x1b[?25l"
+	// "[..........]   0% 0/4"
+	// "[##........]  25% 1/4"
+	// "[#####.....]  50% 2/4"
+	// "[#####.....]  50% 2/4\n\x1b[?25h"
+	// 0/2 (0%)
+	// 1/2 (50%)
+	// 2/2 (100%)
+}
+
+func ExampleSpinner_exit() {
+	buf := new(bytes.Buffer)
+	defer progressTo(buf, stubTerm{})()
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+
+	Z.Spinner("working").Start()
+	Z.Exit()
+	fmt.Printf("%q\n", buf.String())
+
+	// Output:
+	// "\x1b[?25l\r\x1b[K| working\r\x1b[K\x1b[?25h"
+}
+
+func TestProgress_concurrent(t *testing.T) {
+	buf := new(lockedBuffer)
+	defer progressTo(buf, stubTerm{})()
+	Z.SpinnerInterval = time.Millisecond
+	s := Z.Spinner("working")
+	s.Start()
+	p := Z.Progress(100)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				p.Incr()
+				s.Update("working")
+			}
+		}()
+	}
+	wg.Wait()
+	p.Done()
+	s.Stop()
+	if !strings.Contains(buf.String(), "100/100") {
+		t.Errorf("missing final count: %q", buf.String())
+	}
+}
+
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
@@ -0,0 +1,133 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ErrNotInteractive is returned by Prompt, PromptHidden, Confirm, and
+// Choose when the standard input is not interactive (see Interactive)
+// so that scripts fail predictably instead of hanging.
+var ErrNotInteractive = errors.New("input required but not interactive")
+
+// Interactive returns true if the standard input is a terminal (a
+// character device other than os.DevNull). It may be replaced (for
+// testing, for example).
+var Interactive = func() bool {
+	fi, err := os.Stdin.Stat()
+	if err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	null, err := os.Stat(os.DevNull)
+	return err != nil || !os.SameFile(fi, null)
+}
+
+// readLine reads a single line from standard input one byte at a time
+// (so that nothing beyond the line is consumed) dropping the line
+// ending (\n or \r\n). Returns io.EOF only if nothing was read.
+func readLine() (string, error) {
+	var buf []byte
+	b := make([]byte, 1)
+	for {
+		n, err := os.Stdin.Read(b)
+		if n > 0 {
+			if b[0] == '\n' {
+				break
+			}
+			buf = append(buf, b[0])
+		}
+		if err != nil {
+			if err == io.EOF && len(buf) > 0 {
+				break
+			}
+			return "", err
+		}
+	}
+	return strings.TrimSuffix(string(buf), "\r"), nil
+}
+
+// Prompt prints the label to standard error and returns the line read
+// from standard input (without the line ending).
+func Prompt(label string) (string, error) {
+	if !Interactive() {
+		return "", ErrNotInteractive
+	}
+	fmt.Fprint(os.Stderr, label)
+	return readLine()
+}
+
+// PromptHidden is the same as Prompt but does not echo what is typed
+// (for passwords and other secrets).
+func PromptHidden(label string) (string, error) {
+	if !Interactive() {
+		return "", ErrNotInteractive
+	}
+	fmt.Fprint(os.Stderr, label)
+	return Term.ReadHidden(), nil
+}
+
+// Confirm prompts (see Prompt) with the label followed by [y/N] (or
+// [Y/n] if def is true) until answered with y, yes, n, or no (in any
+// case) returning def if nothing is entered. If the BONZAI_YES
+// environment variable is set (to anything) true is returned without
+// prompting (for automation).
+func Confirm(label string, def bool) (bool, error) {
+	if os.Getenv("BONZAI_YES") != "" {
+		return true, nil
+	}
+	choices := " [y/N] "
+	if def {
+		choices = " [Y/n] "
+	}
+	for {
+		in, err := Prompt(label + choices)
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(strings.TrimSpace(in)) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+	}
+}
+
+// Choose prints the options numbered from 1 to standard error and then
+// prompts (see Prompt) with the label until either one of the numbers
+// or options is entered returning the option.
+func Choose(label string, options []string) (string, error) {
+	if len(options) == 0 {
+		return "", errors.New("no options to choose from")
+	}
+	if !Interactive() {
+		return "", ErrNotInteractive
+	}
+	for i, o := range options {
+		fmt.Fprintf(os.Stderr, "%d) %v\n", i+1, o)
+	}
+	for {
+		in, err := Prompt(label)
+		if err != nil {
+			return "", err
+		}
+		in = strings.TrimSpace(in)
+		if n, err := strconv.Atoi(in); err == nil && n > 0 && n <= len(options) {
+			return options[n-1], nil
+		}
+		for _, o := range options {
+			if in == o {
+				return o, nil
+			}
+		}
+	}
+}
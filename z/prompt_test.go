@@ -0,0 +1,65 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"fmt"
+	"os"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+// withInput replaces standard input with the input (and makes it
+// Interactive) returning the function to restore it.
+func withInput(input string) func() {
+	r, w, _ := os.Pipe()
+	w.WriteString(input)
+	w.Close()
+	stdin, interactive := os.Stdin, Z.Interactive
+	os.Stdin = r
+	Z.Interactive = func() bool { return true }
+	return func() { os.Stdin, Z.Interactive = stdin, interactive; r.Close() }
+}
+
+func ExamplePrompt() {
+	defer func(f func() bool) { Z.Interactive = f }(Z.Interactive)
+	Z.Interactive = func() bool { return false }
+	fmt.Println(Z.Prompt("name: "))
+
+	defer withInput("Mr. Rob\r\nmore\n")()
+	fmt.Println(Z.Prompt("name: "))
+	fmt.Println(Z.Prompt("again: "))
+	fmt.Println(Z.Prompt("eof: "))
+
+	// Output:
+	//  input required but not interactive
+	// Mr. Rob <nil>
+	// more <nil>
+	//  EOF
+}
+
+func ExampleConfirm() {
+	defer withInput("maybe\nYES\n\n")()
+	fmt.Println(Z.Confirm("overwrite?", false))
+	fmt.Println(Z.Confirm("overwrite?", true))
+
+	os.Setenv("BONZAI_YES", "1")
+	defer os.Unsetenv("BONZAI_YES")
+	fmt.Println(Z.Confirm("overwrite?", false))
+
+	// Output:
+	// true <nil>
+	// true <nil>
+	// true <nil>
+}
+
+func ExampleChoose() {
+	defer withInput("4\nstaging\n1\n")()
+	envs := []string{"dev", "staging", "prod"}
+	fmt.Println(Z.Choose("environment: ", envs))
+	fmt.Println(Z.Choose("environment: ", envs))
+	// Output:
+	// staging <nil>
+	// dev <nil>
+}
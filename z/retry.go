@@ -0,0 +1,124 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// RetryMaxDelay limits the delay between any two attempts of Retry
+// (before jitter).
+var RetryMaxDelay = time.Minute
+
+// RetryBase is the base delay used by Run to retry the Call of a Cmd
+// that sets Retries (see Retry).
+var RetryBase = 500 * time.Millisecond
+
+// RetrySleep is called by Retry to wait for the delay before the next
+// attempt returning the error of the context if it is done first. It
+// may be assigned (in tests, for example) to avoid actually waiting.
+var RetrySleep = func(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// RetryJitter returns the actual delay to use for the given backoff
+// delay, which is half of it plus a random amount up to the other half
+// by default. It may be assigned (in tests, for example) to remove the
+// randomness.
+var RetryJitter = func(d time.Duration) time.Duration {
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)+1))
+}
+
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// canceledError is returned by Retry when the context is done before
+// another attempt and wraps both the error of the context and the last
+// error of fn (without needing errors.Join).
+type canceledError struct{ ctx, last error }
+
+func (e *canceledError) Error() string   { return e.ctx.Error() + ": " + e.last.Error() }
+func (e *canceledError) Unwrap() error   { return e.ctx }
+func (e *canceledError) Is(t error) bool { return errors.Is(e.last, t) }
+func (e *canceledError) As(t any) bool   { return errors.As(e.last, t) }
+
+// Permanent wraps the error so that Retry returns it (unwrapped)
+// immediately instead of trying again. Returns nil if err is nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err}
+}
+
+// Retry calls fn until it returns nil, up to the given number of
+// attempts in total (at least one), waiting between them with
+// exponential backoff (base, then twice that, and so on up to
+// RetryMaxDelay) with jitter (see RetryJitter and RetrySleep). Each
+// retry is logged at LevelDebug with the attempt number. The last error
+// is returned if every attempt fails. Errors wrapped with Permanent are
+// returned immediately (unwrapped). If the context is done before
+// another attempt its error is returned wrapping the last error of fn
+// as well (so that errors.Is and errors.As work with either).
+func Retry(ctx context.Context, attempts int, base time.Duration, fn func() error) error {
+	return retry(ctx, "", attempts, base, fn)
+}
+
+// retry is Retry logging with the given path (see Cmd.logPath).
+func retry(ctx context.Context, path string, attempts int, base time.Duration, fn func() error) error {
+	delay := base
+	for n := 1; ; n++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		var perm *permanentError
+		if errors.As(err, &perm) {
+			return perm.err
+		}
+		if n >= attempts {
+			return err
+		}
+		if delay > RetryMaxDelay {
+			delay = RetryMaxDelay
+		}
+		var wait time.Duration
+		if delay > 0 {
+			wait = RetryJitter(delay)
+		}
+		logAt(LevelDebug, path, fmt.Sprintf(
+			"attempt %v of %v failed (retrying in %v): %v", n, attempts, wait, err))
+		if cerr := RetrySleep(ctx, wait); cerr != nil {
+			return &canceledError{cerr, err}
+		}
+		if delay < RetryMaxDelay {
+			delay *= 2
+		}
+	}
+}
+
+// callRetries calls the Call Method of the Cmd and then again after any
+// error (see Retry) up to Retries times waiting RetryBase before the
+// first retry.
+func (x *Cmd) callRetries(ctx context.Context, args []string) error {
+	call := func() error { return x.Call(x, args...) }
+	if x.Retries <= 0 {
+		return call()
+	}
+	return retry(ctx, x.logPath(), x.Retries+1, RetryBase, call)
+}
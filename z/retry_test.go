@@ -0,0 +1,159 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+// noWait replaces RetrySleep and RetryJitter so that Retry prints each
+// delay (without jitter) instead of waiting and returns a function to
+// restore them.
+func noWait() func() {
+	sleep, jitter := Z.RetrySleep, Z.RetryJitter
+	Z.RetrySleep = func(ctx context.Context, d time.Duration) error {
+		fmt.Println("sleep", d)
+		return ctx.Err()
+	}
+	Z.RetryJitter = func(d time.Duration) time.Duration { return d }
+	return func() { Z.RetrySleep, Z.RetryJitter = sleep, jitter }
+}
+
+func ExampleRetry() {
+	defer noWait()()
+	defer log.SetOutput(os.Stderr)
+	defer log.SetFlags(log.Flags())
+	log.SetOutput(os.Stdout)
+	log.SetFlags(0)
+	defer func(l Z.Level) { Z.LogLevel = l }(Z.LogLevel)
+	Z.LogLevel = Z.LevelDebug
+
+	var n int
+	err := Z.Retry(context.Background(), 5, time.Second, func() error {
+		if n++; n < 3 {
+			return fmt.Errorf("flaky %v", n)
+		}
+		return nil
+	})
+	fmt.Println(err, n)
+
+	// Output:
+	// debug: attempt 1 of 5 failed (retrying in 1s): flaky 1
+	// sleep 1s
+	// debug: attempt 2 of 5 failed (retrying in 2s): flaky 2
+	// sleep 2s
+	// <nil> 3
+}
+
+func ExampleRetry_exhausted() {
+	defer noWait()()
+	defer func(d time.Duration) { Z.RetryMaxDelay = d }(Z.RetryMaxDelay)
+	Z.RetryMaxDelay = 3 * time.Second
+
+	err := Z.Retry(context.Background(), 4, time.Second, func() error {
+		return errors.New("down")
+	})
+	fmt.Println(err)
+
+	// Output:
+	// sleep 1s
+	// sleep 2s
+	// sleep 3s
+	// down
+}
+
+func ExamplePermanent() {
+	defer noWait()()
+	notFound := errors.New("not found")
+
+	var n int
+	err := Z.Retry(context.Background(), 5, time.Second, func() error {
+		if n++; n < 2 {
+			return errors.New("timeout")
+		}
+		return Z.Permanent(fmt.Errorf("lookup: %w", notFound))
+	})
+	fmt.Println(err, n, errors.Is(err, notFound))
+	fmt.Println(Z.Permanent(nil))
+
+	// Output:
+	// sleep 1s
+	// lookup: not found 2 true
+	// <nil>
+}
+
+func ExampleRetry_canceled() {
+	defer noWait()()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var n int
+	failed := errors.New("failed")
+	err := Z.Retry(ctx, 5, time.Second, func() error {
+		if n++; n == 2 {
+			cancel()
+		}
+		return fmt.Errorf("%w %v", failed, n)
+	})
+	fmt.Println(err, n, errors.Is(err, context.Canceled), errors.Is(err, failed))
+
+	// Output:
+	// sleep 1s
+	// sleep 2s
+	// context canceled: failed 2 2 true true
+}
+
+func ExampleCmd_Run_retries() {
+	defer noWait()()
+	defer logErrs()()
+	rec := new(Z.RecordingExiter)
+	defer Z.SetExiter(rec)()
+	defer log.SetOutput(os.Stderr)
+	defer log.SetFlags(log.Flags())
+	log.SetOutput(os.Stdout)
+	log.SetFlags(0)
+	defer func(l Z.Level) { Z.LogLevel = l }(Z.LogLevel)
+	Z.LogLevel = Z.LevelDebug
+	orig := os.Args
+	defer func() { os.Args = orig }()
+
+	var n int
+	x := &Z.Cmd{Name: `foo`}
+	x.Commands = []*Z.Cmd{{
+		Name:    `fetch`,
+		Retries: 2,
+		Call: func(_ *Z.Cmd, args ...string) error {
+			n++
+			if len(args) > 0 && n < 2 {
+				return Z.Permanent(errors.New("bad url"))
+			}
+			return fmt.Errorf("unreachable %v", n)
+		},
+	}}
+
+	os.Args = []string{"foo", "fetch"}
+	x.Run()
+	fmt.Println(n, rec.Last())
+
+	n = 0
+	os.Args = []string{"foo", "fetch", "badurl"}
+	x.Run()
+	fmt.Println(n, rec.Last())
+
+	// Output:
+	// fetch: debug: attempt 1 of 3 failed (retrying in 500ms): unreachable 1
+	// sleep 500ms
+	// fetch: debug: attempt 2 of 3 failed (retrying in 1s): unreachable 2
+	// sleep 1s
+	// unreachable 3
+	// 3 1
+	// bad url
+	// 1 1
+}
@@ -0,0 +1,282 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/rwxrob/bonzai/comp"
+)
+
+// Shell is an interactive read-evaluate-print loop for a Bonzai tree
+// (see REPL and ShellCmd). Each line is split into arguments with
+// ArgsFrom and run relative to the Cur branch exactly as Run would
+// (aliases at the root, Flags, defaults, and all checks) except that
+// errors (and panics) are printed to Out without ending the session and
+// DefaultExiter is a NopExiter for the duration. The following shell
+// commands are always available:
+//
+//     cd [CMD...|..|/] - change the Cur branch (root if none)
+//     help [CMD...]    - usage and commands (unless the tree has help)
+//     history          - list the previous lines (see !! and !N)
+//     exit, quit       - end the session (as does end of input)
+//
+// A line of !! repeats the previous line and !N the line numbered N in
+// the history. Completion candidates for a line (see Complete) are
+// printed instead of running it when it ends with a tab. Any line
+// editor (with proper history and tab completion) may be used by
+// assigning ReadLine.
+type Shell struct {
+	Root     *Cmd                                // tree (required)
+	Cur      *Cmd                                // current branch (Root if nil)
+	In       io.Reader                           // input (os.Stdin if nil)
+	Out      io.Writer                           // prompts, errors, and builtins (os.Stdout if nil)
+	History  []string                            // lines run so far
+	ReadLine func(prompt string) (string, error) // optional line editor
+}
+
+// ShellBuiltins are the names of the shell commands (see Shell).
+var ShellBuiltins = []string{"cd", "exit", "help", "history", "quit"}
+
+// errShellExit is returned by Exec to end the session.
+var errShellExit = errors.New("exit")
+
+// REPL runs an interactive Shell for the tree rooted at x using the
+// standard input and output until exit or end of input.
+func REPL(x *Cmd) error { return (&Shell{Root: x}).Run() }
+
+// ShellCmd is an optional builtin leaf command (see Builtins) that
+// starts an interactive Shell for the entire tree (see REPL).
+var ShellCmd = &Cmd{
+	Name:    `shell`,
+	Summary: `interactive shell for all commands`,
+	Call: func(x *Cmd, args ...string) error {
+		if len(args) > 0 {
+			return x.UsageError()
+		}
+		return REPL(x.Root())
+	},
+}
+
+// Prompt returns the name of the Root followed by the names of the Cur
+// branch (see Path) and "> " (ex: "foo db> ").
+func (s *Shell) Prompt() string {
+	return strings.Join(append([]string{s.Root.Name}, s.cur().Path()...), " ") + "> "
+}
+
+func (s *Shell) cur() *Cmd {
+	if s.Cur == nil {
+		return s.Root
+	}
+	return s.Cur
+}
+
+func (s *Shell) out() io.Writer {
+	if s.Out == nil {
+		return os.Stdout
+	}
+	return s.Out
+}
+
+// Run reads and executes (see Exec) every line until exit or the end of
+// input printing any error and returns only errors from reading.
+func (s *Shell) Run() error {
+	s.Root.injectBuiltins()
+	s.Root.cacheSections()
+	defer SetExiter(NopExiter{})()
+	read := s.ReadLine
+	if read == nil {
+		in := s.In
+		if in == nil {
+			in = os.Stdin
+		}
+		r := bufio.NewReader(in)
+		read = func(prompt string) (string, error) {
+			fmt.Fprint(s.out(), prompt)
+			line, err := r.ReadString('\n')
+			if err == io.EOF && line != "" {
+				err = nil
+			}
+			return strings.TrimRight(line, "\r\n"), err
+		}
+	}
+	for {
+		line, err := read(s.Prompt())
+		if err == io.EOF {
+			fmt.Fprintln(s.out())
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.Exec(line); err != nil {
+			if errors.Is(err, errShellExit) {
+				return nil
+			}
+			fmt.Fprintln(s.out(), err)
+		}
+	}
+}
+
+// Complete returns the completion candidates for the line relative to
+// the Cur branch (see Run and complete) including the ShellBuiltins
+// when completing the first word.
+func (s *Shell) Complete(line string) []string {
+	cur := s.cur()
+	words := ArgsFrom(line)
+	var list []string
+	if len(words) <= 1 {
+		var pre string
+		if len(words) == 1 {
+			pre = words[0]
+		}
+		for _, b := range ShellBuiltins {
+			if strings.HasPrefix(b, pre) && cur.Resolve(b) == nil {
+				list = append(list, b)
+			}
+		}
+	}
+	return append(list, comp.Values(cur.candidates(cur.Name+" "+line))...)
+}
+
+// Exec runs a single line (see Shell) and returns any error.
+func (s *Shell) Exec(line string) (err error) {
+	if strings.HasSuffix(line, "\t") {
+		fmt.Fprintln(s.out(), strings.Join(s.Complete(strings.TrimSuffix(line, "\t")), "  "))
+		return nil
+	}
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return nil
+	}
+	if strings.HasPrefix(line, "!") {
+		if line, err = s.recall(line); err != nil {
+			return err
+		}
+		fmt.Fprintln(s.out(), line)
+	}
+	s.History = append(s.History, line)
+
+	words := ArgsFrom(line)
+	if n := len(words); n > 0 && words[n-1] == "" {
+		words = words[:n-1]
+	}
+	cur := s.cur()
+
+	switch words[0] {
+	case "exit", "quit":
+		return errShellExit
+	case "cd":
+		return s.cd(words[1:])
+	case "history":
+		for i, h := range s.History {
+			fmt.Fprintf(s.out(), "%4d  %v\n", i+1, h)
+		}
+		return nil
+	case "help":
+		if cur.Resolve("help") == nil {
+			return s.help(words[1:])
+		}
+	}
+
+	runs++
+	if cur == s.Root {
+		if words, err = cur.expandAliases(words); err != nil {
+			return err
+		}
+	}
+	in, flags, err := cur.extractFlags(words)
+	if err != nil {
+		return err
+	}
+	setVerbosity(flags)
+	cmd, args, err := cur.prepare(in, flags)
+	if err != nil || traceOnly() {
+		return err
+	}
+	setLast(cmd)
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return cmd.call(args)
+}
+
+// recall returns the line from History for !! (previous) or !N.
+func (s *Shell) recall(line string) (string, error) {
+	n := len(s.History)
+	if line != "!!" {
+		var err error
+		if n, err = strconv.Atoi(line[1:]); err != nil {
+			return "", fmt.Errorf("invalid history reference: %v", line)
+		}
+	}
+	if n < 1 || n > len(s.History) {
+		return "", fmt.Errorf("no such history: %v", line)
+	}
+	return s.History[n-1], nil
+}
+
+// cd changes the Cur branch to the one sought from the args (which may
+// also be separated by slashes) relative to the Cur branch (or the Root
+// if the first begins with a slash). A single .. changes to the Caller.
+func (s *Shell) cd(args []string) error {
+	cur := s.cur()
+	if len(args) == 0 {
+		s.Cur = s.Root
+		return nil
+	}
+	var path []string
+	for _, a := range args {
+		if strings.HasPrefix(a, "/") && len(path) == 0 {
+			cur = s.Root
+		}
+		for _, p := range strings.Split(a, "/") {
+			if p != "" {
+				path = append(path, p)
+			}
+		}
+	}
+	for _, p := range path {
+		if p == ".." {
+			if cur != s.Root && cur.Caller != nil {
+				cur = cur.Caller
+			}
+			continue
+		}
+		next, rest := cur.Seek([]string{p})
+		if len(rest) > 0 {
+			return fmt.Errorf("no such command: %v", p)
+		}
+		if len(next.AllCommands()) == 0 {
+			return fmt.Errorf("not a branch: %v", p)
+		}
+		cur = next
+	}
+	s.Cur = cur
+	return nil
+}
+
+// help prints the usage (see UsageError) and commands (see
+// UsageCmdTitles) of the Cmd sought from the args relative to the Cur
+// branch followed by the shell commands.
+func (s *Shell) help(args []string) error {
+	cmd, rest := s.cur().Seek(args)
+	if len(rest) > 0 {
+		return fmt.Errorf("no such command: %v", rest[0])
+	}
+	fmt.Fprintln(s.out(), cmd.UsageError())
+	if titles := cmd.UsageCmdTitles(); titles != "" {
+		fmt.Fprint(s.out(), "\n"+titles)
+	}
+	fmt.Fprintln(s.out(), "\nshell: cd [CMD...|..|/], help [CMD...], history, exit")
+	return nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+// shellTree adds a flag and Call that prints to migrate in the shared
+// tree (see fooTree) along with commands that fail and panic.
+func shellTree() *Z.Cmd {
+	x := fooTree()
+	db := x.Commands[0]
+	migrate := db.Commands[0]
+	migrate.Flags = []Z.Flag{{Name: `steps`, Value: true, Default: `1`}}
+	migrate.Call = func(x *Z.Cmd, args ...string) error {
+		fmt.Println("migrate", x.Flag("steps"), args)
+		return nil
+	}
+	db.Commands = append(db.Commands, &Z.Cmd{
+		Name:    `drop`,
+		Summary: `drop everything`,
+		Call: func(x *Z.Cmd, _ ...string) error {
+			return errors.New("refusing to drop")
+		},
+	})
+	x.Commands = append(x.Commands, &Z.Cmd{
+		Name: `boom`,
+		Call: func(x *Z.Cmd, _ ...string) error { panic("kaboom") },
+	})
+	return x
+}
+
+func ExampleShell() {
+	input := strings.Join([]string{
+		`db migrate --steps 3 "up now"`,
+		`cd db`,
+		`migrate`,
+		`drop`,
+		`cd ..`,
+		`boom`,
+		`cd nope`,
+		`cd db/migrate`,
+		`!2`,
+		`!!`,
+		`history`,
+		`exit`,
+		`never`,
+	}, "\n")
+	s := &Z.Shell{Root: shellTree(), In: strings.NewReader(input), Out: os.Stdout}
+	fmt.Println(s.Run())
+
+	// Output:
+	// foo> migrate 3 [up now]
+	// foo> foo db> migrate 1 []
+	// foo db> refusing to drop
+	// foo db> foo> panic: kaboom
+	// foo> no such command: nope
+	// foo> not a branch: migrate
+	// foo> cd db
+	// foo db> cd db
+	// no such command: db
+	// foo db>    1  db migrate --steps 3 "up now"
+	//    2  cd db
+	//    3  migrate
+	//    4  drop
+	//    5  cd ..
+	//    6  boom
+	//    7  cd nope
+	//    8  cd db/migrate
+	//    9  cd db
+	//   10  cd db
+	//   11  history
+	// foo db> <nil>
+}
+
+func ExampleShell_Complete() {
+	s := &Z.Shell{Root: shellTree()}
+	fmt.Println(s.Complete(""))
+	fmt.Println(s.Complete("d"))
+	fmt.Println(s.Complete("db m"))
+	s.Exec("cd db")
+	fmt.Println(s.Complete("m"))
+	s.Exec("mig\t")
+
+	// Output:
+	// [cd exit help history quit boom db]
+	// [db]
+	// [migrate]
+	// [migrate]
+	// migrate
+}
+
+func ExampleShell_help() {
+	s := &Z.Shell{Root: shellTree(), In: strings.NewReader("help db\n"), Out: os.Stdout}
+	s.Run()
+
+	// Output:
+	// foo> usage: db (migrate|drop)
+	//
+	// migrate - migrate the schema
+	// drop    - drop everything
+	//
+	// shell: cd [CMD...|..|/], help [CMD...], history, exit
+	// foo>
+}
@@ -0,0 +1,95 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// HandleSignals causes Run to handle interrupt (SIGINT, Ctrl-C, or
+// os.Interrupt on Windows) and termination (SIGTERM) signals received
+// while the Call of the command is running instead of letting them kill
+// the process immediately. When the first signal arrives the channel
+// returned by Interrupted is closed and the Call has SignalGrace to
+// return. Then (or as soon as the Call returns) any functions
+// registered with AtExit are called and the process exits with 130
+// (interrupt) or 143 (termination). A second signal forces the exit
+// immediately.
+var HandleSignals bool
+
+// SignalGrace is how long a Call has to return after a signal has been
+// received before exiting anyway (see HandleSignals).
+var SignalGrace = 2 * time.Second
+
+var sigmu sync.Mutex
+var sigrcvd os.Signal
+var interrupted = make(chan struct{})
+
+// Interrupted returns a channel that is closed when a signal has been
+// received (see HandleSignals). Calls that run for a long time should
+// check it and return early.
+func Interrupted() <-chan struct{} {
+	sigmu.Lock()
+	defer sigmu.Unlock()
+	return interrupted
+}
+
+// signaled returns the exit code for the last signal received (or 0 if
+// none).
+func signaled() int {
+	sigmu.Lock()
+	defer sigmu.Unlock()
+	switch sigrcvd {
+	case nil:
+		return 0
+	case syscall.SIGTERM:
+		return 143
+	default:
+		return 130
+	}
+}
+
+// handleSignals starts handling signals (see HandleSignals) returning
+// the function to stop.
+func handleSignals() (stop func()) {
+	sigmu.Lock()
+	sigrcvd = nil
+	interrupted = make(chan struct{})
+	intr := interrupted
+	sigmu.Unlock()
+
+	sig := make(chan os.Signal, 2)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case s := <-sig:
+			sigmu.Lock()
+			sigrcvd = s
+			sigmu.Unlock()
+			close(intr)
+		case <-done:
+			return
+		}
+		select {
+		case <-sig:
+		case <-time.After(SignalGrace):
+		case <-done:
+			return
+		}
+		exit(signaled())
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(sig)
+			close(done)
+		})
+	}
+}
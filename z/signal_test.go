@@ -0,0 +1,39 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"fmt"
+	"os"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func ExampleHandleSignals() {
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	defer Z.ClearAtExit()
+	defer func() { Z.HandleSignals = false }()
+	Z.HandleSignals = true
+
+	x := &Z.Cmd{
+		Name: `long`,
+		Call: func(_ *Z.Cmd, _ ...string) error {
+			Z.AtExit(func() { fmt.Println("cleaned up") })
+			p, _ := os.FindProcess(os.Getpid())
+			p.Signal(os.Interrupt)
+			<-Z.Interrupted()
+			fmt.Println("interrupted")
+			return nil
+		},
+	}
+
+	orig := os.Args
+	defer func() { os.Args = orig }()
+	os.Args = []string{"long"}
+	x.Run()
+
+	// Output:
+	// interrupted
+	// cleaned up
+}
@@ -0,0 +1,275 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/rwxrob/bonzai/comp"
+)
+
+// StaticShells are the shells for which StaticCompletion can produce
+// a static completion script.
+var StaticShells = []string{"bash", "zsh"}
+
+// StaticCompletion returns a completion script for the shell (see
+// StaticShells) that, unlike CompletionScript, does not invoke the
+// binary at all except to complete the arguments of commands with
+// a Completer (or ContextCompleter or Describer) of their own.
+// Instead, the tree rooted at x is walked ahead of time and the words
+// of every path through it are written into the script as case
+// statements:
+//
+//     * the names (and aliases) of Commands leading to each path
+//     * the Commands and Params completed at each (see comp.Static)
+//     * the long form of the Flags completed at each (see Flag)
+//     * Aliases (and ConfAliases) wherever a Command name is accepted
+//
+// Aliases are followed to the path of the Commands they expand to, as
+// they are when run (see Aliases). Words are expected to be simple
+// (without white space or quotes). Only the Name of the root is
+// registered (not those of multicall Commands). The script must be
+// generated again whenever the tree (or aliases) change.
+func StaticCompletion(x *Cmd, shell string) (string, error) {
+	var head, dynamic, rest, tail string
+	switch shell {
+	case "bash":
+		head, dynamic, rest, tail = staticBashHead, staticBashDynamic,
+			staticBashRest, staticBashTail
+	case "zsh":
+		head, dynamic, rest, tail = staticZshHead, staticZshDynamic,
+			staticZshRest, staticZshTail
+	default:
+		return "", fmt.Errorf("unsupported static completion shell: %q", shell)
+	}
+	name := x.Name
+	if name == "" {
+		name = ExeName
+	}
+	nodes := staticNodes(x, name)
+	vflags := map[string]bool{}
+	for _, n := range nodes {
+		for _, f := range n.vflags {
+			vflags[f] = true
+		}
+	}
+
+	var out strings.Builder
+	fname := compFuncName(name)
+	vlist := " "
+	for _, f := range keysWithPrefix(vflags, "") {
+		vlist += f + " "
+	}
+	fmt.Fprintf(&out, head, fname, name, shQuote(name), shQuote(vlist))
+	for _, n := range nodes {
+		for _, t := range n.next {
+			fmt.Fprintf(&out, "      %v) node=%v ;;\n",
+				strings.Join(shQuoteAll(t.words), "|"), shQuote(t.path))
+		}
+	}
+	out.WriteString(rest)
+	for _, n := range nodes {
+		if n.dynamic {
+			fmt.Fprintf(&out, "    %v)\n"+dynamic, shQuote(n.path), name)
+			continue
+		}
+		fmt.Fprintf(&out, "    %v) cands=%v flags=%v ;;\n", shQuote(n.path),
+			shQuote(strings.Join(n.words, " ")),
+			shQuote(strings.Join(n.flags, " ")))
+	}
+	fmt.Fprintf(&out, tail, fname, name)
+	return out.String(), nil
+}
+
+const staticBashHead = `%[1]v() {
+  local cur=${COMP_WORDS[COMP_CWORD]} node=%[3]v w i cands= flags=
+  local vflags=%[4]v
+  for ((i=1; i<COMP_CWORD; i++)); do
+    w=${COMP_WORDS[i]}
+    if [[ $w == -* ]]; then
+      [[ $vflags == *" $w "* ]] && ((i++))
+      continue
+    fi
+    case "$node $w" in
+`
+
+const staticBashRest = `      *) break ;;
+    esac
+  done
+  case $node in
+`
+
+const staticBashDynamic = `      mapfile -t COMPREPLY < <(COMP_LINE=$COMP_LINE COMP_POINT=$COMP_POINT %v 2>/dev/null)
+      return ;;
+`
+
+const staticBashTail = `  esac
+  [[ $cur == -* ]] && cands=$flags
+  COMPREPLY=($(compgen -W "$cands" -- "$cur"))
+}
+complete -F %[1]v %[2]v
+`
+
+const staticZshHead = `#compdef %[2]v
+%[1]v() {
+  local node=%[3]v w i cands= flags=
+  local vflags=%[4]v
+  local -a list
+  for ((i=2; i<CURRENT; i++)); do
+    w=${words[i]}
+    if [[ $w == -* ]]; then
+      [[ $vflags == *" $w "* ]] && ((i++))
+      continue
+    fi
+    case "$node $w" in
+`
+
+const staticZshRest = staticBashRest
+
+const staticZshDynamic = `      list=("${(@f)$(BONZAI_COMP_SHELL=zsh COMP_LINE="${words[1,CURRENT]}" %v 2>/dev/null)}")
+      _describe 'command' list
+      return ;;
+`
+
+const staticZshTail = `  esac
+  [[ ${words[CURRENT]} == -* ]] && cands=$flags
+  list=(${=cands})
+  compadd -- $list
+}
+compdef %[1]v %[2]v
+`
+
+// staticNode is one path through the tree (see StaticCompletion).
+type staticNode struct {
+	path    string
+	dynamic bool
+	words   []string
+	flags   []string
+	vflags  []string // flags taking a separate value (ex: --dir, -d)
+	next    []staticNext
+}
+
+// staticNext is a transition from a staticNode to another path with
+// any of the words (path followed by a Command name, alias, or Alias).
+type staticNext struct {
+	words []string
+	path  string
+}
+
+// staticNodes walks the tree rooted at x (with the name) returning
+// a staticNode for each path in the order visited (depth first).
+func staticNodes(x *Cmd, name string) []staticNode {
+	aliases := AllAliases()
+	var nodes []staticNode
+	var walk func(c *Cmd, path string)
+	walk = func(c *Cmd, path string) {
+		n := staticNode{path: path}
+		n.dynamic = c.Completer != nil || c.ContextCompleter != nil ||
+			c.Describer != nil
+		cmds := c.AllCommands()
+		if !n.dynamic {
+			n.words = comp.Static(c, "")
+			for _, f := range c.flagCandidates("-") {
+				n.flags = append(n.flags, f.Value)
+			}
+		}
+		flags := c.Flags
+		if formats, _ := c.formats(); len(formats) > 0 {
+			flags = append(append([]Flag{}, flags...), FormatFlag)
+		}
+		for _, f := range flags {
+			if f.Value {
+				n.vflags = append(n.vflags, "--"+f.Name)
+				if f.Short != "" {
+					n.vflags = append(n.vflags, "-"+f.Short)
+				}
+			}
+		}
+		named := map[string]bool{}
+		for _, sub := range cmds {
+			if sub.Name == "" {
+				continue
+			}
+			var words []string
+			for _, name := range sub.Names() {
+				named[name] = true
+				if _, has := aliases[name]; has && c == x {
+					continue // shadowed (see rewriteAliases)
+				}
+				words = append(words, path+" "+name)
+			}
+			if len(words) > 0 {
+				n.next = append(n.next, staticNext{words, path + " " + sub.Name})
+			}
+		}
+		if c == x || len(cmds) > 0 {
+			for _, a := range keysWithPrefix(aliases, "") {
+				if named[a] && c != x {
+					continue
+				}
+				if !n.dynamic && !contains(n.words, a) {
+					n.words = append(n.words, a)
+				}
+				words, err := c.rewriteAliases(aliases, []string{a})
+				if err != nil {
+					continue
+				}
+				to := path
+				for cur, i := c, 0; i < len(words); i++ {
+					if strings.HasPrefix(words[i], "-") {
+						continue
+					}
+					next := cur.Resolve(words[i])
+					if next == nil {
+						break
+					}
+					to += " " + next.Name
+					cur = next
+				}
+				if to != path {
+					n.next = append(n.next, staticNext{[]string{path + " " + a}, to})
+				}
+			}
+		}
+		var cands []comp.Candidate
+		for _, w := range n.words {
+			cands = append(cands, comp.Candidate{Value: w})
+		}
+		n.words = comp.Values(c.sortCands(cands))
+		nodes = append(nodes, n)
+		for _, sub := range cmds {
+			if sub.Name != "" {
+				walk(bind(sub, c), path+" "+sub.Name)
+			}
+		}
+	}
+	walk(x, name)
+	return nodes
+}
+
+// compFuncName returns the name of the shell function completing the
+// command name (ex: __foo_bar_complete for foo-bar).
+func compFuncName(name string) string {
+	return "__" + strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, name) + "_complete"
+}
+
+// shQuote returns the string in single quotes for any POSIX shell.
+func shQuote(s string) string {
+	return `'` + strings.ReplaceAll(s, `'`, `'\''`) + `'`
+}
+
+func shQuoteAll(list []string) []string {
+	out := make([]string, len(list))
+	for i, s := range list {
+		out[i] = shQuote(s)
+	}
+	return out
+}
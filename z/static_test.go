@@ -0,0 +1,138 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rwxrob/bonzai/comp"
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+// staticTree adds flags, params, and commands (one with a Completer)
+// to the shared tree (see fooTree).
+func staticTree() *Z.Cmd {
+	x := fooTree()
+	x.Flags = []Z.Flag{{Name: `dir`, Value: true}}
+	db := x.Commands[0]
+	migrate := db.Commands[0]
+	migrate.Params = append(migrate.Params, `env=dev|prod`)
+	migrate.Flags = []Z.Flag{{Name: `dry-run`}}
+	db.Commands = append(db.Commands, &Z.Cmd{Name: `seed`})
+	x.Commands = append(x.Commands,
+		&Z.Cmd{Name: `remote`, Completer: comp.List(`origin`, `upstream`)},
+		&Z.Cmd{Name: `status`, Params: []string{`short`, `long`}},
+	)
+	return x
+}
+
+func TestStaticCompletion(t *testing.T) {
+	defer func(a map[string][]string) { Z.Aliases = a }(Z.Aliases)
+	Z.Aliases = map[string][]string{
+		"st":  {"status", "short"},
+		"mig": {"db", "migrate", "--dry-run"},
+	}
+	for _, shell := range Z.StaticShells {
+		got, err := Z.StaticCompletion(staticTree(), shell)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := Z.TestGolden("testdata/static."+shell+".golden", got); err != nil {
+			t.Error(err)
+		}
+	}
+	if _, err := Z.StaticCompletion(staticTree(), "fish"); err == nil {
+		t.Error("expected error for fish")
+	}
+}
+
+// TestStaticCompletion_bash sources the script and calls the function
+// the same way bash does to complete each line (with the cursor at the
+// end of it).
+func TestStaticCompletion_bash(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not found")
+	}
+	defer func(a map[string][]string) { Z.Aliases = a }(Z.Aliases)
+	Z.Aliases = map[string][]string{"mig": {"db", "migrate", "--dry-run"}}
+	script, err := Z.StaticCompletion(staticTree(), "bash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(t.TempDir(), "foo.bash")
+	if err := os.WriteFile(file, []byte(script), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct{ line, want string }{
+		{`foo `, `db mig remote status`},
+		{`foo s`, `status`},
+		{`foo d `, `mig migrate seed`},
+		{`foo db migrate `, `down env= up`},
+		{`foo --dir x db migrate --dr`, `--dry-run`},
+		{`foo mig `, `down env= up`},
+		{`foo status l`, `long`},
+		{`foo -`, `--dir --verbose --quiet`},
+		{`foo secret `, ``},
+	}
+	for _, test := range tests {
+		cmd := exec.Command(bash, "--norc", "-c", `source "$1"
+read -ra COMP_WORDS <<< "$2"
+[[ $2 == *' ' ]] && COMP_WORDS+=('')
+COMP_CWORD=$((${#COMP_WORDS[@]}-1))
+__foo_complete
+echo "${COMPREPLY[*]}"`, "bash", file, test.line)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("%v: %s", err, out)
+		}
+		if got := strings.TrimSpace(string(out)); got != test.want {
+			t.Errorf("%q: got %q, want %q", test.line, got, test.want)
+		}
+	}
+}
+
+func ExampleStaticCompletion() {
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	orig := os.Args
+	defer func() { os.Args = orig }()
+
+	x := &Z.Cmd{
+		Name:           `foo`,
+		NoBuiltinFlags: true,
+		Commands:       []*Z.Cmd{{Name: `bar`}, Z.CompletionCmd},
+	}
+	os.Args = []string{"foo", "completion", "--static", "bash"}
+	x.Run()
+
+	// Output:
+	// __foo_complete() {
+	//   local cur=${COMP_WORDS[COMP_CWORD]} node='foo' w i cands= flags=
+	//   local vflags=' '
+	//   for ((i=1; i<COMP_CWORD; i++)); do
+	//     w=${COMP_WORDS[i]}
+	//     if [[ $w == -* ]]; then
+	//       [[ $vflags == *" $w "* ]] && ((i++))
+	//       continue
+	//     fi
+	//     case "$node $w" in
+	//       'foo bar') node='foo bar' ;;
+	//       'foo completion') node='foo completion' ;;
+	//       *) break ;;
+	//     esac
+	//   done
+	//   case $node in
+	//     'foo') cands='bar completion' flags='' ;;
+	//     'foo bar') cands='' flags='--verbose --quiet' ;;
+	//     'foo completion') cands='bash fish zsh' flags='--static --verbose --quiet' ;;
+	//   esac
+	//   [[ $cur == -* ]] && cands=$flags
+	//   COMPREPLY=($(compgen -W "$cands" -- "$cur"))
+	// }
+	// complete -F __foo_complete foo
+}
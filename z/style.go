@@ -0,0 +1,56 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import "os"
+
+// Styles contains the ANSI escape sequences used to style usage and
+// help output (see Style). Any may be set to an empty string to leave
+// that part of the output unstyled.
+type Styles struct {
+	Name  string // command names
+	Param string // params
+	Error string // error prefixes (ex: usage)
+	Title string // section titles
+	Reset string // ends every styled string
+}
+
+// Style is the current Styles used for usage and help output. It may
+// be overridden by users (or tree composers) at any time.
+var Style = Styles{
+	Name:  "\033[1m",
+	Param: "\033[4m",
+	Error: "\033[31m",
+	Title: "\033[1m",
+	Reset: "\033[0m",
+}
+
+// ForceColor enables styled output even when it would otherwise be
+// disabled (see Color). This is mostly useful for testing.
+var ForceColor bool
+
+// Color returns true if styled output should be written to the given
+// file (usually os.Stdout or os.Stderr). It is false when the file is
+// not a terminal or when either the NO_COLOR environment variable is
+// set (to anything) or TERM is "dumb" unless ForceColor is true.
+func Color(f *os.File) bool {
+	if ForceColor {
+		return true
+	}
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	i, err := f.Stat()
+	return err == nil && i.Mode()&os.ModeCharDevice != 0
+}
+
+// Styled wraps the string with the style and Style.Reset if styled
+// output is enabled for the file (see Color). Empty strings and styles
+// are returned unchanged.
+func Styled(f *os.File, style, s string) string {
+	if s == "" || style == "" || !Color(f) {
+		return s
+	}
+	return style + s + Style.Reset
+}
@@ -0,0 +1,53 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"fmt"
+	"os"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func ExampleStyled() {
+	fmt.Printf("%q\n", Z.Styled(os.Stdout, Z.Style.Name, "foo"))
+	Z.ForceColor = true
+	defer func() { Z.ForceColor = false }()
+	fmt.Printf("%q\n", Z.Styled(os.Stdout, Z.Style.Name, "foo"))
+	fmt.Printf("%q\n", Z.Styled(os.Stdout, "", "foo"))
+	// Output:
+	// "foo"
+	// "\x1b[1mfoo\x1b[0m"
+	// "foo"
+}
+
+func ExampleColor() {
+	orig := os.Getenv("NO_COLOR")
+	defer os.Setenv("NO_COLOR", orig)
+	os.Setenv("NO_COLOR", "1")
+	fmt.Println(Z.Color(os.Stdout))
+	Z.ForceColor = true
+	defer func() { Z.ForceColor = false }()
+	fmt.Println(Z.Color(os.Stdout))
+	// Output:
+	// false
+	// true
+}
+
+func ExampleCmd_UsageCmdTitles_color() {
+	Z.ForceColor = true
+	defer func() { Z.ForceColor = false }()
+	x := &Z.Cmd{
+		Name: `cmd`,
+		Commands: []*Z.Cmd{
+			&Z.Cmd{Name: "foo", Aliases: []string{"f"}, Summary: "foo it"},
+			&Z.Cmd{Name: "bar", Summary: "bar it"},
+		},
+	}
+	fmt.Printf("%q\n", x.UsageCmdTitles())
+	fmt.Printf("%q\n", x.UsageError())
+	// Output:
+	// "\x1b[1mf|foo\x1b[0m - foo it\n\x1b[1mbar\x1b[0m   - bar it\n"
+	// "\x1b[31musage\x1b[0m: \x1b[1mcmd\x1b[0m ((f|foo)|bar)"
+}
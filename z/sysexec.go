@@ -0,0 +1,16 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package Z
+
+import (
+	"os"
+	"syscall"
+)
+
+func sysexec(path string, args []string) error {
+	return syscall.Exec(path, args, os.Environ())
+}
@@ -0,0 +1,142 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Table contains rows of cells to be written in aligned columns (see
+// Write). The zero value is ready to use.
+type Table struct {
+	Header   []string
+	Rows     [][]string
+	Raw      bool // always write tab-separated values (see Write)
+	MaxWidth int  // of each line when aligned (default: Columns)
+	Cmd      *Cmd // whose flags and Format are used (default: last Run)
+}
+
+// SetHeader sets the Header to the cells (each formatted with
+// fmt.Sprint).
+func (t *Table) SetHeader(cells ...any) { t.Header = sprintAll(cells) }
+
+// AddRow adds a row of the cells (each formatted with fmt.Sprint) to
+// the Rows.
+func (t *Table) AddRow(cells ...any) { t.Rows = append(t.Rows, sprintAll(cells)) }
+
+func sprintAll(cells []any) []string {
+	out := make([]string, len(cells))
+	for i, c := range cells {
+		out[i] = fmt.Sprint(c)
+	}
+	return out
+}
+
+// Write writes the Header (if any) and Rows to the writer with every
+// column padded to the Width of its longest cell and separated by two
+// spaces. If the lines would be longer than MaxWidth the widest columns
+// are narrowed and their cells truncated to fit (see TruncateDisplay).
+// If Raw is set, the Terminal is not interactive, the quiet flag was
+// passed (see BuiltinFlags), or the Format is json (of the Cmd or, if
+// none, of the one last called by Run or Shell) the Rows (without the
+// Header) are written as tab-separated values instead with any tabs or
+// line returns in the cells replaced by spaces so that the output can
+// be processed by other programs.
+func (t *Table) Write(w io.Writer) error {
+	f := lastFlags()
+	if t.Cmd != nil {
+		f = t.Cmd.passed()
+		f.print = t.Cmd.Format()
+	}
+	var out string
+	if t.Raw || !Term.IsInteractive() || f.quiet || f.print == "json" {
+		out = t.tsv()
+	} else {
+		out = t.aligned()
+	}
+	_, err := io.WriteString(w, out)
+	return err
+}
+
+// String fulfills the fmt.Stringer interface with the aligned form of
+// the table (see Write).
+func (t *Table) String() string { return t.aligned() }
+
+func (t *Table) tsv() string {
+	clean := strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ", "\r", " ")
+	var buf strings.Builder
+	for _, row := range t.Rows {
+		for i, c := range row {
+			if i > 0 {
+				buf.WriteString("\t")
+			}
+			buf.WriteString(clean.Replace(c))
+		}
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
+
+func (t *Table) aligned() string {
+	rows := t.Rows
+	if len(t.Header) > 0 {
+		rows = append([][]string{t.Header}, rows...)
+	}
+	var widths []int
+	for _, row := range rows {
+		for i, c := range row {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			if n := Width(c); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+	t.fit(widths)
+	var buf strings.Builder
+	for _, row := range rows {
+		var line string
+		for i, c := range row {
+			c = TruncateDisplay(c, widths[i])
+			if i > 0 {
+				line += "  "
+			}
+			line += c
+			if i < len(row)-1 {
+				line += strings.Repeat(" ", widths[i]-Width(c))
+			}
+		}
+		buf.WriteString(strings.TrimRight(line, " ") + "\n")
+	}
+	return buf.String()
+}
+
+// fit narrows the widest of the column widths (one cell at a time)
+// until a line fits within the MaxWidth (or Columns).
+func (t *Table) fit(widths []int) {
+	max := t.MaxWidth
+	if max <= 0 {
+		max = Columns
+	}
+	total := 2 * (len(widths) - 1)
+	for _, n := range widths {
+		total += n
+	}
+	for total > max {
+		widest := 0
+		for i, n := range widths {
+			if n > widths[widest] {
+				widest = i
+			}
+		}
+		if widths[widest] <= 1 {
+			return
+		}
+		widths[widest]--
+		total--
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"os"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func ExampleTable() {
+	defer func(t Z.Terminal) { Z.Term = t }(Z.Term)
+	Z.Term = stubTerm{}
+
+	t := new(Z.Table)
+	t.SetHeader("NAME", "STATUS", "AGE")
+	t.AddRow("web", "running", 3)
+	t.AddRow("数据库", "stopped", 12)
+	t.AddRow("cache", "", 1)
+	t.Write(os.Stdout)
+
+	// Output:
+	// NAME    STATUS   AGE
+	// web     running  3
+	// 数据库  stopped  12
+	// cache            1
+}
+
+func ExampleTable_piped() {
+	defer func(t Z.Terminal) { Z.Term = t }(Z.Term)
+	Z.Term = pipeTerm{}
+
+	t := new(Z.Table)
+	t.SetHeader("NAME", "NOTE")
+	t.AddRow("web", "has\ttab")
+	t.AddRow("数据库", "two\nlines")
+	t.Write(os.Stdout)
+
+	// Output:
+	// web	has tab
+	// 数据库	two lines
+}
+
+func ExampleTable_truncated() {
+	defer func(t Z.Terminal) { Z.Term = t }(Z.Term)
+	Z.Term = stubTerm{}
+
+	t := &Z.Table{MaxWidth: 24}
+	t.SetHeader("ID", "SUMMARY")
+	t.AddRow(1, "a summary much too long to fit")
+	t.AddRow(2, "中文的摘要也太长了放不下")
+	t.Write(os.Stdout)
+
+	// Output:
+	// ID  SUMMARY
+	// 1   a summary much too …
+	// 2   中文的摘要也太长了…
+}
@@ -0,0 +1,54 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import "github.com/rwxrob/term"
+
+// Terminal is everything Z needs from the terminal: whether output is
+// interactive, its size (for Columns and paging), reading a line of
+// input (with or without echo), and the escapes used by Emph. This is
+// the only place the rwxrob/term package is used (see Term) so that it
+// can be stubbed or replaced entirely.
+type Terminal interface {
+	IsInteractive() bool
+	Size() (rows, cols int) // zero if unknown
+	Read() string
+	ReadHidden() string
+	Attrs() TermAttrs
+}
+
+// TermAttrs contains the escapes (usually VT100) that Emph uses for
+// each kind of emphasis and to reset it.
+type TermAttrs struct {
+	Italic     string
+	Bold       string
+	BoldItalic string
+	Under      string
+	Reset      string
+}
+
+// Term is the Terminal used by everything in Z and is backed by the
+// rwxrob/term package by default. Assign another (in tests, for
+// example) to change the detection, input, or emphasis.
+var Term Terminal = rwxTerm{}
+
+type rwxTerm struct{}
+
+func (rwxTerm) IsInteractive() bool { return term.IsInteractive() }
+func (rwxTerm) Read() string        { return term.Read() }
+func (rwxTerm) ReadHidden() string  { return term.ReadHidden() }
+
+func (rwxTerm) Size() (int, int) {
+	return int(term.WinSize.Row), int(term.WinSize.Col)
+}
+
+func (rwxTerm) Attrs() TermAttrs {
+	return TermAttrs{
+		Italic:     term.Italic,
+		Bold:       term.Bold,
+		BoldItalic: term.BoldItalic,
+		Under:      term.Under,
+		Reset:      term.Reset,
+	}
+}
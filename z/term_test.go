@@ -0,0 +1,34 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"fmt"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+// stubTerm is an interactive terminal of a fixed size that always reads
+// the same line.
+type stubTerm struct{ line string }
+
+func (stubTerm) IsInteractive() bool  { return true }
+func (stubTerm) Size() (int, int)     { return 2, 40 }
+func (t stubTerm) Read() string       { return t.line }
+func (t stubTerm) ReadHidden() string { return t.line }
+func (stubTerm) Attrs() Z.TermAttrs   { return Z.TermAttrs{Bold: `[`, Reset: `]`} }
+
+func ExampleTerminal() {
+	defer func(t Z.Terminal) { Z.Term = t }(Z.Term)
+	Z.Term = stubTerm{`typed by the user`}
+
+	fmt.Println(Z.ArgsOrIn(nil))
+	fmt.Println(Z.ArgsOrIn([]string{`from`, `args`}))
+	fmt.Println(Z.Emph(`some **bold** words`))
+
+	// Output:
+	// typed by the user
+	// from args
+	// some [bold] words
+}
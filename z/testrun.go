@@ -0,0 +1,160 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// TestResult is the captured outcome of a TestRun.
+type TestResult struct {
+	Stdout string // everything written to os.Stdout
+	Stderr string // everything written to os.Stderr (including logs)
+	Err    error  // error passed to ExitError (or panic), if any
+	Code   int    // exit code that would have been used
+}
+
+var testmu sync.Mutex
+
+// TestRun runs the full Run pipeline of the Cmd with the args (as if
+// from the command line, see os.Args) without exiting and returns
+// everything captured as a TestResult. The os.Args, os.Stdout,
+// os.Stderr, log output (and flags, which are set to 0), DoNotExit,
+// ExeName (set to the Name of x), and DefaultExiter (see
+// RecordingExiter) are all swapped for the duration and restored
+// afterward. Since these are global, every TestRun is serialized so it
+// is safe to call from tests that use t.Parallel (but any other
+// concurrent output to them will be captured as well).
+func TestRun(x *Cmd, args ...string) *TestResult {
+	testmu.Lock()
+	defer testmu.Unlock()
+
+	outr, outw, err := os.Pipe()
+	if err != nil {
+		return &TestResult{Err: err, Code: 1}
+	}
+	errr, errw, err := os.Pipe()
+	if err != nil {
+		outr.Close()
+		outw.Close()
+		return &TestResult{Err: err, Code: 1}
+	}
+	stdout, stderr := drain(outr), drain(errr)
+
+	oargs, ostdout, ostderr := os.Args, os.Stdout, os.Stderr
+	owriter, oflags, odonot, oexe := log.Writer(), log.Flags(), DoNotExit, ExeName
+	rec := new(RecordingExiter)
+	restore := SetExiter(rec)
+	os.Args = append([]string{x.Name}, args...)
+	os.Stdout, os.Stderr = outw, errw
+	log.SetOutput(errw)
+	log.SetFlags(0)
+	DoNotExit = false
+	ExeName = x.Name
+	exitErr = nil
+
+	func() {
+		defer func() {
+			os.Args, os.Stdout, os.Stderr = oargs, ostdout, ostderr
+			log.SetOutput(owriter)
+			log.SetFlags(oflags)
+			DoNotExit = odonot
+			ExeName = oexe
+			restore()
+			outw.Close()
+			errw.Close()
+		}()
+		x.Run()
+	}()
+
+	code := rec.Last()
+	if code < 0 {
+		code = 0
+	}
+	return &TestResult{
+		Stdout: <-stdout,
+		Stderr: <-stderr,
+		Err:    exitErr,
+		Code:   code,
+	}
+}
+
+// drain reads everything from r (until closed) in the background.
+func drain(r *os.File) <-chan string {
+	c := make(chan string, 1)
+	go func() {
+		buf, _ := io.ReadAll(r)
+		r.Close()
+		c <- string(buf)
+	}()
+	return c
+}
+
+// TestUpdate causes TestGolden to (over)write the golden files instead
+// of comparing with them. It is set at init() time if the
+// BONZAI_UPDATE_GOLDEN environment variable is not empty:
+//
+//     BONZAI_UPDATE_GOLDEN=1 go test ./...
+var TestUpdate = os.Getenv("BONZAI_UPDATE_GOLDEN") != ""
+
+// TestGolden returns an error describing the first line that differs
+// between got and the content of the golden file (usually under
+// testdata) or any error reading it. If TestUpdate is set, the file
+// (and its directory) is written with got instead. Also see TestUsage.
+func TestGolden(file, got string) error {
+	if TestUpdate {
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return err
+		}
+		return os.WriteFile(file, []byte(got), 0644)
+	}
+	buf, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	want := string(buf)
+	if want == got {
+		return nil
+	}
+	wl, gl := strings.Split(want, "\n"), strings.Split(got, "\n")
+	for i := 0; ; i++ {
+		var w, g string
+		if i < len(wl) {
+			w = wl[i]
+		}
+		if i < len(gl) {
+			g = gl[i]
+		}
+		if w != g || i >= len(wl) || i >= len(gl) {
+			return fmt.Errorf("%v:%v: golden mismatch\nwant: %q\n got: %q",
+				file, i+1, w, g)
+		}
+	}
+}
+
+// TestUsage returns the usage of every command in the tree rooted at
+// x (including hidden ones, see Walk) for comparison with a golden file
+// (see TestGolden): the full path followed by the usage line (see Usage
+// and UsageFunc), the Flags (see UsageFlags), and the Commands (see
+// UsageCmdTitles), with a blank line between commands.
+func TestUsage(x *Cmd) string {
+	var out strings.Builder
+	x.Walk(func(c *Cmd, path []string) error {
+		if out.Len() > 0 {
+			out.WriteString("\n")
+		}
+		fmt.Fprintln(&out, strings.Join(append([]string{x.Name}, path...), " "))
+		fmt.Fprintln(&out, strings.TrimSpace(usageOf(c)))
+		out.WriteString(c.UsageFlags())
+		out.WriteString(c.UsageCmdTitles())
+		return nil
+	})
+	return out.String()
+}
@@ -0,0 +1,96 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+// testRunTree adds a greet command that writes to both stdout and
+// stderr and one that panics to the shared tree (see fooTree).
+func testRunTree() *Z.Cmd {
+	x := fooTree()
+	x.Commands = append(x.Commands,
+		&Z.Cmd{
+			Name:    `greet`,
+			Summary: `say hello`,
+			Params:  []string{`world`, `you`},
+			Flags:   []Z.Flag{{Name: `loud`, Short: `l`, Summary: `shout`}},
+			Call: func(x *Z.Cmd, args ...string) error {
+				if len(args) == 0 {
+					return errors.New("nobody to greet")
+				}
+				fmt.Println("hello", args[0])
+				fmt.Fprintln(os.Stderr, "greeted")
+				return nil
+			},
+		},
+		&Z.Cmd{
+			Name: `boom`,
+			Call: func(_ *Z.Cmd, _ ...string) error { panic("kaboom") },
+		},
+	)
+	return x
+}
+
+func ExampleTestRun() {
+	r := Z.TestRun(testRunTree(), "greet", "world")
+	fmt.Printf("%q %q %v %v\n", r.Stdout, r.Stderr, r.Err, r.Code)
+
+	r = Z.TestRun(testRunTree(), "greet")
+	fmt.Printf("%q %q %v %v\n", r.Stdout, r.Stderr, r.Err, r.Code)
+
+	r = Z.TestRun(testRunTree(), "boom")
+	fmt.Printf("%q %v %v\n", r.Stderr, r.Err, r.Code)
+
+	// Output:
+	// "hello world\n" "greeted\n" <nil> 0
+	// "" "foo: nobody to greet\n" nobody to greet 1
+	// "kaboom\n" kaboom 70
+}
+
+func TestTestRun_parallel(t *testing.T) {
+	for _, name := range []string{"a", "b", "c", "d"} {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			r := Z.TestRun(testRunTree(), "greet", name)
+			if r.Stdout != "hello "+name+"\n" || r.Code != 0 {
+				t.Errorf("got %q (%v)", r.Stdout, r.Code)
+			}
+		})
+	}
+}
+
+func TestTestUsage(t *testing.T) {
+	if err := Z.TestGolden("testdata/usage.golden", Z.TestUsage(testRunTree())); err != nil {
+		t.Error(err)
+	}
+}
+
+func ExampleTestGolden() {
+	dir, _ := os.MkdirTemp("", "bonzai")
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "out.golden")
+
+	defer func(u bool) { Z.TestUpdate = u }(Z.TestUpdate)
+	Z.TestUpdate = true
+	fmt.Println(Z.TestGolden(file, "one\ntwo\n"))
+	Z.TestUpdate = false
+	fmt.Println(Z.TestGolden(file, "one\ntwo\n"))
+	fmt.Println(Z.TestGolden(file, "one\nthree\n") != nil)
+	fmt.Println(Z.TestGolden(file, "one\n") != nil)
+
+	// Output:
+	// <nil>
+	// <nil>
+	// true
+	// true
+}
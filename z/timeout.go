@@ -0,0 +1,117 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// TimeoutParam is the key of the key=value param (see bonzai.KVParam)
+// that overrides the Timeout when passed to any Cmd that declares it in
+// its Params (ex: "timeout=" for timeout=5m).
+var TimeoutParam = "timeout"
+
+// TimeoutEnv is the environment variable that overrides the Timeout of
+// every Cmd called by Run (ex: BONZAI_TIMEOUT=5m). A TimeoutParam takes
+// precedence.
+var TimeoutEnv = "BONZAI_TIMEOUT"
+
+// TimeoutError is returned by RunE (and printed by Run before exiting
+// with ExitTimeout) when the Call of a Cmd does not return within its
+// Timeout. The Call itself is abandoned (left running) since Methods
+// cannot be canceled.
+type TimeoutError struct {
+	Cmd     *Cmd
+	Timeout time.Duration
+	Elapsed time.Duration
+}
+
+// Error fulfills the error interface naming the path of the Cmd (see
+// PathString) and the Elapsed time (ex: db.migrate timed out after
+// 30s).
+func (e *TimeoutError) Error() string {
+	return Msg(`timed-out`, e.Cmd.logPath(), e.Elapsed.Round(time.Millisecond))
+}
+
+// timeout returns the Timeout to enforce for the Cmd: the value of the
+// TimeoutParam from the args (if declared), the TimeoutEnv (if set), or
+// the smallest Timeout (greater than 0) of the Cmd and its Callers, in
+// that order. Zero means none.
+func (x *Cmd) timeout(args []string) (time.Duration, error) {
+	v, has := x.KV(args)[TimeoutParam]
+	if !has {
+		v = os.Getenv(TimeoutEnv)
+	}
+	if v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return 0, fmt.Errorf("%v: %w", Msg(`invalid-timeout`, x.logPath()), err)
+		}
+		return d, nil
+	}
+	var min time.Duration
+	for c := x; c != nil; c = c.Caller {
+		if c.Timeout > 0 && (min == 0 || c.Timeout < min) {
+			min = c.Timeout
+		}
+	}
+	return min, nil
+}
+
+// call calls the Call Method of the Cmd (see callRetries) while
+// holding its lock (see Lock) returning a TimeoutError if it does not
+// return within its timeout (if any), in which case no more retries are
+// attempted. The lock of an abandoned Call is only released once it
+// does return (or the program exits) so that it is never run
+// concurrently. A panic in the Call is repanicked from the calling
+// goroutine.
+func (x *Cmd) call(args []string) error {
+	limit, err := x.timeout(args)
+	if err != nil {
+		return err
+	}
+	release, err := x.lock()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if limit <= 0 {
+		defer release()
+		return x.callRetries(ctx, args)
+	}
+	type result struct {
+		err      error
+		panicked bool
+		r        any
+	}
+	done := make(chan result, 1)
+	go func() {
+		res := result{panicked: true}
+		defer func() {
+			if res.panicked {
+				res.r = recover()
+			}
+			done <- res
+		}()
+		defer release()
+		res.err = x.callRetries(ctx, args)
+		res.panicked = false
+	}()
+	start := time.Now()
+	timer := time.NewTimer(limit)
+	defer timer.Stop()
+	select {
+	case res := <-done:
+		if res.panicked {
+			panic(res.r)
+		}
+		return res.err
+	case <-timer.C:
+		return &TimeoutError{x, limit, time.Since(start)}
+	}
+}
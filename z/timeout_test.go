@@ -0,0 +1,131 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+// sleepTree returns a tree with a leaf (foo slow) that sleeps for the
+// duration of its first argument (if any) and then records it was done.
+func sleepTree(parent, leaf time.Duration, done *int32) *Z.Cmd {
+	return &Z.Cmd{
+		Name:    `foo`,
+		Timeout: parent,
+		Commands: []*Z.Cmd{{
+			Name:    `slow`,
+			Timeout: leaf,
+			Params:  []string{"timeout="},
+			Call: func(x *Z.Cmd, args ...string) error {
+				if len(args) > 0 {
+					d, _ := time.ParseDuration(args[0])
+					time.Sleep(d)
+				}
+				atomic.StoreInt32(done, 1)
+				return nil
+			},
+		}},
+	}
+}
+
+func TestCmd_Run_timeout(t *testing.T) {
+	defer logErrs()()
+	rec := new(Z.RecordingExiter)
+	defer Z.SetExiter(rec)()
+	var buf bytes.Buffer
+	defer log.SetOutput(os.Stderr)
+	defer log.SetFlags(log.Flags())
+	log.SetOutput(&buf)
+	log.SetFlags(0)
+	orig := os.Args
+	defer func() { os.Args = orig }()
+	defer os.Unsetenv(Z.TimeoutEnv)
+
+	tests := []struct {
+		name   string
+		parent time.Duration
+		leaf   time.Duration
+		env    string
+		args   []string
+		code   int
+	}{
+		{"none", 0, 0, "", []string{"50ms"}, 0},
+		{"leaf", 0, 20 * time.Millisecond, "", []string{"1s"}, Z.ExitTimeout},
+		{"smallest", 20 * time.Millisecond, time.Hour, "", []string{"1s"}, Z.ExitTimeout},
+		{"within", time.Second, 0, "", []string{"10ms"}, 0},
+		{"relaxed", 0, 20 * time.Millisecond, "", []string{"50ms", "timeout=1s"}, 0},
+		{"tightened", time.Hour, 0, "", []string{"1s", "timeout=20ms"}, Z.ExitTimeout},
+		{"env", time.Hour, 0, "20ms", []string{"1s"}, Z.ExitTimeout},
+		{"env disabled", 0, 20 * time.Millisecond, "0", []string{"50ms"}, 0},
+		{"param over env", 0, 0, "20ms", []string{"50ms", "timeout=1s"}, 0},
+		{"invalid", 0, 0, "", []string{"timeout=soon"}, 1},
+	}
+	for _, tt := range tests {
+		buf.Reset()
+		os.Setenv(Z.TimeoutEnv, tt.env)
+		var done int32
+		x := sleepTree(tt.parent, tt.leaf, &done)
+		os.Args = append([]string{"foo", "slow"}, tt.args...)
+		start := time.Now()
+		x.Run()
+		if got := rec.Last(); got != tt.code {
+			t.Errorf("%v: want exit %v, got %v (%q)", tt.name, tt.code, got, buf.String())
+			continue
+		}
+		switch tt.code {
+		case Z.ExitTimeout:
+			if atomic.LoadInt32(&done) == 1 || time.Since(start) > 500*time.Millisecond {
+				t.Errorf("%v: call should have been abandoned", tt.name)
+			}
+			if !strings.HasPrefix(buf.String(), "slow timed out after ") {
+				t.Errorf("%v: unexpected error: %q", tt.name, buf.String())
+			}
+		case 0:
+			if atomic.LoadInt32(&done) == 0 {
+				t.Errorf("%v: call should have completed", tt.name)
+			}
+		default:
+			if !strings.Contains(buf.String(), `slow: invalid timeout`) {
+				t.Errorf("%v: unexpected error: %q", tt.name, buf.String())
+			}
+		}
+	}
+}
+
+func TestCmd_RunE_timeout(t *testing.T) {
+	var done int32
+	x := sleepTree(20*time.Millisecond, 0, &done)
+	err := x.RunE("slow", "1s")
+	var terr *Z.TimeoutError
+	if !errors.As(err, &terr) || terr.Cmd.Name != `slow` ||
+		terr.Timeout != 20*time.Millisecond || terr.Elapsed < terr.Timeout {
+		t.Errorf("want TimeoutError: %v", err)
+	}
+	if err := x.RunE("slow", "1ms"); err != nil || atomic.LoadInt32(&done) == 0 {
+		t.Errorf("should have completed: %v", err)
+	}
+}
+
+func TestCmd_RunE_timeout_panic(t *testing.T) {
+	x := &Z.Cmd{
+		Name:    `foo`,
+		Timeout: time.Second,
+		Call:    func(*Z.Cmd, ...string) error { panic("boom") },
+	}
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Errorf("panic should have been repanicked: %v", r)
+		}
+	}()
+	x.RunE()
+}
@@ -0,0 +1,45 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Trace causes Run to write every step taken to resolve the command to
+// call (before calling it) to TraceOut, one per line, each beginning
+// with "trace: ":
+//
+//     trace: alias NAME -> [ARGS]   - expansion from Aliases
+//     trace: seek NAME ARG -> CHILD - ARG resolved to CHILD of NAME
+//     trace: seek NAME ARG stopped  - ARG is not a Command of NAME
+//     trace: default NAME -> CHILD  - NAME has no Call (see DefaultCmd)
+//     trace: leaf PATH              - PathString (or Name of root)
+//     trace: args [ARGS]            - remaining args passed to the Call
+//
+// Setting the BONZAI_TRACE environment variable to anything has the
+// same effect. Also see TraceOnly.
+var Trace bool
+
+// TraceOnly causes Run to Trace and then Exit without calling the
+// command (a dry run of the command resolution itself). Setting the
+// BONZAI_TRACE environment variable to "only" has the same effect.
+var TraceOnly bool
+
+// TraceOut is where Trace output is written (os.Stderr by default).
+var TraceOut io.Writer = os.Stderr
+
+func tracing() bool {
+	return Trace || TraceOnly || os.Getenv("BONZAI_TRACE") != ""
+}
+
+func traceOnly() bool {
+	return TraceOnly || os.Getenv("BONZAI_TRACE") == "only"
+}
+
+func tracef(format string, a ...any) {
+	fmt.Fprintf(TraceOut, "trace: "+format+"\n", a...)
+}
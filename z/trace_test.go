@@ -0,0 +1,59 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"fmt"
+	"os"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func ExampleTrace() {
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	defer func() { Z.Trace, Z.TraceOnly, Z.TraceOut = false, false, os.Stderr }()
+	Z.TraceOut = os.Stdout
+	Z.Trace = true
+	orig := os.Args
+	defer func() { os.Args = orig }()
+	defer delete(Z.Aliases, "mig")
+	Z.Aliases["mig"] = []string{"db", "migrate"}
+
+	call := func(x *Z.Cmd, args ...string) error {
+		fmt.Println("called", x.Name)
+		return nil
+	}
+	x := &Z.Cmd{
+		Name: `foo`,
+		Commands: []*Z.Cmd{
+			&Z.Cmd{
+				Name: `db`,
+				Commands: []*Z.Cmd{
+					&Z.Cmd{Name: `migrate`, Call: call},
+					&Z.Cmd{Name: `status`, Call: call},
+				},
+			},
+		},
+	}
+
+	os.Args = []string{"foo", "mig", "up"}
+	x.Run()
+
+	Z.TraceOnly = true
+	os.Args = []string{"foo", "db"}
+	x.Run()
+
+	// Output:
+	// trace: alias mig -> ["db" "migrate"]
+	// trace: seek foo "db" -> db
+	// trace: seek db "migrate" -> migrate
+	// trace: seek migrate "up" stopped
+	// trace: leaf db.migrate
+	// trace: args ["up"]
+	// called migrate
+	// trace: seek foo "db" -> db
+	// trace: default db -> migrate
+	// trace: leaf db.migrate
+	// trace: args []
+}
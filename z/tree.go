@@ -0,0 +1,124 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TreeOption changes what Tree includes (see TreeDepth and TreePaths).
+type TreeOption func(*treeOpts)
+
+type treeOpts struct {
+	depth int
+	paths bool
+}
+
+// TreeDepth limits the Tree to the number of levels below the root
+// (zero or less for no limit).
+func TreeDepth(n int) TreeOption { return func(o *treeOpts) { o.depth = n } }
+
+// TreePaths adds the PathString of every command (but the root) to the
+// Tree.
+func TreePaths() TreeOption { return func(o *treeOpts) { o.paths = true } }
+
+// Tree returns an indented tree of every command (including hidden
+// ones) rooted at x with one line per command showing:
+//
+//     * the Name and Aliases joined with bars (|)
+//     * an asterisk (*) if the explicit Default of its Caller
+//     * the Params in brackets
+//     * (hidden) if hidden (see IsHidden)
+//     * the number of Other sections (if any)
+//     * the number of Commands not shown (see TreeDepth)
+//     * the PathString (see TreePaths)
+//
+// Commands are indented by two spaces for each level and listed in the
+// order of the Commands. A command already being listed above itself
+// (a cycle) is marked as such rather than listed again.
+func Tree(x *Cmd, opts ...TreeOption) string {
+	o := new(treeOpts)
+	for _, opt := range opts {
+		opt(o)
+	}
+	var out strings.Builder
+	tree(&out, x, nil, nil, map[*Cmd]bool{}, o)
+	return out.String()
+}
+
+func tree(
+	out *strings.Builder, x, caller *Cmd, path []string, seen map[*Cmd]bool, o *treeOpts,
+) {
+	line := strings.Repeat("  ", len(path)) + strings.Join(x.Names(), "|")
+	if caller != nil && caller.Default != "" && caller.Default == x.Name {
+		line += "*"
+	}
+	if len(x.Params) > 0 {
+		line += " [" + strings.Join(x.Params, " ") + "]"
+	}
+	if caller != nil && caller.IsHidden(x.Name) {
+		line += " (hidden)"
+	}
+	if n := len(x.Other); n > 0 {
+		line += fmt.Sprintf(" (%v other)", n)
+	}
+	cmds := x.AllCommands()
+	more := o.depth > 0 && len(path) >= o.depth && len(cmds) > 0
+	if more {
+		line += " (+" + strconv.Itoa(len(cmds)) + " commands)"
+	}
+	if seen[x] {
+		line += " (cycle)"
+	}
+	if o.paths && len(path) > 0 {
+		line += " - " + strings.Join(path, ".")
+	}
+	out.WriteString(line + "\n")
+	if more || seen[x] {
+		return
+	}
+	seen[x] = true
+	defer delete(seen, x)
+	for _, c := range cmds {
+		tree(out, c, x, append(path[:len(path):len(path)], c.Name), seen, o)
+	}
+}
+
+// String fulfills the fmt.Stringer interface with the full Tree of the
+// Cmd.
+func (x *Cmd) String() string { return Tree(x) }
+
+// TreeCmd is an optional hidden builtin leaf command (see Builtins)
+// that prints the Tree of the entire tree from the root to the depth
+// passed as the only argument (if any) with the PathString of each
+// command if the --paths flag is passed.
+var TreeCmd = &Cmd{
+	Name:    `tree`,
+	Summary: `print the tree of every command`,
+	Usage:   `[DEPTH]`,
+	Hide:    true,
+	Flags: []Flag{
+		{Name: `paths`, Summary: `include the full path of each command`},
+	},
+	Call: func(x *Cmd, args ...string) error {
+		if len(args) > 1 {
+			return x.UsageError()
+		}
+		var opts []TreeOption
+		if len(args) > 0 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil {
+				return x.UsageError()
+			}
+			opts = append(opts, TreeDepth(n))
+		}
+		if x.FlagBool("paths") {
+			opts = append(opts, TreePaths())
+		}
+		fmt.Print(Tree(x.Root(), opts...))
+		return nil
+	},
+}
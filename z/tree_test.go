@@ -0,0 +1,73 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"fmt"
+	"os"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+// treeFixture adds a default status command and Other sections to db
+// in the shared tree (see fooTree).
+func treeFixture() *Z.Cmd {
+	x := fooTree()
+	db := x.Commands[0]
+	db.Default = `status`
+	db.Other = []Z.Section{{`Notes`, `some`}, {`More`, `more`}}
+	status := &Z.Cmd{Name: `status`, Call: func(*Z.Cmd, ...string) error { return nil }}
+	db.Commands = append([]*Z.Cmd{status}, db.Commands...)
+	return x
+}
+
+func ExampleTree() {
+	fmt.Print(Z.Tree(treeFixture()))
+	// Output:
+	// foo
+	//   d|db (2 other)
+	//     status*
+	//     migrate [up down]
+	//   secret (hidden)
+}
+
+func ExampleTree_depth() {
+	fmt.Print(Z.Tree(treeFixture(), Z.TreeDepth(1), Z.TreePaths()))
+	// Output:
+	// foo
+	//   d|db (2 other) (+2 commands) - db
+	//   secret (hidden) - secret
+}
+
+func ExampleCmd_String() {
+	x := treeFixture()
+	x.Commands[0].Commands[1].Commands = []*Z.Cmd{x.Commands[0]}
+	fmt.Println(x)
+	// Output:
+	// foo
+	//   d|db (2 other)
+	//     status*
+	//     migrate [up down]
+	//       d|db (2 other) (cycle)
+	//   secret (hidden)
+}
+
+func ExampleTreeCmd() {
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	orig := os.Args
+	defer func() { os.Args = orig }()
+	x := treeFixture()
+	x.Commands = append(x.Commands, Z.TreeCmd)
+
+	os.Args = []string{"foo", "tree", "--paths", "2"}
+	x.Run()
+
+	// Output:
+	// foo
+	//   d|db (2 other) - db
+	//     status* - db.status
+	//     migrate [up down] - db.migrate
+	//   secret (hidden) - secret
+	//   tree (hidden) - tree
+}
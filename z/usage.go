@@ -3,50 +3,59 @@ package Z
 import (
 	"fmt"
 	"strings"
-
-	"github.com/rwxrob/fn/filt"
 )
 
 // UsageGroup uses Bonzai usage notation, a basic form of regular
 // expressions, to describe the arguments allowed where each argument is
 // a literal string (avoid spaces). The arguments are joined with bars
-// (|) and wrapped with parentheses producing a regex group.  The min
-// and max are then applied by adding the following regex decorations
-// after the final parenthesis:
+// (|) and wrapped with parentheses producing a regex group (unless
+// there is only one). The min and max are then applied as follows
+// (shown for the args a and b):
 //
-//                - min=1 max=1 (exactly one)
-//     ?          - min=0 max=0 (none or many)
-//     +          - min=1 max=0 (one or more)
-//     {min,}     - min>0 max=0 (min, no max)
-//     {min,max}  - min>0 max>0 (min and max)
-//     {,max}     - min=0 max>0 (max, no min)
+//     (a|b)          - min=1 max=1 (exactly one)
+//     [a|b]          - min=0 max=1 (optional)
+//     [a|b]...       - min=0 max=0 (none or many)
+//     (a|b)...       - min=1 max=0 (one or more)
+//     (a|b){min,}    - min>1 max=0 (min, no max)
+//     (a|b){min,max} - min>0 max>1 (min and max)
+//     (a|b){n}       - min=max=n   (exactly n)
+//     [(a|b){1,max}] - min=0 max>1 (up to max)
 //
-// An empty args slice returns an empty string. If only one arg, then
-// that arg is simply returned and min and max are ignored. Arguments
-// that are empty strings are ignored. No transformation is done to the
-// string itself (such as removing white space).
+// An empty args slice returns an empty string. Arguments that are empty
+// strings are ignored. No transformation is done to the string itself
+// (such as removing white space).
 func UsageGroup(args []string, min, max int) string {
-	args = filt.NotEmpty(args)
-	switch len(args) {
-	case 0:
+	var nonempty []string
+	for _, a := range args {
+		if a != "" {
+			nonempty = append(nonempty, a)
+		}
+	}
+	args = nonempty
+	if len(args) == 0 {
 		return ""
-	case 1:
-		return args[0]
+	}
+	alt := strings.Join(args, "|")
+	group := alt
+	if len(args) > 1 {
+		group = "(" + alt + ")"
+	}
+	switch {
+	case min == 1 && max == 1:
+		return group
+	case min <= 0 && max == 1:
+		return "[" + alt + "]"
+	case min <= 0 && max <= 0:
+		return "[" + alt + "]..."
+	case min == 1 && max <= 0:
+		return group + "..."
+	case max <= 0:
+		return group + fmt.Sprintf("{%v,}", min)
+	case min == max:
+		return group + fmt.Sprintf("{%v}", min)
+	case min <= 0:
+		return "[" + group + fmt.Sprintf("{1,%v}", max) + "]"
 	default:
-		var dec string
-		switch {
-		case min == 1 && max == 1:
-		case min == 0 && max == 0:
-			dec = "?"
-		case min == 1 && max == 0:
-			dec = "+"
-		case min > 1 && max == 0:
-			dec = fmt.Sprintf("{%v,}", min)
-		case min > 0 && max > 0:
-			dec = fmt.Sprintf("{%v,%v}", min, max)
-		case min == 0 && max > 1:
-			dec = fmt.Sprintf("{,%v}", max)
-		}
-		return "(" + strings.Join(args, "|") + ")" + dec
+		return group + fmt.Sprintf("{%v,%v}", min, max)
 	}
 }
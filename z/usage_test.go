@@ -0,0 +1,39 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"testing"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func TestUsageGroup(t *testing.T) {
+	tests := []struct {
+		args     []string
+		min, max int
+		want     string
+	}{
+		{[]string{"a", "b"}, 0, 0, "[a|b]..."},
+		{[]string{"a", "b"}, 0, 1, "[a|b]"},
+		{[]string{"a", "b"}, 1, 1, "(a|b)"},
+		{[]string{"a", "b"}, 1, 0, "(a|b)..."},
+		{[]string{"a", "b"}, 2, 0, "(a|b){2,}"},
+		{[]string{"a", "b"}, 1, 3, "(a|b){1,3}"},
+		{[]string{"a", "b"}, 2, 2, "(a|b){2}"},
+		{[]string{"a", "b"}, 0, 3, "[(a|b){1,3}]"},
+		{[]string{"a"}, 0, 0, "[a]..."},
+		{[]string{"a"}, 0, 1, "[a]"},
+		{[]string{"a"}, 1, 1, "a"},
+		{[]string{"a"}, 2, 0, "a{2,}"},
+		{[]string{"a"}, 1, 3, "a{1,3}"},
+		{[]string{"", ""}, 0, 0, ""},
+	}
+	for _, tt := range tests {
+		if got := Z.UsageGroup(tt.args, tt.min, tt.max); got != tt.want {
+			t.Errorf("UsageGroup(%q, %v, %v) = %q, want %q",
+				tt.args, tt.min, tt.max, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StrictTree causes Run to Validate the entire command tree before
+// doing anything else and to ExitError with every problem found (one
+// per line). Warnings are only logged. This is meant for development
+// and testing since validating large trees adds to the startup time of
+// every run.
+var StrictTree bool
+
+// ValidationWarning is returned by Validate for problems that do not
+// keep the command from running (which StrictTree only logs).
+type ValidationWarning struct {
+	Path string // dotted path to the command (or Name of the root)
+	Msg  string
+}
+
+// Error fulfills the error interface.
+func (w *ValidationWarning) Error() string {
+	return w.Path + ": warning: " + w.Msg
+}
+
+// Validate walks the entire command tree (depth-first) and returns an
+// error for every problem found, each beginning with the dotted path
+// to the offending command (see PathString) or the Name of the root:
+//
+//     * Commands without a Name
+//     * Command names or aliases used more than once by siblings
+//     * Params without a Call
+//     * MinParm greater than MaxParm (when MaxParm is set)
+//     * Excl or Needs words that are not Params (see bonzai.ParamWord)
+//     * Hidden entries that are not the name of a Command or Param
+//     * Default that is not the name (or alias) of a Command
+//     * Other sections with the same Title
+//     * Commands without Call or Default (warning, see DefaultCmd)
+//     * Commands that contain themselves (cycles)
+//
+// Warnings are returned as a *ValidationWarning. Validate does not
+// change anything in the tree (including Caller).
+func (x *Cmd) Validate() []error {
+	var errs []error
+	x.validate(nil, map[*Cmd]bool{}, &errs)
+	return errs
+}
+
+func (x *Cmd) validate(path []string, seen map[*Cmd]bool, errs *[]error) {
+	at := strings.Join(path, ".")
+	if at == "" {
+		at = x.Name
+	}
+	add := func(format string, a ...any) {
+		*errs = append(*errs, fmt.Errorf(at+": "+format, a...))
+	}
+	warn := func(format string, a ...any) {
+		*errs = append(*errs, &ValidationWarning{at, fmt.Sprintf(format, a...)})
+	}
+
+	if seen[x] {
+		add("cycle: command contains itself")
+		return
+	}
+	seen[x] = true
+	defer delete(seen, x)
+
+	if x.Params != nil && !x.Callable() {
+		add("params without call: %v", strings.Join(x.Params, ", "))
+	}
+
+	if x.MaxParm > 0 && x.MinParm > x.MaxParm {
+		add("min params (%v) greater than max (%v)", x.MinParm, x.MaxParm)
+	}
+
+	for _, w := range x.unknownConstraints() {
+		add("constraint is not a param: %q", w)
+	}
+
+	names := map[string]bool{}
+	for _, c := range x.Commands {
+		if c.Name == "" {
+			add("command without name")
+		}
+		for _, n := range c.Names() {
+			if n == "" {
+				continue
+			}
+			if names[n] {
+				add("duplicate command name or alias: %q", n)
+			}
+			names[n] = true
+		}
+	}
+
+	switch {
+	case x.Default != "" && x.Resolve(x.Default) == nil:
+		add("default is not a command: %q", x.Default)
+	case x.Default == "" && !x.Callable() && len(x.Commands) > 0:
+		warn("no call or default (first command %q used)",
+			x.Commands[0].Name)
+	}
+
+	titles := map[string]bool{}
+	for _, t := range x.OtherTitles() {
+		if titles[t] {
+			add("duplicate other section title: %q", t)
+		}
+		titles[t] = true
+	}
+
+	for _, h := range x.Hidden {
+		if !names[h] && x.Param(h) == "" {
+			add("hidden is not a command or param: %q", h)
+		}
+	}
+
+	for _, c := range x.Commands {
+		c.validate(append(path[:len(path):len(path)], c.Name), seen, errs)
+	}
+}
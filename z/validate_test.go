@@ -0,0 +1,96 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"errors"
+	"fmt"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func ExampleCmd_Validate() {
+	call := func(_ *Z.Cmd, _ ...string) error { return nil }
+
+	leaf := &Z.Cmd{Name: `leaf`, Call: call}
+
+	x := &Z.Cmd{
+		Name:   `foo`,
+		Hidden: []string{"secret", "nothere"},
+		Commands: []*Z.Cmd{
+			&Z.Cmd{Name: `bar`, Aliases: []string{"b"}, Call: call},
+			&Z.Cmd{Name: `baz`, Aliases: []string{"b"}, Call: call},
+			&Z.Cmd{Name: `bar`, Call: call},
+			&Z.Cmd{Name: `secret`, Call: call},
+			&Z.Cmd{
+				Name:     `branch`,
+				Params:   []string{"p1"},
+				Commands: []*Z.Cmd{leaf},
+			},
+			&Z.Cmd{
+				Name:    `minmax`,
+				Params:  []string{"p1", "p2"},
+				MinParm: 2,
+				MaxParm: 1,
+				Call:    call,
+			},
+			&Z.Cmd{
+				Name:   `export`,
+				Params: []string{"json", "yaml", "to="},
+				Excl:   [][]string{{"json", "yaml", "xml"}},
+				Needs:  map[string][]string{"to": {"json"}, "force": {"to"}},
+				Call:   call,
+			},
+		},
+	}
+
+	fmt.Println(leaf.Validate())
+
+	for _, err := range x.Validate() {
+		fmt.Println(err)
+	}
+
+	// Output:
+	// []
+	// foo: duplicate command name or alias: "b"
+	// foo: duplicate command name or alias: "bar"
+	// foo: warning: no call or default (first command "bar" used)
+	// foo: hidden is not a command or param: "nothere"
+	// branch: params without call: p1
+	// branch: warning: no call or default (first command "leaf" used)
+	// minmax: min params (2) greater than max (1)
+	// export: constraint is not a param: "xml"
+	// export: constraint is not a param: "force"
+}
+
+func ExampleCmd_Validate_cycle() {
+	x := &Z.Cmd{Name: `foo`}
+	bar := x.Add("bar")
+	bar.Commands = append(bar.Commands, x)
+	for _, err := range x.Validate() {
+		var w *Z.ValidationWarning
+		fmt.Println(err, errors.As(err, &w))
+	}
+	// Output:
+	// foo: warning: no call or default (first command "bar" used) true
+	// bar: warning: no call or default (first command "foo" used) true
+	// bar.foo: cycle: command contains itself false
+}
+
+func ExampleCmd_Validate_other() {
+	x := &Z.Cmd{
+		Name: `foo`,
+		Call: func(_ *Z.Cmd, _ ...string) error { return nil },
+		Other: []Z.Section{
+			{`Notes`, `first`},
+			{`Bugs`, `none`},
+			{`Notes`, `second`},
+		},
+	}
+	for _, err := range x.Validate() {
+		fmt.Println(err)
+	}
+	// Output:
+	// foo: duplicate other section title: "Notes"
+}
@@ -0,0 +1,180 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rwxrob/bonzai"
+)
+
+// Vars may be optionally assigned any implementation of bonzai.Vars
+// for persisting small bits of state between runs (see Cmd.Get and
+// Cmd.Set). Commands that require Z.Vars should set ReqVars to true.
+// Like Conf, it should be assigned once at init() time, if at all, and
+// commands must never require a specific implementation. By default,
+// it is a VarsFile so that trees work without any setup.
+var Vars bonzai.Vars = new(VarsFile)
+
+// VarsFile is the default implementation of bonzai.Vars persisting the
+// keys and values as properties (key=value, one per line, sorted by
+// key) in a single File. Backslashes and line endings in values are
+// escaped. Every Set and Del rewrites the entire file atomically (by
+// renaming a temporary file) creating any missing directories. If File
+// is empty the file is "vars" in a directory named after the executable
+// within os.UserCacheDir (ex: ~/.cache/foo/vars).
+type VarsFile struct {
+	File string
+	mu   sync.Mutex
+}
+
+// Path returns the File or the default location if File is empty.
+func (v *VarsFile) Path() string {
+	if v.File != "" {
+		return v.File
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	name := strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe")
+	return filepath.Join(dir, name, "vars")
+}
+
+var varsEsc = strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`)
+var varsUnesc = strings.NewReplacer(`\\`, `\`, `\n`, "\n", `\r`, "\r")
+
+// load returns the keys and values currently persisted (if any).
+func (v *VarsFile) load() map[string]string {
+	m := map[string]string{}
+	buf, err := os.ReadFile(v.Path())
+	if err != nil {
+		return m
+	}
+	for _, line := range strings.Split(string(buf), "\n") {
+		key, val, ok := strings.Cut(line, "=")
+		if !ok || key == "" {
+			continue
+		}
+		m[key] = varsUnesc.Replace(val)
+	}
+	return m
+}
+
+// data renders the keys and values as sorted properties.
+func (v *VarsFile) data(m map[string]string) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var out strings.Builder
+	for _, k := range keys {
+		out.WriteString(k + "=" + varsEsc.Replace(m[k]) + "\n")
+	}
+	return out.String()
+}
+
+// save atomically replaces the file with the keys and values.
+func (v *VarsFile) save(m map[string]string) error {
+	path := v.Path()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".vars-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(v.data(m)); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Get fulfills the bonzai.Vars interface.
+func (v *VarsFile) Get(key string) string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.load()[key]
+}
+
+// Set fulfills the bonzai.Vars interface. Keys may not be empty or
+// contain an equal sign or line ending.
+func (v *VarsFile) Set(key, val string) error {
+	if key == "" || strings.ContainsAny(key, "=\r\n") {
+		return fmt.Errorf("invalid vars key: %q", key)
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	m := v.load()
+	m[key] = val
+	return v.save(m)
+}
+
+// Del fulfills the bonzai.Vars interface.
+func (v *VarsFile) Del(key string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	m := v.load()
+	if _, has := m[key]; !has {
+		return nil
+	}
+	delete(m, key)
+	return v.save(m)
+}
+
+// Data fulfills the bonzai.Vars interface.
+func (v *VarsFile) Data() string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.data(v.load())
+}
+
+// pathKey returns the key namespaced under the PathString of the Cmd
+// (ex: db.migrate.last) or the key itself for the root.
+func (x *Cmd) pathKey(key string) string {
+	if path := x.PathString(); path != "" {
+		return path + "." + key
+	}
+	return key
+}
+
+// Get is a shorter version of Z.Vars.Get(x.PathString()+"."+key) for
+// convenience. Logs the error and returns a blank string if Z.Vars is
+// not defined (see ReqVars).
+func (x *Cmd) Get(key string) string {
+	if Vars == nil {
+		logAt(LevelError, "", x.ReqVarsError().Error())
+		return ""
+	}
+	return Vars.Get(x.pathKey(key))
+}
+
+// Set is a shorter version of Z.Vars.Set(x.PathString()+"."+key, val)
+// for convenience. Returns ReqVarsError if Z.Vars is not defined.
+func (x *Cmd) Set(key, val string) error {
+	if Vars == nil {
+		return x.ReqVarsError()
+	}
+	return Vars.Set(x.pathKey(key), val)
+}
+
+// Del is a shorter version of Z.Vars.Del(x.PathString()+"."+key) for
+// convenience. Returns ReqVarsError if Z.Vars is not defined.
+func (x *Cmd) Del(key string) error {
+	if Vars == nil {
+		return x.ReqVarsError()
+	}
+	return Vars.Del(x.pathKey(key))
+}
@@ -0,0 +1,90 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func ExampleVarsFile() {
+	dir, _ := os.MkdirTemp("", "bonzai")
+	defer os.RemoveAll(dir)
+	v := &Z.VarsFile{File: filepath.Join(dir, "foo", "vars")}
+
+	fmt.Printf("%q\n", v.Get("env"))
+	v.Set("env", "prod")
+	v.Set("motd", "line one\nline two")
+	v.Set("count", "1")
+	v.Del("count")
+	v.Del("nope")
+	fmt.Printf("%q\n", v.Get("motd"))
+	fmt.Print(v.Data())
+	fmt.Println(v.Set("a=b", "c"))
+
+	// Output:
+	// ""
+	// "line one\nline two"
+	// env=prod
+	// motd=line one\nline two
+	// invalid vars key: "a=b"
+}
+
+func ExampleCmd_Get() {
+	dir, _ := os.MkdirTemp("", "bonzai")
+	defer os.RemoveAll(dir)
+	origVars := Z.Vars
+	defer func() { Z.Vars = origVars }()
+	v := &Z.VarsFile{File: filepath.Join(dir, "vars")}
+	Z.Vars = v
+
+	x := &Z.Cmd{
+		Name:     `foo`,
+		Commands: []*Z.Cmd{{Name: `db`, Commands: []*Z.Cmd{{Name: `migrate`}}}},
+	}
+	migrate, _ := x.Seek([]string{"db", "migrate"})
+	migrate.Set("last", "v2")
+	x.Set("env", "dev")
+	fmt.Printf("%v %v %q\n", migrate.Get("last"), x.Get("env"), x.Get("last"))
+	fmt.Print(v.Data())
+
+	// Output:
+	// v2 dev ""
+	// db.migrate.last=v2
+	// env=dev
+}
+
+func ExampleCmd_Run_reqvars() {
+	defer logErrs()()
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	defer log.SetOutput(os.Stderr)
+	defer log.SetFlags(log.Flags())
+	log.SetOutput(os.Stdout)
+	log.SetFlags(0)
+	orig := os.Args
+	defer func() { os.Args = orig }()
+	origVars := Z.Vars
+	defer func() { Z.Vars = origVars }()
+	Z.Vars = nil
+
+	x := &Z.Cmd{
+		Name:    `foo`,
+		ReqVars: true,
+		Call: func(_ *Z.Cmd, _ ...string) error {
+			fmt.Println("never called")
+			return nil
+		},
+	}
+	os.Args = []string{"foo"}
+	x.Run()
+	fmt.Println(x.Set("a", "b"))
+
+	// Output:
+	// cmd "foo" requires vars (Z.Vars must be assigned)
+	// cmd "foo" requires vars (Z.Vars must be assigned)
+}
@@ -0,0 +1,55 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import "strings"
+
+// BuiltinFlags are recognized by every Cmd (unless it has set
+// NoBuiltinFlags or declares a flag of its own with the same name or
+// short form) and are extracted from the arguments by Run just like
+// the Flags of the Cmd (see Flag). The verbose flag may be repeated
+// (ex: -v -v, -vv) to increase the Verbose level, which sets the
+// LogLevel to LevelDebug. The quiet flag sets Quiet and the LogLevel to
+// LevelError.
+var BuiltinFlags = []Flag{
+	{Name: "verbose", Short: "v", Summary: "more output (repeatable)"},
+	{Name: "quiet", Short: "q", Summary: "only output errors"},
+}
+
+// BuiltinsInUsage causes UsageFlags to include the BuiltinFlags.
+var BuiltinsInUsage bool
+
+// Verbose returns the number of times the verbose flag was passed (see
+// BuiltinFlags).
+func (x *Cmd) Verbose() int { return x.passed().verbose }
+
+// Quiet returns true if the quiet flag was passed (see BuiltinFlags).
+func (x *Cmd) Quiet() bool { return x.passed().quiet }
+
+// builtinFlag consumes the argument into the flags if it is one of the
+// BuiltinFlags (or -vv...) returning false if it is not.
+func builtinFlag(f *runFlags, a string) bool {
+	switch {
+	case a == "--verbose" || a == "-v":
+		f.verbose++
+	case a == "--quiet" || a == "-q":
+		f.quiet = true
+	case len(a) > 2 && strings.Trim(a[1:], "v") == "" && a[0] == '-':
+		f.verbose += len(a) - 1
+	default:
+		return false
+	}
+	return true
+}
+
+// setVerbosity sets the LogLevel if either of the BuiltinFlags was
+// passed.
+func setVerbosity(f runFlags) {
+	switch {
+	case f.quiet:
+		LogLevel = LevelError
+	case f.verbose > 0:
+		LogLevel = LevelDebug
+	}
+}
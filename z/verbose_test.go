@@ -0,0 +1,65 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"fmt"
+	"os"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func ExampleCmd_Verbose() {
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+	defer func(l Z.Level) { Z.LogLevel = l }(Z.LogLevel)
+	orig := os.Args
+	defer func() { os.Args = orig }()
+
+	call := func(x *Z.Cmd, args ...string) error {
+		fmt.Println(x.Verbose(), x.Quiet(), Z.LogLevel, args)
+		return nil
+	}
+	x := &Z.Cmd{
+		Name:    `foo`,
+		MinArgs: 1,
+		Call:    call,
+		Commands: []*Z.Cmd{
+			&Z.Cmd{Name: `raw`, NoBuiltinFlags: true, Call: call},
+		},
+	}
+
+	os.Args = []string{"foo", "-v", "--verbose", "a"}
+	x.Run()
+
+	os.Args = []string{"foo", "-vvv", "a", "-v"}
+	x.Run()
+
+	os.Args = []string{"foo", "-q", "a"}
+	x.Run()
+
+	os.Args = []string{"foo", "raw", "-v"}
+	x.Run()
+
+	// Output:
+	// 2 false debug [a]
+	// 3 false debug [a -v]
+	// 0 true error [a]
+	// 0 false error [-v]
+}
+
+func ExampleBuiltinsInUsage() {
+	defer func() { Z.BuiltinsInUsage = false }()
+	x := &Z.Cmd{
+		Name:  `foo`,
+		Flags: []Z.Flag{{Name: `all`, Short: `a`, Summary: `everything`}},
+	}
+	fmt.Print(x.UsageFlags())
+	Z.BuiltinsInUsage = true
+	fmt.Print(x.UsageFlags())
+	// Output:
+	// -a, --all - everything
+	// -a, --all     - everything
+	// -v, --verbose - more output (repeatable)
+	// -q, --quiet   - only output errors
+}
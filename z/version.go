@@ -0,0 +1,55 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import "fmt"
+
+// VersionCmd is an optional builtin leaf command (see Builtins) that
+// prints the Legal information of the root Cmd followed by the Site,
+// Source, and Issues (if set). The "short" param prints only the raw
+// Version of the root (useful for scripting) and the "all" param lists
+// the Version of every command along the Path that has one (which is
+// common for composed commands with their own versions).
+var VersionCmd = &Cmd{
+	Name:    `version`,
+	Summary: `print version and legal information`,
+	Params:  []string{"short", "all"},
+	MaxParm: 1,
+	Call: func(x *Cmd, args ...string) error {
+		root := x.Root()
+		if len(args) == 0 {
+			if legal := root.Legal(); legal != "" {
+				fmt.Println(legal)
+			} else if root.Version != "" {
+				fmt.Println(root.Name + " (" + root.Version + ")")
+			}
+			for _, l := range [][]string{
+				{"Site", root.Site},
+				{"Source", root.Source},
+				{"Issues", root.Issues},
+			} {
+				if l[1] != "" {
+					fmt.Printf("%-7v %v\n", l[0]+":", l[1])
+				}
+			}
+			return nil
+		}
+		if len(args) > 1 {
+			return x.UsageError()
+		}
+		switch args[0] {
+		case "short":
+			fmt.Println(root.Version)
+		case "all":
+			for _, c := range x.Callers() {
+				if c.Version != "" {
+					fmt.Println(c.Name + " " + c.Version)
+				}
+			}
+		default:
+			return x.UsageError()
+		}
+		return nil
+	},
+}
@@ -0,0 +1,52 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"os"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func ExampleVersionCmd() {
+	defer Z.SetExiter(new(Z.RecordingExiter))()
+
+	x := &Z.Cmd{
+		Name:      `foo`,
+		Version:   `v1.0.0`,
+		Copyright: `Copyright 2022 Some One`,
+		License:   `Apache-2.0`,
+		Source:    `https://github.com/some/foo`,
+		Commands: []*Z.Cmd{
+			&Z.Cmd{
+				Name:     `bar`,
+				Version:  `v0.2.0`,
+				Commands: []*Z.Cmd{Z.VersionCmd},
+			},
+		},
+	}
+
+	Z.Builtins = []*Z.Cmd{Z.VersionCmd}
+	defer func() { Z.Builtins = nil }()
+
+	orig := os.Args
+	defer func() { os.Args = orig }()
+
+	os.Args = []string{"foo", "version"}
+	x.Run()
+
+	os.Args = []string{"foo", "version", "short"}
+	x.Run()
+
+	os.Args = []string{"foo", "bar", "version", "all"}
+	x.Run()
+
+	// Output:
+	// foo (v1.0.0) Copyright 2022 Some One
+	// License Apache-2.0
+	// Source: https://github.com/some/foo
+	// v1.0.0
+	// foo v1.0.0
+	// bar v0.2.0
+}
@@ -0,0 +1,63 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Walk calls fn for the Cmd and then every one of its Commands
+// (depth-first, in order) passing each along with its path of command
+// names (which is empty for the Cmd itself and does not include its
+// Name, see Path). Walking stops with the first error returned by fn.
+// A Cmd that has been added to more than one parent is visited once
+// for each but one that contains itself (a cycle) stops the walk with
+// an error. Walk is safe to call before Run and does not change
+// anything in the tree (including Caller).
+func (x *Cmd) Walk(fn func(c *Cmd, path []string) error) error {
+	return x.walk(fn, []string{}, map[*Cmd]bool{})
+}
+
+func (x *Cmd) walk(
+	fn func(*Cmd, []string) error, path []string, seen map[*Cmd]bool,
+) error {
+	if seen[x] {
+		return fmt.Errorf("cycle: %q contains itself", strings.Join(path, "."))
+	}
+	seen[x] = true
+	defer delete(seen, x)
+	if err := fn(x, path); err != nil {
+		return err
+	}
+	for _, c := range x.AllCommands() {
+		p := append(path[:len(path):len(path)], c.Name)
+		if err := c.walk(fn, p, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindPath returns the Cmd at the end of the path of command names (or
+// aliases) or nil if not found. The path may be passed as separate
+// strings or as a single dotted string (see PathString). Each name is
+// looked up with Resolve but unlike Seek no Caller is ever changed. An
+// empty path returns the Cmd itself.
+func (x *Cmd) FindPath(path ...string) *Cmd {
+	if len(path) == 1 {
+		path = strings.Split(path[0], ".")
+	}
+	cur := x
+	for _, name := range path {
+		if name == "" {
+			continue
+		}
+		cur = cur.Resolve(name)
+		if cur == nil {
+			return nil
+		}
+	}
+	return cur
+}
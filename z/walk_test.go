@@ -0,0 +1,101 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"fmt"
+	"strings"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func ExampleCmd_Walk() {
+	x := &Z.Cmd{Name: `foo`}
+	db := x.Add("db")
+	db.Add("migrate").Add("up")
+	db.Add("seed")
+	x.Add("status")
+
+	x.Walk(func(c *Z.Cmd, path []string) error {
+		fmt.Printf("%v %q\n", c.Name, strings.Join(path, "."))
+		return nil
+	})
+
+	// Output:
+	// foo ""
+	// db "db"
+	// migrate "db.migrate"
+	// up "db.migrate.up"
+	// seed "db.seed"
+	// status "status"
+}
+
+func ExampleCmd_Walk_stop() {
+	x := &Z.Cmd{Name: `foo`}
+	x.Add("bar")
+	x.Add("stop")
+	x.Add("never")
+
+	err := x.Walk(func(c *Z.Cmd, _ []string) error {
+		fmt.Println(c.Name)
+		if c.Name == "stop" {
+			return fmt.Errorf("stopped")
+		}
+		return nil
+	})
+	fmt.Println(err)
+
+	// Output:
+	// foo
+	// bar
+	// stop
+	// stopped
+}
+
+func ExampleCmd_Walk_cycle() {
+	x := &Z.Cmd{Name: `foo`}
+	shared := &Z.Cmd{Name: `shared`}
+	x.Add("one").Commands = []*Z.Cmd{shared}
+	two := x.Add("two")
+	two.Commands = []*Z.Cmd{shared}
+
+	// added to two parents is fine
+	x.Walk(func(c *Z.Cmd, path []string) error {
+		fmt.Printf("%q\n", strings.Join(path, "."))
+		return nil
+	})
+
+	// but containing itself is not
+	shared.Commands = []*Z.Cmd{two}
+	fmt.Println(x.Walk(func(*Z.Cmd, []string) error { return nil }))
+
+	// Output:
+	// ""
+	// "one"
+	// "one.shared"
+	// "two"
+	// "two.shared"
+	// cycle: "one.shared.two.shared" contains itself
+}
+
+func ExampleCmd_FindPath() {
+	x := &Z.Cmd{Name: `foo`}
+	db := x.Add("db")
+	db.Add("migrate", "m").Add("up")
+
+	fmt.Println(x.FindPath("db.migrate.up").Name)
+	fmt.Println(x.FindPath("db", "m", "up").Name)
+	fmt.Println(x.FindPath().Name)
+	fmt.Println(x.FindPath("db.nope"))
+
+	// Caller is untouched
+	fmt.Println(x.FindPath("db.m").Caller)
+
+	// Output:
+	// up
+	// up
+	// foo
+	// <nil>
+	// <nil>
+}
@@ -0,0 +1,208 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+)
+
+// ServeDocs starts a local HTTP server on addr rendering the embedded
+// documentation of every command in the tree rooted at x as HTML (see
+// DocsHandler). If the port of addr is 0 (ex: ":0", "localhost:0")
+// a free port is chosen. The URL is always printed to standard output
+// once listening. ServeDocs blocks until interrupted (SIGINT) at which
+// point the server is shut down cleanly and nil is returned.
+func ServeDocs(x *Cmd, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	host, _, _ := net.SplitHostPort(addr)
+	if host == "" {
+		host = "localhost"
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	fmt.Printf("http://%v/\n", net.JoinHostPort(host, fmt.Sprint(port)))
+
+	srv := &http.Server{Handler: DocsHandler(x)}
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	defer signal.Stop(sig)
+	go func() {
+		if _, ok := <-sig; ok {
+			srv.Shutdown(context.Background())
+		}
+	}()
+
+	err = srv.Serve(ln)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// DocsHandler returns an http.Handler rendering the Title, usage,
+// Description, Flags, EnvVars, ConfKeys, Other sections, Examples, and
+// Commands of any command in the tree rooted at x as a single HTML page
+// with navigation links mirroring the tree. The URL path is the command
+// path separated by slashes (ex: /db/migrate) with names or aliases
+// resolved as with FindPath. Hidden commands are not found unless
+// DocHidden is true.
+func DocsHandler(x *Cmd) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cur := x
+		path := []string{x.Name}
+		for _, name := range strings.Split(r.URL.Path, "/") {
+			if name == "" {
+				continue
+			}
+			next := cur.Resolve(name)
+			if next == nil || (!DocHidden && cur.IsHidden(next.Name)) {
+				http.NotFound(w, r)
+				return
+			}
+			cur = bind(next, cur)
+			path = append(path, next.Name)
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := docsPage.Execute(w, newWebPage(cur, path)); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+type webLink struct {
+	Name, Href, Summary string
+}
+
+type webSection struct {
+	Title  string
+	Blocks []*Block
+}
+
+type webPage struct {
+	Title    string
+	Usage    string
+	Crumbs   []webLink
+	Sections []webSection
+	Examples []Example
+	Commands []webLink
+}
+
+func newWebPage(x *Cmd, path []string) webPage {
+	p := webPage{
+		Title: x.Title(),
+		Usage: strings.TrimSpace(strings.Join(path, " ") + " " + usageOf(x)),
+	}
+	href := "/"
+	for i, name := range path {
+		if i > 0 {
+			href += name + "/"
+		}
+		p.Crumbs = append(p.Crumbs, webLink{Name: name, Href: href})
+	}
+	if x.Description != "" {
+		p.Sections = append(p.Sections,
+			webSection{"Description", webBlocks(x.Fill(x.Description))})
+	}
+	for _, e := range x.Examples {
+		p.Examples = append(p.Examples, Example{x.Invocation(e.Cmd), e.Note})
+	}
+	if docflags := x.docFlags(); len(docflags) > 0 {
+		var flags []string
+		for _, f := range docflags {
+			flags = append(flags, strings.TrimSpace(f.Usage()+"  "+f.Summary))
+		}
+		p.Sections = append(p.Sections, webSection{"Flags",
+			[]*Block{{Verbatim, []byte(strings.Join(flags, "\n"))}}})
+	}
+	if len(x.EnvVars) > 0 {
+		var vars []string
+		for _, e := range x.EnvVars {
+			vars = append(vars, strings.TrimSpace(e.Name+"  "+docSummary(e.Summary, e.Default, e.Required)))
+		}
+		p.Sections = append(p.Sections, webSection{"Environment",
+			[]*Block{{Verbatim, []byte(strings.Join(vars, "\n"))}}})
+	}
+	if len(x.ConfKeys) > 0 {
+		var keys []string
+		for _, k := range x.ConfKeys {
+			keys = append(keys, strings.TrimSpace(x.pathKey(k.Key)+"  "+
+				docSummary(k.Summary, k.Default, k.Required)))
+		}
+		p.Sections = append(p.Sections, webSection{"Configuration",
+			[]*Block{{Verbatim, []byte(strings.Join(keys, "\n"))}}})
+	}
+	for _, s := range x.Other {
+		p.Sections = append(p.Sections, webSection{s.Title, webBlocks(x.Fill(s.Body))})
+	}
+	for _, c := range docCommands(x) {
+		p.Commands = append(p.Commands,
+			webLink{Name: c.Name, Href: href + c.Name + "/", Summary: c.Summary})
+	}
+	return p
+}
+
+// webBlocks returns the Blocks of the text with the trailing white
+// space of each trimmed, which would otherwise remain in the last
+// paragraph from the closing line of an indented raw string literal.
+func webBlocks(in string) []*Block {
+	blocks := Blocks(in)
+	for _, b := range blocks {
+		b.V = []byte(strings.TrimRight(string(b.V), " \t\r\n"))
+	}
+	return blocks
+}
+
+var docsPage = template.Must(template.New("docs").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+</head>
+<body>
+<nav>{{range $i, $c := .Crumbs}}{{if $i}} / {{end}}<a href="{{$c.Href}}">{{$c.Name}}</a>{{end}}</nav>
+<h1>{{.Title}}</h1>
+<pre><code>{{.Usage}}</code></pre>
+{{range .Sections}}<h2>{{.Title}}</h2>
+{{range .Blocks}}{{if eq .T 1}}<p>{{.}}</p>{{else}}<pre>{{.}}</pre>{{end}}
+{{end}}{{end}}{{if .Examples}}<h2>Examples</h2>
+<dl>
+{{range .Examples}}<dt><code>{{.Cmd}}</code></dt>{{if .Note}}<dd>{{.Note}}</dd>{{end}}
+{{end}}</dl>
+{{end}}{{if .Commands}}<h2>Commands</h2>
+<ul>
+{{range .Commands}}<li><a href="{{.Href}}">{{.Name}}</a>{{if .Summary}} - {{.Summary}}{{end}}</li>
+{{end}}</ul>
+{{end}}</body>
+</html>
+`))
+
+// WebCmd is an optional builtin leaf command (see Builtins) that serves
+// the documentation for the entire tree from the root (see ServeDocs)
+// on the address passed as the only argument (default "localhost:0").
+// It is also included in DocsCmd and can be added to any help branch.
+var WebCmd = &Cmd{
+	Name:    `web`,
+	Summary: `serve documentation as local web pages`,
+	Usage:   `[ADDR]`,
+	Call: func(x *Cmd, args ...string) error {
+		if len(args) > 1 {
+			return x.UsageError()
+		}
+		addr := "localhost:0"
+		if len(args) > 0 {
+			addr = args[0]
+		}
+		return ServeDocs(x.Root(), addr)
+	},
+}
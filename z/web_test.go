@@ -0,0 +1,78 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"fmt"
+	"io"
+	"net/http/httptest"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func ExampleDocsHandler() {
+	x := docTree()
+	x.Description = `
+		The <foo> command.
+
+		    foo db
+
+		Done.
+		`
+	h := Z.DocsHandler(x)
+
+	get := func(path string) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		body, _ := io.ReadAll(w.Result().Body)
+		fmt.Println(w.Code)
+		fmt.Print(string(body))
+	}
+
+	get("/")
+	get("/db/migrate")
+	get("/secret")
+
+	// Output:
+	// 200
+	// <!DOCTYPE html>
+	// <html>
+	// <head>
+	// <meta charset="utf-8">
+	// <title>foo - foo the things</title>
+	// </head>
+	// <body>
+	// <nav><a href="/">foo</a></nav>
+	// <h1>foo - foo the things</h1>
+	// <pre><code>foo (d|db)</code></pre>
+	// <h2>Description</h2>
+	// <p>The &lt;foo&gt; command.</p>
+	// <pre>foo db</pre>
+	// <p>Done.</p>
+	// <h2>Notes</h2>
+	// <p>Notes go here.</p>
+	// <h2>Bugs</h2>
+	// <p>No bugs | ever.</p>
+	// <h2>Commands</h2>
+	// <ul>
+	// <li><a href="/db/">db</a> - database | things</li>
+	// </ul>
+	// </body>
+	// </html>
+	// 200
+	// <!DOCTYPE html>
+	// <html>
+	// <head>
+	// <meta charset="utf-8">
+	// <title>migrate - migrate the schema</title>
+	// </head>
+	// <body>
+	// <nav><a href="/">foo</a> / <a href="/db/">db</a> / <a href="/db/migrate/">migrate</a></nav>
+	// <h1>migrate - migrate the schema</h1>
+	// <pre><code>foo db migrate (up|down)</code></pre>
+	// </body>
+	// </html>
+	// 404
+	// 404 page not found
+}
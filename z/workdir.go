@@ -0,0 +1,77 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FindWorkDir returns the first directory containing the marker (a file
+// or directory such as go.mod or .git) searching upward from the
+// directory. An error wrapping fs.ErrNotExist is returned if it is not
+// found all the way up to the root of the file system.
+func FindWorkDir(dir, marker string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("%v: %w", marker, fs.ErrNotExist)
+		}
+		dir = parent
+	}
+}
+
+// WorkDir returns the directory containing the WorkDirMarker of the Cmd
+// (or of the nearest of its Callers declaring one) found searching
+// upward from the current working directory (see FindWorkDir) or an
+// empty string if there is no marker or it cannot be found. Run (and
+// RunE) check that it can be found for every Cmd from the root to the
+// leaf before calling it, changing to the directory first for any that
+// set WorkDirChdir. Since the working directory belongs to the whole
+// process, trees that run concurrently (see RunE) should not use
+// WorkDirChdir.
+func (x *Cmd) WorkDir() string {
+	for c := x; c != nil; c = c.Caller {
+		if c.WorkDirMarker == "" {
+			continue
+		}
+		dir, err := FindWorkDir(".", c.WorkDirMarker)
+		if err != nil {
+			return ""
+		}
+		return dir
+	}
+	return ""
+}
+
+// checkWorkDir returns an error if the WorkDirMarker of the Cmd itself
+// cannot be found (see WorkDir) and changes to its directory if
+// WorkDirChdir is set.
+func (x *Cmd) checkWorkDir() error {
+	if x.WorkDirMarker == "" {
+		return nil
+	}
+	dir, err := FindWorkDir(".", x.WorkDirMarker)
+	if errors.Is(err, fs.ErrNotExist) {
+		cwd, _ := os.Getwd()
+		return errors.New(Msg(`requires-workdir`, x.logPath(), x.WorkDirMarker, cwd))
+	}
+	if err != nil {
+		return err
+	}
+	if x.WorkDirChdir {
+		return os.Chdir(dir)
+	}
+	return nil
+}
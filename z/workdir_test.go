@@ -0,0 +1,79 @@
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package Z_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	Z "github.com/rwxrob/bonzai/z"
+)
+
+func ExampleFindWorkDir() {
+	tmp, _ := os.MkdirTemp("", "bonzai-workdir")
+	defer os.RemoveAll(tmp)
+	tmp, _ = filepath.EvalSymlinks(tmp)
+	os.MkdirAll(filepath.Join(tmp, "proj", "sub", "deeper"), 0700)
+	os.WriteFile(filepath.Join(tmp, "proj", "go.mod"), nil, 0600)
+
+	dir, err := Z.FindWorkDir(filepath.Join(tmp, "proj", "sub", "deeper"), "go.mod")
+	fmt.Println(strings.TrimPrefix(dir, tmp), err)
+
+	_, err = Z.FindWorkDir(tmp, "go.mod")
+	fmt.Println(err)
+
+	// Output:
+	// /proj <nil>
+	// go.mod: file does not exist
+}
+
+func ExampleCmd_WorkDir() {
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+
+	tmp, _ := os.MkdirTemp("", "bonzai-workdir")
+	defer os.RemoveAll(tmp)
+	tmp, _ = filepath.EvalSymlinks(tmp)
+	sub := filepath.Join(tmp, "proj", "sub")
+	os.MkdirAll(sub, 0700)
+	os.WriteFile(filepath.Join(tmp, "proj", ".bonzai-root"), nil, 0600)
+
+	x := &Z.Cmd{
+		Name: `foo`,
+		Commands: []*Z.Cmd{
+			&Z.Cmd{
+				Name:          `db`,
+				WorkDirMarker: `.bonzai-root`,
+				Commands: []*Z.Cmd{
+					&Z.Cmd{
+						Name: `migrate`,
+						Call: func(x *Z.Cmd, _ ...string) error {
+							wd, _ := os.Getwd()
+							fmt.Println(strings.TrimPrefix(x.WorkDir(), tmp),
+								strings.TrimPrefix(wd, tmp))
+							return nil
+						},
+					},
+				},
+			},
+		},
+	}
+
+	os.Chdir(tmp)
+	err := x.RunE("db", "migrate")
+	fmt.Println(strings.Replace(err.Error(), tmp, "TMP", 1))
+
+	os.Chdir(sub)
+	x.RunE("db", "migrate")
+
+	x.Commands[0].WorkDirChdir = true
+	x.RunE("db", "migrate")
+
+	// Output:
+	// db must be run within a directory containing .bonzai-root (not found in TMP or above)
+	// /proj /proj/sub
+	// /proj /proj
+}